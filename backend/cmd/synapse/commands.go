@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"dailysynapse/backend/internal/backup"
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/store"
+)
+
+const usage = `usage: synapse [command]
+
+With no command, synapse serves the app.
+
+Commands:
+  backup <path>   copy a SQLite database to a new file; safe while serving
+  export <path>   write every feed, article and setting to a JSON snapshot
+  import <path>   load a JSON snapshot into the empty database at DATABASE_URL`
+
+// runCommand runs one maintenance command against DATABASE_URL. Each takes
+// a file path rather than using stdout, where migrations are reported.
+func runCommand(cfg *config.Config, args []string) error {
+	cmd := args[0]
+	if cmd == "help" || cmd == "-h" || cmd == "--help" {
+		fmt.Println(usage)
+		return nil
+	}
+	run, ok := map[string]func(context.Context, *sql.DB, string) error{
+		"backup": backupCommand,
+		"export": exportCommand,
+		"import": importCommand,
+	}[cmd]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", cmd, usage)
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("%s takes one path\n\n%s", cmd, usage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := store.Open(cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+	return run(ctx, db, args[1])
+}
+
+func backupCommand(ctx context.Context, db *sql.DB, path string) error {
+	if store.IsPostgres(db) {
+		return errors.New("backup copies SQLite databases; back up Postgres with pg_dump")
+	}
+	if err := backup.Backup(ctx, db, path); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "backed up to", path)
+	return nil
+}
+
+func exportCommand(ctx context.Context, db *sql.DB, path string) error {
+	snap, err := store.NewQueries(db).Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := errors.Join(enc.Encode(snap), w.Flush(), f.Close()); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "exported %d feeds and %d articles to %s\n",
+		len(snap.Tables["feeds"]), len(snap.Tables["articles"]), path)
+	return nil
+}
+
+func importCommand(ctx context.Context, db *sql.DB, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	snap, err := store.ReadSnapshot(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	if err := store.NewQueries(db).Restore(ctx, snap); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %d feeds and %d articles from %s\n",
+		len(snap.Tables["feeds"]), len(snap.Tables["articles"]), path)
+	return nil
+}
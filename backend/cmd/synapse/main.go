@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -9,18 +11,36 @@ import (
 	"time"
 
 	"dailysynapse/backend/internal/api"
+	"dailysynapse/backend/internal/backup"
 	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/digest"
+	"dailysynapse/backend/internal/events"
 	"dailysynapse/backend/internal/judge"
 	"dailysynapse/backend/internal/logging"
+	"dailysynapse/backend/internal/newsletter"
+	"dailysynapse/backend/internal/notify"
 	"dailysynapse/backend/internal/store"
 	"dailysynapse/backend/internal/syncer"
+	"dailysynapse/backend/internal/webhook"
 	pkgjudge "dailysynapse/backend/pkg/judge"
+	"dailysynapse/backend/pkg/secret"
 )
 
 func main() {
 	cfg := config.Load()
 	logger := logging.New(cfg.LogLevel)
 
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "synapse:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	serve(cfg, logger)
+}
+
+func serve(cfg *config.Config, logger *slog.Logger) {
 	logger.Info("initializing database")
 	db, err := store.Open(cfg.DatabaseURL)
 	if err != nil {
@@ -29,8 +49,25 @@ func main() {
 	}
 	defer db.Close()
 
-	storeQueries := store.NewQueries(db)
-	feedSyncer := syncer.New(storeQueries, cfg, logger)
+	box, err := secret.NewBox(cfg.SecretKey)
+	if err != nil {
+		logger.Warn("SECRET_KEY not set, feed credentials, webhooks and notification channels cannot be stored")
+	}
+
+	storeQueries := store.NewQueries(db).WithSecretBox(box)
+	if !store.IsPostgres(db) {
+		readDB, err := store.OpenReadPool(cfg.DatabaseURL)
+		if err != nil {
+			logger.Error("failed to open read pool", "error", err)
+			os.Exit(1)
+		}
+		defer readDB.Close()
+		storeQueries = storeQueries.WithReadPool(readDB)
+	}
+	bus := events.New()
+	feedSyncer := syncer.New(storeQueries, cfg, bus, logger)
+	dispatcher := webhook.NewDispatcher(storeQueries, bus, cfg, logger)
+	notifier := notify.NewNotifier(storeQueries, bus, cfg, logger)
 
 	var judgeWorker *judge.Worker
 	if cfg.GeminiAPIKey != "" {
@@ -39,7 +76,7 @@ func main() {
 		if err != nil {
 			logger.Warn("failed to initialize gemini client, judge disabled", "error", err)
 		} else {
-			judgeWorker = judge.NewWorker(storeQueries, geminiClient, cfg, logger)
+			judgeWorker = judge.NewWorker(storeQueries, geminiClient, cfg, bus, logger)
 		}
 	} else {
 		logger.Warn("GEMINI_API_KEY not set, judge disabled")
@@ -49,12 +86,44 @@ func main() {
 	defer cancel()
 
 	go feedSyncer.StartBackgroundWorkers(ctx)
+	go dispatcher.Run(ctx)
+	go notifier.Run(ctx)
 
 	if judgeWorker != nil {
 		go judgeWorker.Start(ctx)
 	}
 
-	server := api.NewServer(db, feedSyncer, logger)
+	ingester := newsletter.NewIngester(storeQueries, feedSyncer, bus, logger)
+	if cfg.SMTPAddr != "" {
+		smtpServer := newsletter.NewSMTPServer(cfg.SMTPAddr, cfg.SMTPDomain, cfg.NewsletterRecipients, ingester.Deliver, logger)
+		go func() {
+			logger.Info("starting newsletter smtp listener", "addr", cfg.SMTPAddr)
+			if err := smtpServer.ListenAndServe(ctx); err != nil {
+				logger.Error("newsletter smtp listener failed", "error", err)
+			}
+		}()
+	}
+	if cfg.IMAPAddr != "" {
+		poller := newsletter.NewIMAPPoller(cfg.IMAPAddr, cfg.IMAPUsername, cfg.IMAPPassword, cfg.IMAPMailbox, cfg.IMAPTLS, ingester.Deliver, logger)
+		logger.Info("polling newsletter mailbox", "addr", cfg.IMAPAddr, "mailbox", cfg.IMAPMailbox)
+		go poller.Run(ctx, cfg.IMAPPollInterval)
+	}
+	if cfg.BackupDir != "" && store.IsPostgres(db) {
+		logger.Warn("BACKUP_DIR is ignored on Postgres; back it up with pg_dump")
+	} else if cfg.BackupDir != "" {
+		logger.Info("backing up database", "dir", cfg.BackupDir, "interval", cfg.BackupInterval, "keep", cfg.BackupKeep)
+		go backup.New(db, cfg, logger).Run(ctx)
+	}
+	if cfg.DigestSMTPAddr != "" && len(cfg.DigestRecipients) > 0 {
+		digestJob, err := digest.New(storeQueries, cfg, logger)
+		if err != nil {
+			logger.Warn("invalid digest settings, digest disabled", "error", err)
+		} else {
+			go digestJob.Run(ctx)
+		}
+	}
+
+	server := api.NewServer(db, storeQueries, feedSyncer, bus, logger).WithNotifier(notifier)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -63,6 +132,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Event streams outlive requests; ending them lets Shutdown finish.
+	srv.RegisterOnShutdown(bus.Close)
 
 	go func() {
 		logger.Info("starting server", "port", cfg.Port)
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+)
+
+const maxBulkIDs = 1000
+
+// bulkFilter mirrors the list query parameters, plus older_than_days as a
+// shorthand for until and hide_muted to leave out muted tags as the daily
+// list does.
+type bulkFilter struct {
+	Feed          int64    `json:"feed"`
+	Category      int64    `json:"category"`
+	Tags          []string `json:"tags"`
+	TagMode       string   `json:"tag_mode"`
+	MinScore      int      `json:"min_score"`
+	MaxScore      int      `json:"max_score"`
+	Since         string   `json:"since"`
+	Until         string   `json:"until"`
+	OlderThanDays int      `json:"older_than_days"`
+	Read          string   `json:"read"`
+	Saved         *bool    `json:"saved"`
+	HideMuted     bool     `json:"hide_muted"`
+}
+
+// articleFilter runs the fields through parseArticleQuery so bulk actions
+// and lists validate filters the same way.
+func (b bulkFilter) articleFilter() (core.ArticleFilter, error) {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" && value != "0" {
+			v.Set(key, value)
+		}
+	}
+	set("feed", strconv.FormatInt(b.Feed, 10))
+	set("category", strconv.FormatInt(b.Category, 10))
+	set("tags", strings.Join(b.Tags, ","))
+	set("tag_mode", b.TagMode)
+	set("min_score", strconv.Itoa(b.MinScore))
+	set("max_score", strconv.Itoa(b.MaxScore))
+	set("since", b.Since)
+	set("until", b.Until)
+	set("read", b.Read)
+	if b.Saved != nil {
+		v.Set("saved", strconv.FormatBool(*b.Saved))
+	}
+
+	q, err := parseArticleQuery(v)
+	if err != nil {
+		return core.ArticleFilter{}, err
+	}
+	q.Filter.HideMuted = b.HideMuted
+	if b.OlderThanDays < 0 {
+		return core.ArticleFilter{}, fmt.Errorf("older_than_days must not be negative")
+	}
+	if b.OlderThanDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -b.OlderThanDays)
+		if q.Filter.Until.IsZero() || cutoff.Before(q.Filter.Until) {
+			q.Filter.Until = cutoff
+		}
+	}
+	return q.Filter, nil
+}
+
+// bulkFilterFromQuery is the bulk filter matching the daily page's query,
+// so the page can act on everything it lists and nothing it hides.
+func bulkFilterFromQuery(v url.Values) bulkFilter {
+	b := bulkFilter{
+		TagMode:   v.Get("tag_mode"),
+		Since:     v.Get("since"),
+		Until:     v.Get("until"),
+		Read:      v.Get("read"),
+		HideMuted: true,
+	}
+	b.Feed, _ = strconv.ParseInt(v.Get("feed"), 10, 64)
+	b.Category, _ = strconv.ParseInt(v.Get("category"), 10, 64)
+	b.MinScore, _ = strconv.Atoi(v.Get("min_score"))
+	b.MaxScore, _ = strconv.Atoi(v.Get("max_score"))
+	for _, t := range strings.Split(v.Get("tags"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			b.Tags = append(b.Tags, t)
+		}
+	}
+	if saved, err := strconv.ParseBool(v.Get("saved")); err == nil {
+		b.Saved = &saved
+	}
+	return b
+}
+
+type bulkRequest struct {
+	Action core.BulkAction `json:"action"`
+	IDs    []int64         `json:"ids"`
+	Filter *bulkFilter     `json:"filter"`
+	Tags   []string        `json:"tags"`
+}
+
+func (r bulkRequest) articleFilter() (core.ArticleFilter, error) {
+	switch r.Action {
+	case core.BulkRead, core.BulkUnread, core.BulkSave, core.BulkUnsave, core.BulkDismiss:
+	case core.BulkTag:
+		if len(r.Tags) == 0 {
+			return core.ArticleFilter{}, fmt.Errorf("tags are required for the tag action")
+		}
+	default:
+		return core.ArticleFilter{}, fmt.Errorf("action must be read, unread, save, unsave, dismiss or tag")
+	}
+
+	if (len(r.IDs) > 0) == (r.Filter != nil) {
+		return core.ArticleFilter{}, fmt.Errorf("pass either ids or a filter")
+	}
+	if r.Filter != nil {
+		return r.Filter.articleFilter()
+	}
+	if len(r.IDs) > maxBulkIDs {
+		return core.ArticleFilter{}, fmt.Errorf("at most %d ids per request", maxBulkIDs)
+	}
+	// Named ids are acted on whatever their score.
+	return core.ArticleFilter{IDs: r.IDs, AllScores: true}, nil
+}
+
+// handleBulkArticles applies one action to a list of articles, or to every
+// article a filter matches, in a single transaction.
+func (s *Server) handleBulkArticles(w http.ResponseWriter, r *http.Request) {
+	var req bulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	filter, err := req.articleFilter()
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var tags []string
+	for _, t := range req.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	ids, err := s.store.BulkUpdateArticles(r.Context(), filter, req.Action, tags)
+	if err != nil {
+		s.fail(w, r, "article", err)
+		return
+	}
+	affected := int64(len(ids))
+	if affected > 0 {
+		s.events.Publish(events.ArticlesBulk, events.BulkChange{Action: string(req.Action), IDs: ids, Affected: affected})
+	}
+	JSON(w, http.StatusOK, map[string]any{"action": req.Action, "affected": affected})
+}
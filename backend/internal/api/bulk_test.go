@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/client"
+)
+
+func TestBulkArticles(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx := context.Background()
+	c := client.New(ts.URL, client.WithHTTPClient(&http.Client{Transport: specTransport{t: t, doc: loadOpenAPI(t)}}))
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	create := func(title string, score int, tags []string) int64 {
+		t.Helper()
+		id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: title, URL: "https://example.com/" + title, PublishedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if score > 0 {
+			if err := q.UpdateArticleScore(ctx, id, score, "", "Why", "model", tags); err != nil {
+				t.Fatalf("UpdateArticleScore() error = %v", err)
+			}
+		}
+		return id
+	}
+	unscored := create("unscored", 0, nil)
+	create("go", 80, []string{"Go"})
+	muted := create("frontend", 85, []string{"Frontend"})
+	if err := q.SetPreferences(ctx, core.Preferences{MutedTags: []core.MutedTag{{Tag: "Frontend"}}}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	t.Run("unscored id", func(t *testing.T) {
+		res, err := c.Bulk(ctx, client.BulkRequest{Action: "read", IDs: []int64{unscored}})
+		if err != nil || res.Affected != 1 {
+			t.Fatalf("Bulk() = %+v, %v, want 1 affected", res, err)
+		}
+		if a, err := q.GetArticleByID(ctx, unscored); err != nil || !a.IsRead {
+			t.Errorf("GetArticleByID() = %+v, %v, want read", a, err)
+		}
+	})
+
+	t.Run("daily page filter skips muted", func(t *testing.T) {
+		b := bulkFilterFromQuery(url.Values{"read": {"unread"}})
+		res, err := c.Bulk(ctx, client.BulkRequest{Action: "read", Filter: &client.BulkFilter{Read: b.Read, HideMuted: b.HideMuted}})
+		if err != nil || res.Affected != 1 {
+			t.Fatalf("Bulk() = %+v, %v, want 1 affected", res, err)
+		}
+		if a, err := q.GetArticleByID(ctx, muted); err != nil || a.IsRead {
+			t.Errorf("GetArticleByID() = %+v, %v, want the muted article unread", a, err)
+		}
+	})
+}
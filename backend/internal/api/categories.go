@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/syncer"
+	"dailysynapse/backend/pkg/opml"
+)
+
+// maxOPMLSize caps uploaded subscription lists.
+const maxOPMLSize = 5 << 20
+
+type categoryRequest struct {
+	Name     string `json:"name"`
+	MinScore int    `json:"min_score"`
+}
+
+func (req categoryRequest) validate() error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if req.MinScore < 0 || req.MinScore > 100 {
+		return errors.New("min_score must be between 0 and 100")
+	}
+	return nil
+}
+
+func (s *Server) handleGetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := s.store.GetCategories(r.Context())
+	if err != nil {
+		s.fail(w, r, "category", err)
+		return
+	}
+	JSON(w, http.StatusOK, categories)
+}
+
+func (s *Server) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := req.validate(); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category, err := s.store.CreateCategory(r.Context(), strings.TrimSpace(req.Name), req.MinScore)
+	if err != nil {
+		s.fail(w, r, "category", err)
+		return
+	}
+	JSON(w, http.StatusCreated, category)
+}
+
+func (s *Server) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	var req categoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := req.validate(); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category := core.Category{ID: id, Name: strings.TrimSpace(req.Name), MinScore: req.MinScore}
+	if err := s.store.UpdateCategory(r.Context(), category); err != nil {
+		s.fail(w, r, "category", err)
+		return
+	}
+
+	updated, err := s.store.GetCategory(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "category", err)
+		return
+	}
+	JSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	if err := s.store.DeleteCategory(r.Context(), id); err != nil {
+		s.fail(w, r, "category", err)
+		return
+	}
+	JSON(w, http.StatusOK, map[string]string{"message": "category deleted"})
+}
+
+// handleImportOPML takes an OPML document as the raw request body.
+func (s *Server) handleImportOPML(w http.ResponseWriter, r *http.Request) {
+	result, err := s.importOPML(r.Context(), http.MaxBytesReader(w, r.Body, maxOPMLSize))
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
+	}
+	JSON(w, http.StatusOK, result)
+}
+
+type opmlImportResult struct {
+	Imported   int      `json:"imported"`
+	Skipped    int      `json:"skipped"`
+	Failed     int      `json:"failed"`
+	Categories []string `json:"categories"`
+}
+
+// importOPML subscribes to every feed in the document, creating categories
+// from its folders. Feeds that already exist are skipped but still filed
+// under the folder if they have no category yet.
+func (s *Server) importOPML(ctx context.Context, r io.Reader) (opmlImportResult, error) {
+	result := opmlImportResult{Categories: []string{}}
+
+	feeds, err := opml.Parse(r)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", core.ErrBadRequest, err)
+	}
+
+	categoryIDs := make(map[string]int64)
+	for _, f := range feeds {
+		if err := validateFeedURL(f.XMLURL, ""); err != nil {
+			s.logger.Warn("skipping opml outline", "url", f.XMLURL, "error", err)
+			result.Failed++
+			continue
+		}
+
+		var categoryID int64
+		if f.Category != "" {
+			id, ok := categoryIDs[f.Category]
+			if !ok {
+				category, err := s.store.GetOrCreateCategory(ctx, f.Category)
+				if err != nil {
+					return result, fmt.Errorf("creating category %q: %w", f.Category, err)
+				}
+				id = category.ID
+				categoryIDs[f.Category] = id
+				result.Categories = append(result.Categories, category.Name)
+			}
+			categoryID = id
+		}
+
+		feed, err := s.store.GetFeedByURL(ctx, f.XMLURL)
+		switch {
+		case err == nil:
+			result.Skipped++
+			if feed.CategoryID != 0 || categoryID == 0 {
+				continue
+			}
+		case errors.Is(err, core.ErrNotFound):
+			feed, err = s.store.CreateFeedWithType(ctx, f.XMLURL, f.Title, syncer.DetectSourceType(f.XMLURL))
+			if err != nil {
+				s.logger.Error("failed to import feed", "url", f.XMLURL, "error", err)
+				result.Failed++
+				continue
+			}
+			s.publishFeedAdded(feed)
+			result.Imported++
+			if categoryID == 0 {
+				continue
+			}
+		default:
+			return result, fmt.Errorf("looking up feed: %w", err)
+		}
+
+		if _, err := s.store.UpdateFeed(ctx, feed.ID, core.FeedPatch{CategoryID: &categoryID}); err != nil {
+			s.logger.Error("failed to categorize feed", "url", f.XMLURL, "error", err)
+		}
+	}
+
+	if result.Imported > 0 {
+		go s.syncer.TriggerSync(context.Background())
+	}
+	return result, nil
+}
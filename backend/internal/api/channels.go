@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/notify"
+)
+
+type channelRequest struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	URL       string   `json:"url"`
+	RoomID    string   `json:"room_id"`
+	Token     string   `json:"token"`
+	MinScore  *int     `json:"min_score"`
+	Tags      []string `json:"tags"`
+	FeedIDs   []int64  `json:"feed_ids"`
+	RateLimit int      `json:"rate_limit"`
+	Enabled   *bool    `json:"enabled"`
+}
+
+func (req channelRequest) toChannel() core.NotificationChannel {
+	minScore := notify.DefaultMinScore
+	if req.MinScore != nil {
+		minScore = *req.MinScore
+	}
+	rateLimit := notify.DefaultRateLimit
+	if req.RateLimit != 0 {
+		rateLimit = req.RateLimit
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return core.NotificationChannel{
+		Name:      req.Name,
+		Type:      req.Type,
+		URL:       req.URL,
+		RoomID:    req.RoomID,
+		Token:     req.Token,
+		MinScore:  minScore,
+		Tags:      req.Tags,
+		FeedIDs:   req.FeedIDs,
+		RateLimit: rateLimit,
+		Enabled:   enabled,
+	}
+}
+
+// channelView leaves out the URL and token. A Slack or Discord webhook URL
+// is as good as a password, so only its host is shown.
+type channelView struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Host      string    `json:"host"`
+	RoomID    string    `json:"room_id"`
+	HasToken  bool      `json:"has_token"`
+	MinScore  int       `json:"min_score"`
+	Tags      []string  `json:"tags"`
+	FeedIDs   []int64   `json:"feed_ids"`
+	RateLimit int       `json:"rate_limit"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toChannelView(ch core.NotificationChannel) channelView {
+	view := channelView{
+		ID:        ch.ID,
+		Name:      ch.Name,
+		Type:      ch.Type,
+		RoomID:    ch.RoomID,
+		HasToken:  ch.Token != "",
+		MinScore:  ch.MinScore,
+		Tags:      ch.Tags,
+		FeedIDs:   ch.FeedIDs,
+		RateLimit: ch.RateLimit,
+		Enabled:   ch.Enabled,
+		CreatedAt: ch.CreatedAt,
+	}
+	if u, err := url.Parse(ch.URL); err == nil {
+		view.Host = u.Host
+	}
+	if view.Tags == nil {
+		view.Tags = []string{}
+	}
+	if view.FeedIDs == nil {
+		view.FeedIDs = []int64{}
+	}
+	return view
+}
+
+func (s *Server) handleGetChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := s.store.GetNotificationChannels(r.Context())
+	if err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+
+	views := make([]channelView, 0, len(channels))
+	for _, ch := range channels {
+		views = append(views, toChannelView(ch))
+	}
+	JSON(w, http.StatusOK, views)
+}
+
+func (s *Server) handleGetChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid channel id")
+		return
+	}
+
+	ch, err := s.store.GetNotificationChannel(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+	JSON(w, http.StatusOK, toChannelView(ch))
+}
+
+func (s *Server) handleCreateChannel(w http.ResponseWriter, r *http.Request) {
+	var req channelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ch := req.toChannel()
+	if err := notify.Validate(ch); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ch, err := s.store.CreateNotificationChannel(r.Context(), ch)
+	if err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+	JSON(w, http.StatusCreated, toChannelView(ch))
+}
+
+// handleUpdateChannel replaces a channel. The URL and token are kept
+// unless new ones are given, since they are never sent back to clients.
+func (s *Server) handleUpdateChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid channel id")
+		return
+	}
+
+	var req channelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	existing, err := s.store.GetNotificationChannel(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+
+	ch := req.toChannel()
+	ch.ID = id
+	ch.CreatedAt = existing.CreatedAt
+	if ch.URL == "" {
+		ch.URL = existing.URL
+	}
+	if ch.Token == "" {
+		ch.Token = existing.Token
+	}
+	if err := notify.Validate(ch); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.UpdateNotificationChannel(r.Context(), ch); err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+	JSON(w, http.StatusOK, toChannelView(ch))
+}
+
+func (s *Server) handleDeleteChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid channel id")
+		return
+	}
+
+	if err := s.store.DeleteNotificationChannel(r.Context(), id); err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+	JSON(w, http.StatusOK, map[string]string{"message": "channel deleted"})
+}
+
+// handleTestChannel sends a sample message, whether or not the channel is
+// enabled. What the chat service said is logged under the request ID rather
+// than returned, like any other upstream failure.
+func (s *Server) handleTestChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid channel id")
+		return
+	}
+	if s.notifier == nil {
+		Error(w, http.StatusServiceUnavailable, "notifications are not running")
+		return
+	}
+
+	ch, err := s.store.GetNotificationChannel(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "channel", err)
+		return
+	}
+
+	if err := s.notifier.SendTest(r.Context(), ch); err != nil {
+		if errors.Is(err, core.ErrRateLimited) {
+			s.fail(w, r, "channel", err)
+			return
+		}
+		s.logger.Error("test notification failed",
+			"request_id", RequestID(r.Context()),
+			"channel_id", id,
+			"error", err,
+		)
+		Error(w, http.StatusBadGateway, "the chat service rejected the test notification")
+		return
+	}
+	JSON(w, http.StatusOK, map[string]string{"message": "test notification sent"})
+}
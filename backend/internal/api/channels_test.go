@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/notify"
+	"dailysynapse/backend/pkg/client"
+)
+
+func TestChannels(t *testing.T) {
+	s, q := newTestServer(t)
+	cfg := &config.Config{NotifyTimeout: time.Second, Port: "8080"}
+	s = s.WithNotifier(notify.NewNotifier(q, s.events, cfg, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx := context.Background()
+
+	// The Slack stand-in fails while status is set.
+	var status atomic.Int32
+	messages := make(chan map[string]any, 4)
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code := status.Load(); code != 0 {
+			w.WriteHeader(int(code))
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		messages <- body
+	}))
+	defer slack.Close()
+
+	_, err := client.New(ts.URL).CreateChannel(ctx, client.ChannelRequest{Name: "Room", Type: "irc", URL: slack.URL})
+	if !isCode(err, http.StatusBadRequest, CodeInvalidRequest) {
+		t.Errorf("CreateChannel() with an unknown type error = %v, want 400 invalid_request", err)
+	}
+
+	c := client.New(ts.URL, client.WithHTTPClient(&http.Client{Transport: specTransport{t: t, doc: loadOpenAPI(t)}}))
+
+	ch, err := c.CreateChannel(ctx, client.ChannelRequest{
+		Name: "Team", Type: "slack", URL: slack.URL + "/services/T/B/X", Tags: []string{"Go"}, FeedIDs: []int64{1},
+	})
+	if err != nil {
+		t.Fatalf("CreateChannel() error = %v", err)
+	}
+	if ch.MinScore != notify.DefaultMinScore || ch.RateLimit != notify.DefaultRateLimit || !ch.Enabled {
+		t.Errorf("CreateChannel() = %+v, want the default score, rate limit and enabled", ch)
+	}
+	if ch.Host != strings.TrimPrefix(slack.URL, "http://") {
+		t.Errorf("Host = %q, want the webhook host only", ch.Host)
+	}
+
+	if err := c.TestChannel(ctx, ch.ID); err != nil {
+		t.Fatalf("TestChannel() error = %v", err)
+	}
+	select {
+	case msg := <-messages:
+		if text, _ := msg["text"].(string); !strings.Contains(text, "Test notification") {
+			t.Errorf("test message = %v, want a sample message", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the test message")
+	}
+
+	status.Store(http.StatusForbidden)
+	if err := c.TestChannel(ctx, ch.ID); !isCode(err, http.StatusBadGateway, CodeUpstream) {
+		t.Errorf("TestChannel() against a failing service error = %v, want 502 upstream_error", err)
+	} else if strings.Contains(err.Error(), "403") {
+		t.Errorf("TestChannel() error = %v, want the service's response kept out of it", err)
+	}
+
+	one := 80
+	updated, err := c.UpdateChannel(ctx, ch.ID, client.ChannelRequest{Name: "Team", Type: "slack", MinScore: &one, RateLimit: 1})
+	if err != nil {
+		t.Fatalf("UpdateChannel() error = %v", err)
+	}
+	if updated.MinScore != 80 || updated.RateLimit != 1 || len(updated.Tags) != 0 || updated.Host != ch.Host {
+		t.Errorf("UpdateChannel() = %+v, want min score 80, limit 1 and the URL kept", updated)
+	}
+	stored, err := q.GetNotificationChannel(ctx, ch.ID)
+	if err != nil || stored.URL != slack.URL+"/services/T/B/X" {
+		t.Errorf("stored URL after update = %q, %v; want it unchanged", stored.URL, err)
+	}
+
+	// Lowering the limit to one message an hour leaves room for one more.
+	status.Store(0)
+	if err := c.TestChannel(ctx, ch.ID); err != nil {
+		t.Fatalf("TestChannel() after update error = %v", err)
+	}
+	<-messages
+	if err := c.TestChannel(ctx, ch.ID); !isCode(err, http.StatusTooManyRequests, CodeRateLimited) {
+		t.Errorf("TestChannel() over the rate limit error = %v, want 429 rate_limited", err)
+	}
+
+	channels, err := c.ListChannels(ctx)
+	if err != nil || len(channels) != 1 {
+		t.Fatalf("ListChannels() = %v, %v; want one channel", channels, err)
+	}
+
+	if err := c.DeleteChannel(ctx, ch.ID); err != nil {
+		t.Fatalf("DeleteChannel() error = %v", err)
+	}
+	if err := c.TestChannel(ctx, ch.ID); !isCode(err, http.StatusNotFound, "channel_not_found") {
+		t.Errorf("TestChannel() after delete error = %v, want 404 channel_not_found", err)
+	}
+}
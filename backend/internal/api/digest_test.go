@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestDigestAction(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: "Article", URL: "https://example.com/1", PublishedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	do := func(method, path string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+		resp, err := noRedirect.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	state := func() (read, saved bool) {
+		t.Helper()
+		article, err := q.GetArticleByID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetArticleByID() error = %v", err)
+		}
+		return article.IsRead, article.ReadLater
+	}
+
+	// Following a link only shows a form that posts the action back.
+	for _, action := range []string{"read", "save"} {
+		path := fmt.Sprintf("/digest/%d/%s", id, action)
+		resp := do(http.MethodGet, path)
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `method="POST" action="`+path+`"`) {
+			t.Errorf("GET %s = %d, want 200 with a form posting to it", path, resp.StatusCode)
+		}
+	}
+	if read, saved := state(); read || saved {
+		t.Errorf("after GET article read = %v, saved = %v; want both false", read, saved)
+	}
+
+	// Each form is posted twice: repeating it must not undo the action.
+	for _, action := range []string{"read", "save"} {
+		for range 2 {
+			resp := do(http.MethodPost, fmt.Sprintf("/digest/%d/%s", id, action))
+			if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != fmt.Sprintf("/read/%d", id) {
+				t.Errorf("POST /digest/%d/%s = %d to %q, want 303 to the reader page", id, action, resp.StatusCode, resp.Header.Get("Location"))
+			}
+		}
+	}
+	if read, saved := state(); !read || !saved {
+		t.Errorf("article read = %v, saved = %v; want both true", read, saved)
+	}
+
+	for _, path := range []string{fmt.Sprintf("/digest/%d/delete", id), "/digest/9999/read"} {
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			if resp := do(method, path); resp.StatusCode != http.StatusNotFound {
+				t.Errorf("%s %s = %d, want 404", method, path, resp.StatusCode)
+			}
+		}
+	}
+}
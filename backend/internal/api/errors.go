@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Error codes are the stable, machine-readable half of an error response;
+// messages are for people and may change. Not-found and conflict errors
+// about a resource get a code naming it instead, e.g. feed_not_found or
+// feed_exists.
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+	CodeRateLimited    = "rate_limited"
+	CodeUnavailable    = "unavailable"
+	CodeUpstream       = "upstream_error"
+	CodeInternal       = "internal_error"
+)
+
+// APIError is an error as the client sees it.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// fromCore maps err onto the status and code it should be reported as.
+// resource names what the request was about ("feed", "article", ...).
+// Errors that aren't core errors become a bare internal_error, so SQL and
+// driver messages never reach clients.
+func fromCore(resource string, err error) *APIError {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, core.ErrNotFound):
+		return &APIError{http.StatusNotFound, resource + "_not_found", resource + " not found"}
+	case errors.Is(err, core.ErrConflict):
+		return &APIError{http.StatusConflict, resource + "_exists", resource + " already exists"}
+	case errors.Is(err, core.ErrRateLimited):
+		return &APIError{http.StatusTooManyRequests, CodeRateLimited, "rate limit exceeded, try again later"}
+	case errors.Is(err, core.ErrBadRequest):
+		// Wrapped bad requests carry messages written for the client.
+		return &APIError{http.StatusBadRequest, CodeInvalidRequest, err.Error()}
+	}
+	return &APIError{http.StatusInternalServerError, CodeInternal, "internal server error"}
+}
+
+// fail reports a store or service error. Server errors are logged with
+// the request ID the client was given, which is the only way to tie a
+// report back to the underlying error.
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, resource string, err error) {
+	apiErr := fromCore(resource, err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		s.logger.Error("request failed",
+			slog.String("request_id", RequestID(r.Context())),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Any("error", err),
+		)
+	}
+	writeError(w, apiErr)
+}
+
+// statusCode is the generic code for errors raised directly by handlers.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
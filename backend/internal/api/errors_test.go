@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestFromCore(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		err      error
+		status   int
+		code     string
+	}{
+		{"not found", "article", core.ErrNotFound, http.StatusNotFound, "article_not_found"},
+		{"wrapped conflict", "feed", fmt.Errorf("creating: %w", core.ErrConflict), http.StatusConflict, "feed_exists"},
+		{"rate limited", "feed", core.ErrRateLimited, http.StatusTooManyRequests, CodeRateLimited},
+		{"bad request", "article", fmt.Errorf("%w: bad cursor", core.ErrBadRequest), http.StatusBadRequest, CodeInvalidRequest},
+		{"api error", "feed", &APIError{http.StatusServiceUnavailable, CodeUnavailable, "down"}, http.StatusServiceUnavailable, CodeUnavailable},
+		{"internal", "feed", errors.New("SQL logic error: no such table"), http.StatusInternalServerError, CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fromCore(tt.resource, tt.err)
+			if got.Status != tt.status || got.Code != tt.code {
+				t.Errorf("fromCore() = %d %s, want %d %s", got.Status, got.Code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestFail_HidesInternalErrors(t *testing.T) {
+	var logs bytes.Buffer
+	s := &Server{logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fail(w, r, "feed", errors.New("SQL logic error: no such table: feeds"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/feeds", nil))
+
+	var body Response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError || body.Code != CodeInternal {
+		t.Errorf("fail() = %d %s, want 500 %s", rec.Code, body.Code, CodeInternal)
+	}
+	if strings.Contains(body.Error, "SQL") {
+		t.Errorf("fail() leaked %q to the client", body.Error)
+	}
+	id := rec.Header().Get(requestIDHeader)
+	if id == "" || body.RequestID != id {
+		t.Errorf("fail() request_id = %q, header = %q", body.RequestID, id)
+	}
+	if !strings.Contains(logs.String(), "request_id="+id) || !strings.Contains(logs.String(), "no such table") {
+		t.Errorf("fail() logged %q, want the request ID and the error", logs.String())
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"kept from proxy", "edge-4f2a9c", true},
+		{"rejects spaces", "not valid", false},
+		{"rejects long ids", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(requestIDHeader)
+			if got == "" || got != seen {
+				t.Errorf("X-Request-ID = %q, context = %q, want the same non-empty id", got, seen)
+			}
+			if (got == tt.incoming) != tt.keep {
+				t.Errorf("X-Request-ID = %q for incoming %q, keep = %v", got, tt.incoming, tt.keep)
+			}
+		})
+	}
+}
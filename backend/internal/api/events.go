@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+)
+
+// eventHeartbeat keeps proxies from closing an idle stream.
+const eventHeartbeat = 25 * time.Second
+
+// handleEvents streams bus events as Server-Sent Events. article.scored
+// events are only sent for articles the same query on /api/daily would
+// list, so a page can subscribe with its own filters. Reconnecting clients
+// get what they missed via Last-Event-ID, as far as the bus remembers.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	q, err := parseDailyQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for ordinary responses.
+	rc.SetWriteDeadline(time.Time{})
+
+	sub := s.events.Subscribe(lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case ev, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind or shutting down; the client
+				// reconnects with Last-Event-ID.
+				return
+			}
+			if !s.eventMatches(r.Context(), q.Filter, ev) {
+				continue
+			}
+			if err := writeEvent(w, ev); err != nil {
+				s.logger.Error("failed to encode event", "type", ev.Type, "error", err)
+				continue
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) eventMatches(ctx context.Context, filter core.ArticleFilter, ev events.Event) bool {
+	scored, ok := ev.Data.(events.ArticleScore)
+	if !ok {
+		return true
+	}
+	filter.IDs = []int64{scored.ArticleID}
+	page, err := s.store.ListArticles(ctx, filter, "", 1)
+	if err != nil {
+		s.logger.Error("failed to match event against filter", "request_id", RequestID(ctx), "error", err)
+		return false
+	}
+	return len(page.Articles) > 0
+}
+
+func writeEvent(w io.Writer, ev events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/pkg/client"
+)
+
+func nextEvent(t *testing.T, ch <-chan client.Event) client.Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatal("event stream closed, want an event")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return client.Event{}
+}
+
+func TestEvents_Stream(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []int64
+	for i, url := range []string{"https://a.example.com/feed", "https://b.example.com/feed"} {
+		feed, err := q.CreateFeed(ctx, url, fmt.Sprintf("Feed %d", i))
+		if err != nil {
+			t.Fatalf("CreateFeed() error = %v", err)
+		}
+		id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: "Article", URL: url + "/1", PublishedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, 75, "Summary", "Why", "test", nil); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+	first, err := q.GetArticleByID(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetArticleByID() error = %v", err)
+	}
+
+	c := client.New(ts.URL, client.WithHTTPClient(&http.Client{Transport: specTransport{t: t, doc: loadOpenAPI(t)}}))
+	stream, err := c.Events(ctx, client.ListOptions{Feed: first.FeedID})
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+
+	// Only the article from the subscribed feed gets through.
+	s.events.Publish(events.ArticleScored, events.ArticleScore{ArticleID: ids[1], Score: 75})
+	s.events.Publish(events.ArticleScored, events.ArticleScore{ArticleID: ids[0], Score: 75})
+	if err := c.MarkRead(ctx, ids[0]); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+
+	ev := nextEvent(t, stream)
+	var scored events.ArticleScore
+	json.Unmarshal(ev.Data, &scored)
+	if ev.Type != string(events.ArticleScored) || scored.ArticleID != ids[0] {
+		t.Errorf("first event = %s for %d, want article.scored for %d", ev.Type, scored.ArticleID, ids[0])
+	}
+	ev = nextEvent(t, stream)
+	if ev.Type != string(events.ArticleRead) || ev.ID != 3 {
+		t.Errorf("second event = %s with ID %d, want article.read with ID 3", ev.Type, ev.ID)
+	}
+
+	s.events.Close()
+	select {
+	case _, ok := <-stream:
+		if ok {
+			t.Error("received an event after the bus closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("stream still open after the bus closed")
+	}
+}
+
+func TestEvents_LastEventID(t *testing.T) {
+	s, _ := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+
+	for i := 1; i <= 3; i++ {
+		s.events.Publish(events.FeedSynced, events.FeedSync{FeedID: int64(i)})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/events", nil)
+	req.Header.Set("Last-Event-ID", "2")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if id, ok := strings.CutPrefix(scanner.Text(), "id: "); ok {
+			if id != "3" {
+				t.Errorf("first replayed event ID = %s, want 3", id)
+			}
+			return
+		}
+	}
+	t.Error("no event replayed")
+}
+
+func TestEvents_InvalidFilter(t *testing.T) {
+	s, _ := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+
+	_, err := client.New(ts.URL).Events(context.Background(), client.ListOptions{MinScore: 200})
+	if !isCode(err, http.StatusBadRequest, CodeInvalidRequest) {
+		t.Errorf("Events() with min_score 200 error = %v, want 400 invalid_request", err)
+	}
+}
+
+func TestArticleCard(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: "Card <title>", URL: "https://example.com/1", PublishedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("%s/cards/%d", ts.URL, id))
+	if err != nil {
+		t.Fatalf("GET /cards/{id} error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	html := string(body)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(strings.TrimSpace(html), "<article") {
+		t.Fatalf("GET /cards/%d = %d %q, want an article fragment", id, resp.StatusCode, html)
+	}
+	if !strings.Contains(html, fmt.Sprintf(`data-id="%d"`, id)) || !strings.Contains(html, "Card &lt;title&gt;") {
+		t.Errorf("card does not describe article %d: %s", id, html)
+	}
+
+	resp, err = http.Get(ts.URL + "/cards/9999")
+	if err != nil {
+		t.Fatalf("GET /cards/9999 error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /cards/9999 = %d, want 404", resp.StatusCode)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/export"
+)
+
+const (
+	// maxExportArticles bounds one export, which is built in memory.
+	maxExportArticles = 5000
+	// exportFetchTimeout bounds fetching each article and image for an EPUB.
+	exportFetchTimeout = 30 * time.Second
+	// exportWorkers is how many articles an EPUB export fetches at once.
+	exportWorkers = 4
+)
+
+var exportFormats = map[string]struct{ ContentType, Extension string }{
+	"json":     {"application/json", ".json"},
+	"csv":      {"text/csv; charset=utf-8", ".csv"},
+	"markdown": {"application/zip", ".zip"},
+	"epub":     {"application/epub+zip", ".epub"},
+}
+
+// handleExport downloads articles as a file. It covers saved articles
+// unless saved is given; saved=all exports every article the other filters
+// match.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	format := v.Get("format")
+	kind, ok := exportFormats[format]
+	if !ok {
+		Error(w, http.StatusBadRequest, "format must be markdown, epub, json or csv")
+		return
+	}
+	q, err := parseExportQuery(v)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := s.exportEntries(r.Context(), q.Filter)
+	if err != nil {
+		s.fail(w, r, "article", err)
+		return
+	}
+
+	now := time.Now()
+	scope := "articles"
+	if q.Filter.Saved != nil && *q.Filter.Saved {
+		scope = "saved"
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		err = export.WriteJSON(&buf, entries, now)
+	case "csv":
+		err = export.WriteCSV(&buf, entries)
+	case "markdown":
+		err = export.WriteMarkdown(&buf, entries, now)
+	case "epub":
+		s.loadContent(r.Context(), entries)
+		book := export.Book{
+			Title:   fmt.Sprintf("The Daily Synapse: %s articles, %s", scope, now.Format("2 January 2006")),
+			Entries: entries,
+			Created: now,
+		}
+		err = export.WriteEPUB(r.Context(), &buf, book, &http.Client{Timeout: exportFetchTimeout})
+	}
+	if err != nil {
+		s.fail(w, r, "article", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", kind.ContentType)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="synapse-%s-%s%s"`, scope, now.Format(time.DateOnly), kind.Extension))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// parseExportQuery reads the article list parameters, defaulting to the
+// saved list. cursor and limit are ignored: an export has every match.
+func parseExportQuery(v url.Values) (articleQuery, error) {
+	v = maps.Clone(v)
+	v.Del("cursor")
+	v.Del("limit")
+
+	switch v.Get("saved") {
+	case "":
+		return parseSavedQuery(v)
+	case "all":
+		v.Del("saved")
+	}
+	q, err := parseArticleQuery(v)
+	if err == nil && q.Filter.Sort == "" {
+		q.Filter.Sort = core.SortScore
+	}
+	return q, err
+}
+
+// exportEntries pages through every article matching filter, with tags.
+func (s *Server) exportEntries(ctx context.Context, filter core.ArticleFilter) ([]export.Entry, error) {
+	var entries []export.Entry
+	cursor := ""
+	for {
+		page, err := s.store.ListArticles(ctx, filter, cursor, maxListLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range page.Articles {
+			entries = append(entries, export.Entry{Article: a, Tags: a.Tags})
+		}
+		if len(entries) > maxExportArticles {
+			return nil, fmt.Errorf("%w: exports are limited to %d articles; narrow the filters", core.ErrBadRequest, maxExportArticles)
+		}
+		if page.NextCursor == "" {
+			return entries, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// loadContent fills in each article's full text for an EPUB: the stored
+// body of a newsletter, or the page extracted through the feed's fetch
+// settings, with its images inlined. Articles that fail keep their summary.
+func (s *Server) loadContent(ctx context.Context, entries []export.Entry) {
+	work := make(chan *export.Entry)
+	var wg sync.WaitGroup
+	for range exportWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range work {
+				e.Article.Content = s.articleContent(ctx, e.Article)
+			}
+		}()
+	}
+	for i := range entries {
+		work <- &entries[i]
+	}
+	close(work)
+	wg.Wait()
+}
+
+func (s *Server) articleContent(ctx context.Context, a core.Article) string {
+	stored, err := s.store.GetArticleByID(ctx, a.ID)
+	if err == nil && stored.Content != "" {
+		return stored.Content
+	}
+	if s.syncer == nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, exportFetchTimeout)
+	defer cancel()
+	extractor, err := s.syncer.ExtractorFor(ctx, a.FeedID)
+	if err == nil {
+		var content string
+		if content, err = extractor.Extract(ctx, a.URL); err == nil {
+			return content
+		}
+	}
+	s.logger.Warn("failed to extract article for export", "article_id", a.ID, "error", err)
+	return ""
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/client"
+)
+
+func TestExport(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx := context.Background()
+
+	pixel, _ := base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==")
+	paragraph := "<p>" + strings.Repeat("Profile-guided optimization feeds a CPU profile back into the compiler, which inlines hot calls more aggressively. ", 8) + "</p>"
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pixel.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pixel)
+		case "/pgo":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, `<html><head><title>PGO</title></head><body><article><h1>PGO</h1>`+
+				paragraph+`<p><img src="/pixel.png" alt="chart"></p>`+paragraph+paragraph+`</article></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	feed, err := q.CreateFeed(ctx, site.URL+"/feed", "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	create := func(title, path string, score int, tags []string, saved bool) int64 {
+		t.Helper()
+		id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: title, URL: site.URL + path, PublishedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, score, title+" summary", "Why", "model", tags); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		if saved {
+			if _, err := q.ToggleArticleSaved(ctx, id); err != nil {
+				t.Fatalf("ToggleArticleSaved() error = %v", err)
+			}
+		}
+		return id
+	}
+	create("Profile-guided optimization", "/pgo", 92, []string{"Go"}, true)
+	letter := create("Weekly letter", "/letter", 75, nil, true)
+	if err := q.SaveArticleContent(ctx, letter, "<p>Newsletter body</p>"); err != nil {
+		t.Fatalf("SaveArticleContent() error = %v", err)
+	}
+	create("Unsaved", "/unsaved", 85, nil, false)
+
+	c := client.New(ts.URL, client.WithHTTPClient(&http.Client{Transport: specTransport{t: t, doc: loadOpenAPI(t)}}))
+	download := func(format string, opts client.ListOptions) []byte {
+		t.Helper()
+		body, err := c.Export(ctx, format, opts)
+		if err != nil {
+			t.Fatalf("Export(%s) error = %v", format, err)
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("reading %s export: %v", format, err)
+		}
+		return data
+	}
+
+	var doc struct {
+		Articles []struct {
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+		} `json:"articles"`
+	}
+	if err := json.Unmarshal(download("json", client.ListOptions{}), &doc); err != nil {
+		t.Fatalf("JSON export is not JSON: %v", err)
+	}
+	if len(doc.Articles) != 2 || doc.Articles[0].Title != "Weekly letter" {
+		t.Errorf("JSON export = %+v, want the two saved articles, newest first", doc.Articles)
+	}
+
+	unsaved := false
+	csv := string(download("csv", client.ListOptions{Saved: &unsaved}))
+	if lines := strings.Split(strings.TrimSpace(csv), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "Unsaved") {
+		t.Errorf("CSV export of unsaved articles = %q", csv)
+	}
+
+	notes := unzipFiles(t, download("markdown", client.ListOptions{Tags: []string{"Go"}}))
+	if note := notes["Profile-guided optimization.md"]; !strings.Contains(note, "score: 92\ntags:\n  - \"Go\"\n") {
+		t.Errorf("Markdown export = %v, want the tagged note with front matter", notes)
+	}
+
+	book := unzipFiles(t, download("epub", client.ListOptions{}))
+	if chapter := book["OEBPS/article-1.xhtml"]; !strings.Contains(chapter, "Newsletter body") {
+		t.Errorf("newsletter chapter = %s, want its stored body", chapter)
+	}
+	chapter := book["OEBPS/article-2.xhtml"]
+	if !strings.Contains(chapter, "inlines hot calls") || !strings.Contains(chapter, `<img src="images/1.png" alt="chart"/>`) {
+		t.Errorf("extracted chapter = %s, want the page text with its image", chapter)
+	}
+	if !bytes.Equal([]byte(book["OEBPS/images/1.png"]), pixel) {
+		t.Error("the article's image is not bundled")
+	}
+
+	resp, err := http.Get(ts.URL + "/api/export?format=epub&saved=all")
+	if err != nil {
+		t.Fatalf("GET /api/export error = %v", err)
+	}
+	resp.Body.Close()
+	if cd := resp.Header.Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="synapse-articles-`) || !strings.HasSuffix(cd, `.epub"`) {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	if _, err := client.New(ts.URL).Export(ctx, "pdf", client.ListOptions{}); !isCode(err, http.StatusBadRequest, CodeInvalidRequest) {
+		t.Errorf("Export(pdf) error = %v, want 400 invalid_request", err)
+	}
+}
+
+func unzipFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("export is not a zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
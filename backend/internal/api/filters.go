@@ -0,0 +1,217 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// articleQuery is the parsed form of the query string shared by every
+// article list, in the API and on the HTML pages.
+type articleQuery struct {
+	Filter core.ArticleFilter
+	Cursor string
+	Limit  int
+}
+
+// parseArticleQuery reads the list parameters: feed, category, tags (see
+// parseTags), tag_mode (any or all), min_score, max_score, since, until,
+// read, saved, sort, cursor and limit. Errors name the offending parameter.
+func parseArticleQuery(v url.Values) (articleQuery, error) {
+	q := articleQuery{Limit: defaultListLimit, Cursor: v.Get("cursor")}
+	f := &q.Filter
+	var err error
+
+	if f.FeedID, err = parseID(v, "feed"); err != nil {
+		return q, err
+	}
+	if f.CategoryID, err = parseID(v, "category"); err != nil {
+		return q, err
+	}
+
+	parseTags(v.Get("tags"), f)
+	switch v.Get("tag_mode") {
+	case "", "any":
+	case "all":
+		f.MatchAllTags = true
+	default:
+		return q, fmt.Errorf("tag_mode must be any or all")
+	}
+
+	if f.MinScore, err = parseScore(v, "min_score"); err != nil {
+		return q, err
+	}
+	if f.MaxScore, err = parseScore(v, "max_score"); err != nil {
+		return q, err
+	}
+	if f.MaxScore > 0 && f.MinScore > f.MaxScore {
+		return q, fmt.Errorf("min_score must not exceed max_score")
+	}
+
+	if f.Since, err = parseDate(v, "since"); err != nil {
+		return q, err
+	}
+	if f.Until, err = parseDate(v, "until"); err != nil {
+		return q, err
+	}
+
+	if f.Read, err = parseReadState(v.Get("read")); err != nil {
+		return q, err
+	}
+	if f.Saved, err = parseBool(v, "saved"); err != nil {
+		return q, err
+	}
+
+	switch sort := core.ArticleSort(v.Get("sort")); sort {
+	case "":
+	case core.SortPersonalized, core.SortScore, core.SortDate:
+		f.Sort = sort
+	default:
+		return q, fmt.Errorf("sort must be personalized, score or date")
+	}
+
+	if s := v.Get("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l <= 0 || l > maxListLimit {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		q.Limit = l
+	}
+	return q, nil
+}
+
+// parseTags reads a tags parameter such as Databases+Postgres,Go,-Tutorial:
+// comma-separated terms where a lone tag is one of several that may match,
+// tags joined with + must all match and a tag after - must not. A + that
+// ends a tag, as in C++, is part of its name.
+func parseTags(s string, f *core.ArticleFilter) {
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if name, ok := strings.CutPrefix(term, "-"); ok {
+			if name = strings.TrimSpace(name); name != "" {
+				f.ExcludeTags = append(f.ExcludeTags, name)
+			}
+			continue
+		}
+		required := strings.HasPrefix(term, "+")
+		names := splitTagTerm(strings.TrimPrefix(term, "+"))
+		if len(names) == 1 && !required {
+			f.Tags = append(f.Tags, names[0])
+		} else {
+			f.RequireTags = append(f.RequireTags, names...)
+		}
+	}
+}
+
+// splitTagTerm splits a term on +. An empty piece means a + ended the tag
+// before it, so it is glued back on.
+func splitTagTerm(term string) []string {
+	var names []string
+	for _, part := range strings.Split(term, "+") {
+		switch part = strings.TrimSpace(part); {
+		case part != "":
+			names = append(names, part)
+		case len(names) > 0:
+			names[len(names)-1] += "+"
+		}
+	}
+	return names
+}
+
+func parseID(v url.Values, name string) (int64, error) {
+	s := v.Get(name)
+	if s == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s id", name)
+	}
+	return id, nil
+}
+
+func parseScore(v url.Values, name string) (int, error) {
+	s := v.Get(name)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 100 {
+		return 0, fmt.Errorf("%s must be between 0 and 100", name)
+	}
+	return n, nil
+}
+
+// parseDate accepts RFC 3339 timestamps and plain YYYY-MM-DD dates.
+func parseDate(v url.Values, name string) (time.Time, error) {
+	s := v.Get(name)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 time", name)
+}
+
+// parseReadState accepts read, unread or all as well as true and false.
+func parseReadState(s string) (*bool, error) {
+	read, unread := true, false
+	switch s {
+	case "", "all":
+		return nil, nil
+	case "read", "true":
+		return &read, nil
+	case "unread", "false":
+		return &unread, nil
+	}
+	return nil, fmt.Errorf("read must be read, unread or all")
+}
+
+func parseBool(v url.Values, name string) (*bool, error) {
+	s := v.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", name)
+	}
+	return &b, nil
+}
+
+// parseDailyQuery is parseArticleQuery for the daily list, which hides
+// articles with a muted tag.
+func parseDailyQuery(v url.Values) (articleQuery, error) {
+	q, err := parseArticleQuery(v)
+	q.Filter.HideMuted = true
+	return q, err
+}
+
+// parseSavedQuery is parseArticleQuery for the saved list, which shows every
+// saved article regardless of score, newest first.
+func parseSavedQuery(v url.Values) (articleQuery, error) {
+	q, err := parseArticleQuery(v)
+	if err != nil {
+		return q, err
+	}
+	saved := true
+	q.Filter.Saved = &saved
+	q.Filter.AllScores = true
+	if q.Filter.Sort == "" {
+		q.Filter.Sort = core.SortDate
+	}
+	return q, nil
+}
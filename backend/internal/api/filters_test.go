@@ -0,0 +1,33 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		in                     string
+		any, require, excluded []string
+	}{
+		{"", nil, nil, nil},
+		{"Go, Rust", []string{"Go", "Rust"}, nil, nil},
+		{"Databases+Postgres,-Tutorial", nil, []string{"Databases", "Postgres"}, []string{"Tutorial"}},
+		{"Go,+Performance", []string{"Go"}, []string{"Performance"}, nil},
+		{"C++,Go+C++", []string{"C++"}, []string{"Go", "C++"}, nil},
+		{"+C+++Go", nil, []string{"C++", "Go"}, nil},
+		{"Machine Learning,-,", []string{"Machine Learning"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var f core.ArticleFilter
+			parseTags(tt.in, &f)
+			got := fmt.Sprint(f.Tags, f.RequireTags, f.ExcludeTags)
+			if want := fmt.Sprint(tt.any, tt.require, tt.excluded); got != want {
+				t.Errorf("parseTags(%q) = %s, want %s", tt.in, got, want)
+			}
+		})
+	}
+}
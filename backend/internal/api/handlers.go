@@ -4,16 +4,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 
 	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/syncer"
 )
 
 func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
 	if err := s.syncer.TriggerSync(r.Context()); err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to trigger sync: %v", err))
+		s.fail(w, r, "sync", err)
 		return
 	}
 	JSON(w, http.StatusOK, map[string]string{"message": "sync triggered"})
@@ -22,7 +27,7 @@ func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleGetFeeds(w http.ResponseWriter, r *http.Request) {
 	feeds, err := s.store.GetAllFeeds(r.Context())
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch feeds: %v", err))
+		s.fail(w, r, "feed", err)
 		return
 	}
 	JSON(w, http.StatusOK, feeds)
@@ -32,6 +37,7 @@ func (s *Server) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		URL  string `json:"url"`
 		Name string `json:"name"`
+		Type string `json:"type"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -44,14 +50,152 @@ func (s *Server) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	feed, err := s.store.CreateFeed(r.Context(), req.URL, req.Name)
+	if req.Type == "" {
+		req.Type = syncer.DetectSourceType(req.URL)
+	}
+	if !syncer.ValidSourceType(req.Type) {
+		Error(w, http.StatusBadRequest, "type must be one of "+strings.Join(syncer.SourceTypes, ", "))
+		return
+	}
+
+	feed, err := s.store.CreateFeedWithType(r.Context(), req.URL, req.Name, req.Type)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to create feed: %v", err))
+		s.fail(w, r, "feed", err)
 		return
 	}
+	s.publishFeedAdded(feed)
 	JSON(w, http.StatusCreated, feed)
 }
 
+func (s *Server) publishFeedAdded(feed core.Feed) {
+	s.events.Publish(events.FeedAdded, events.FeedInfo{FeedID: feed.ID, Name: feed.Name, URL: feed.URL, Type: feed.Type})
+}
+
+func (s *Server) handleGetFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	offset := 0
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	feed, err := s.store.GetFeed(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
+	}
+
+	stats, err := s.store.GetFeedStats(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
+	}
+
+	articles, total, err := s.store.GetArticlesByFeed(r.Context(), id, limit, offset)
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]any{
+		"feed":     feed,
+		"stats":    stats,
+		"articles": articles,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+func (s *Server) handleUpdateFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	var req struct {
+		Name       *string `json:"name"`
+		URL        *string `json:"url"`
+		Paused     *bool   `json:"paused"`
+		CategoryID *int64  `json:"category_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == nil && req.URL == nil && req.Paused == nil && req.CategoryID == nil {
+		Error(w, http.StatusBadRequest, "one of name, url, paused or category_id is required")
+		return
+	}
+
+	feed, err := s.store.GetFeed(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
+	}
+
+	patch := core.FeedPatch{Paused: req.Paused, CategoryID: req.CategoryID}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			Error(w, http.StatusBadRequest, "name cannot be empty")
+			return
+		}
+		patch.Name = &name
+	}
+	if req.URL != nil {
+		feedURL := strings.TrimSpace(*req.URL)
+		if err := validateFeedURL(feedURL, feed.Type); err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		patch.URL = &feedURL
+	}
+
+	updated, err := s.store.UpdateFeed(r.Context(), id, patch)
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
+	}
+
+	// A new URL or a resumed feed is fetched now rather than on the next
+	// tick. The workers run until the server shuts down.
+	resync := updated.URL != feed.URL || feed.Status != "active"
+	if resync && updated.Status == "active" && updated.Type != syncer.TypeNewsletter && !s.syncer.Queue(updated) {
+		s.logger.Warn("sync queue full, feed waits for the next sync", slog.Int64("feed_id", updated.ID))
+	}
+	JSON(w, http.StatusOK, updated)
+}
+
+// validateFeedURL checks a replacement URL against the kind of feed it
+// belongs to: newsletters are keyed by sender address, everything else is
+// fetched over HTTP.
+func validateFeedURL(rawURL, feedType string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || rawURL == "" {
+		return errors.New("invalid url")
+	}
+	if feedType == syncer.TypeNewsletter {
+		if u.Scheme != "mailto" || u.Opaque == "" {
+			return errors.New("newsletter url must be a mailto: address")
+		}
+		return nil
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https url")
+	}
+	return nil
+}
+
 func (s *Server) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	if idStr == "" {
@@ -67,40 +211,35 @@ func (s *Server) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.store.MarkFeedForDeletion(r.Context(), id); err != nil {
-		if errors.Is(err, core.ErrNotFound) {
-			Error(w, http.StatusNotFound, "feed not found")
-			return
-		}
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to delete feed: %v", err))
+		s.fail(w, r, "feed", err)
 		return
 	}
 
 	JSON(w, http.StatusAccepted, map[string]string{"message": "feed marked for deletion"})
 }
 
-func (s *Server) handleGetDaily(w http.ResponseWriter, r *http.Request) {
-	limitStr := r.URL.Query().Get("limit")
-	limit := 20
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+func (s *Server) handleRestoreFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid feed id")
+		return
 	}
 
-	offsetStr := r.URL.Query().Get("offset")
-	offset := 0
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	feed, err := s.store.RestoreFeed(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "feed", err)
+		return
 	}
+	JSON(w, http.StatusOK, feed)
+}
 
-	articles, total, err := s.store.GetTopArticles(r.Context(), limit, offset)
+func (s *Server) handleGetDaily(w http.ResponseWriter, r *http.Request) {
+	q, err := parseDailyQuery(r.URL.Query())
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch articles: %v", err))
+		Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	JSON(w, http.StatusOK, map[string]any{"articles": articles, "total": total, "limit": limit, "offset": offset})
+	s.listArticles(w, r, q)
 }
 
 func (s *Server) handleDismissArticle(w http.ResponseWriter, r *http.Request) {
@@ -111,14 +250,31 @@ func (s *Server) handleDismissArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.store.DeleteArticle(r.Context(), id); err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to dismiss: %v", err))
+	if err := s.store.DismissArticle(r.Context(), id); err != nil {
+		s.fail(w, r, "article", err)
 		return
 	}
 
+	s.events.Publish(events.ArticleDismissed, events.ArticleState{ArticleID: id})
 	JSON(w, http.StatusOK, map[string]string{"message": "article dismissed"})
 }
 
+func (s *Server) handleRestoreArticle(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid article id")
+		return
+	}
+
+	if err := s.store.RestoreArticle(r.Context(), id); err != nil {
+		s.fail(w, r, "article", err)
+		return
+	}
+
+	s.events.Publish(events.ArticleRestored, events.ArticleState{ArticleID: id})
+	JSON(w, http.StatusOK, map[string]string{"message": "article restored"})
+}
+
 func (s *Server) handleGetArticle(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -129,49 +285,64 @@ func (s *Server) handleGetArticle(w http.ResponseWriter, r *http.Request) {
 
 	article, err := s.store.GetArticleByID(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, core.ErrNotFound) {
-			Error(w, http.StatusNotFound, "article not found")
-			return
-		}
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch article: %v", err))
+		s.fail(w, r, "article", err)
 		return
 	}
 
 	JSON(w, http.StatusOK, article)
 }
 
+// handleGetArticles is the unrestricted list: unlike the daily view it
+// defaults to ranking by score, read or not.
 func (s *Server) handleGetArticles(w http.ResponseWriter, r *http.Request) {
-	limitStr := r.URL.Query().Get("limit")
-	limit := 20
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	q, err := parseArticleQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
 	}
-
-	tagsParam := r.URL.Query().Get("tags")
-	var tags []string
-	if tagsParam != "" {
-		for _, t := range strings.Split(tagsParam, ",") {
-			t = strings.TrimSpace(t)
-			if t != "" {
-				tags = append(tags, t)
-			}
-		}
+	if q.Filter.Sort == "" {
+		q.Filter.Sort = core.SortScore
 	}
+	s.listArticles(w, r, q)
+}
 
-	articles, err := s.store.GetArticlesByTags(r.Context(), tags, limit)
+// listArticles runs q and writes the list envelope shared by every article
+// list endpoint.
+func (s *Server) listArticles(w http.ResponseWriter, r *http.Request, q articleQuery) {
+	page, err := s.store.ListArticles(r.Context(), q.Filter, q.Cursor, q.Limit)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch articles: %v", err))
+		s.fail(w, r, "article", err)
 		return
 	}
-	JSON(w, http.StatusOK, articles)
+	articles := page.Articles
+	if articles == nil {
+		articles = []core.Article{}
+	}
+	JSON(w, http.StatusOK, map[string]any{"articles": articles, "next_cursor": page.NextCursor, "limit": q.Limit})
 }
 
 func (s *Server) handleGetTags(w http.ResponseWriter, r *http.Request) {
 	tags, err := s.store.GetAllTags(r.Context())
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch tags: %v", err))
+		s.fail(w, r, "tag", err)
+		return
+	}
+	JSON(w, http.StatusOK, tags)
+}
+
+// handleGetRelatedTags lists the tags found alongside name on the articles
+// the list filters match, so a tag selection can be narrowed step by step.
+func (s *Server) handleGetRelatedTags(w http.ResponseWriter, r *http.Request) {
+	q, err := parseArticleQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q.Filter.RequireTags = append(q.Filter.RequireTags, r.PathValue("name"))
+
+	tags, err := s.store.GetRelatedTags(r.Context(), q.Filter, q.Limit)
+	if err != nil {
+		s.fail(w, r, "tag", err)
 		return
 	}
 	JSON(w, http.StatusOK, tags)
@@ -186,10 +357,11 @@ func (s *Server) handleMarkRead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.store.MarkArticleRead(r.Context(), id); err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to mark read: %v", err))
+		s.fail(w, r, "article", err)
 		return
 	}
 
+	s.events.Publish(events.ArticleRead, events.ArticleState{ArticleID: id})
 	JSON(w, http.StatusOK, map[string]string{"message": "marked as read"})
 }
 
@@ -202,10 +374,11 @@ func (s *Server) handleMarkUnread(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.store.MarkArticleUnread(r.Context(), id); err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to mark unread: %v", err))
+		s.fail(w, r, "article", err)
 		return
 	}
 
+	s.events.Publish(events.ArticleUnread, events.ArticleState{ArticleID: id})
 	JSON(w, http.StatusOK, map[string]string{"message": "marked as unread"})
 }
 
@@ -219,18 +392,146 @@ func (s *Server) handleToggleSaved(w http.ResponseWriter, r *http.Request) {
 
 	saved, err := s.store.ToggleArticleSaved(r.Context(), id)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to toggle saved: %v", err))
+		s.fail(w, r, "article", err)
 		return
 	}
 
+	if saved {
+		s.events.Publish(events.ArticleSaved, events.ArticleState{ArticleID: id})
+	} else {
+		s.events.Publish(events.ArticleUnsaved, events.ArticleState{ArticleID: id})
+	}
 	JSON(w, http.StatusOK, map[string]bool{"saved": saved})
 }
 
 func (s *Server) handleGetSaved(w http.ResponseWriter, r *http.Request) {
-	articles, err := s.store.GetSavedArticles(r.Context())
+	q, err := parseSavedQuery(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.listArticles(w, r, q)
+}
+
+type feedSettingsRequest struct {
+	Headers               map[string]string `json:"headers"`
+	Username              string            `json:"username"`
+	Password              string            `json:"password"`
+	BearerToken           string            `json:"bearer_token"`
+	Cookie                string            `json:"cookie"`
+	UserAgent             string            `json:"user_agent"`
+	ProxyURL              string            `json:"proxy_url"`
+	TLSInsecureSkipVerify bool              `json:"tls_insecure_skip_verify"`
+	TLSServerName         string            `json:"tls_server_name"`
+}
+
+// feedSettingsView never echoes secrets back; it only reports which are set.
+type feedSettingsView struct {
+	FeedID                int64    `json:"feed_id"`
+	HeaderNames           []string `json:"header_names"`
+	Username              string   `json:"username"`
+	HasPassword           bool     `json:"has_password"`
+	UserAgent             string   `json:"user_agent"`
+	HasProxy              bool     `json:"has_proxy"`
+	TLSInsecureSkipVerify bool     `json:"tls_insecure_skip_verify"`
+	TLSServerName         string   `json:"tls_server_name"`
+}
+
+func toFeedSettingsView(settings core.FeedSettings) feedSettingsView {
+	names := make([]string, 0, len(settings.Headers))
+	for name := range settings.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return feedSettingsView{
+		FeedID:                settings.FeedID,
+		HeaderNames:           names,
+		Username:              settings.Username,
+		HasPassword:           settings.Password != "",
+		UserAgent:             settings.UserAgent,
+		HasProxy:              settings.ProxyURL != "",
+		TLSInsecureSkipVerify: settings.TLSInsecureSkipVerify,
+		TLSServerName:         settings.TLSServerName,
+	}
+}
+
+func (s *Server) handleGetFeedSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	settings, err := s.store.GetFeedSettings(r.Context(), id)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, fmt.Sprintf("failed to fetch saved articles: %v", err))
+		s.fail(w, r, "feed", err)
+		return
+	}
+
+	JSON(w, http.StatusOK, toFeedSettingsView(settings))
+}
+
+// handleUpdateFeedSettings replaces all settings for a feed. Secrets are
+// write-only, so clients must resend them with every update.
+func (s *Server) handleUpdateFeedSettings(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid feed id")
+		return
+	}
+
+	var req feedSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.ProxyURL != "" {
+		u, err := url.Parse(req.ProxyURL)
+		if err != nil || u.Host == "" {
+			Error(w, http.StatusBadRequest, "invalid proxy_url")
+			return
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			Error(w, http.StatusBadRequest, "proxy_url scheme must be http, https, socks5 or socks5h")
+			return
+		}
+	}
+
+	headers := make(map[string]string, len(req.Headers)+2)
+	for k, v := range req.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	if req.BearerToken != "" {
+		headers["Authorization"] = "Bearer " + req.BearerToken
+	}
+	if req.Cookie != "" {
+		headers["Cookie"] = req.Cookie
+	}
+	// Basic auth and an Authorization header would overwrite each other.
+	if _, ok := headers["Authorization"]; ok && (req.Username != "" || req.Password != "") {
+		Error(w, http.StatusBadRequest, "set either username and password or an Authorization header, not both")
+		return
+	}
+
+	settings := core.FeedSettings{
+		FeedID:                id,
+		Headers:               headers,
+		Username:              req.Username,
+		Password:              req.Password,
+		UserAgent:             req.UserAgent,
+		ProxyURL:              req.ProxyURL,
+		TLSInsecureSkipVerify: req.TLSInsecureSkipVerify,
+		TLSServerName:         req.TLSServerName,
+	}
+
+	if err := s.store.SaveFeedSettings(r.Context(), settings); err != nil {
+		s.fail(w, r, "feed", err)
 		return
 	}
-	JSON(w, http.StatusOK, articles)
+
+	JSON(w, http.StatusOK, toFeedSettingsView(settings))
 }
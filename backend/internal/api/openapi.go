@@ -0,0 +1,19 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes every route registered in Routes. openapi_test.go
+// keeps the two in step and checks requests and responses against it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// handleOpenAPI serves the document as is, outside the data envelope, so
+// generic OpenAPI tooling can load it.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
@@ -0,0 +1,688 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/store"
+	"dailysynapse/backend/internal/syncer"
+	"dailysynapse/backend/pkg/client"
+	"dailysynapse/backend/pkg/secret"
+)
+
+// The types below cover the subset of OpenAPI 3.0 that openapi.json uses.
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Required bool                 `json:"required"`
+		Content  map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"responses"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinProperties        int                `json:"minProperties"`
+	MaxItems             *int               `json:"maxItems"`
+}
+
+func loadOpenAPI(t *testing.T) *openAPIDoc {
+	t.Helper()
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json does not parse: %v", err)
+	}
+	return &doc
+}
+
+// find returns the operation serving method and path, and the values of
+// its path parameters.
+func (d *openAPIDoc) find(method, path string) (*operation, map[string]string) {
+	segments := strings.Split(path, "/")
+	for tmpl, ops := range d.Paths {
+		op, ok := ops[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tmpl, "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for i, p := range parts {
+			if strings.HasPrefix(p, "{") && segments[i] != "" {
+				params[strings.Trim(p, "{}")] = segments[i]
+			} else if p != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return op, params
+		}
+	}
+	return nil, nil
+}
+
+func (d *openAPIDoc) resolve(s *schema) *schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// validate checks a decoded JSON value against s.
+func (d *openAPIDoc) validate(s *schema, v any, at string) error {
+	s = d.resolve(s)
+	if s == nil {
+		return fmt.Errorf("%s: unresolvable schema", at)
+	}
+	if v == nil {
+		if s.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: null is not allowed", at)
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+		return fmt.Errorf("%s: %v is not one of %v", at, v, s.Enum)
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: want an object, got %T", at, v)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+		if len(obj) < s.MinProperties {
+			return fmt.Errorf("%s: want at least %d properties", at, s.MinProperties)
+		}
+		var extra *schema
+		if len(s.AdditionalProperties) > 0 && string(s.AdditionalProperties) != "false" && string(s.AdditionalProperties) != "true" {
+			extra = &schema{}
+			json.Unmarshal(s.AdditionalProperties, extra)
+		}
+		for name, value := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = extra
+			}
+			if prop == nil {
+				if string(s.AdditionalProperties) == "false" {
+					return fmt.Errorf("%s: unexpected property %q", at, name)
+				}
+				continue
+			}
+			if err := d.validate(prop, value, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: want an array, got %T", at, v)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			return fmt.Errorf("%s: more than %d items", at, *s.MaxItems)
+		}
+		for i, item := range items {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: want a string, got %T", at, v)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", at, str)
+			}
+		}
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("%s: want a number, got %T", at, v)
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("%s: %v is not an integer", at, n)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: %v is below %v", at, n, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: %v is above %v", at, n, *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: want a boolean, got %T", at, v)
+		}
+	}
+	return nil
+}
+
+// validateParam checks a path or query parameter's raw string.
+func (d *openAPIDoc) validateParam(p parameter, raw string) error {
+	s := d.resolve(p.Schema)
+	var v any = raw
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %q is not an integer", p.Name, raw)
+		}
+		v = float64(n)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %q is not a boolean", p.Name, raw)
+		}
+		v = b
+	}
+	return d.validate(s, v, "parameter "+p.Name)
+}
+
+// checkRequest validates a request against the document and returns the
+// operation it targets.
+func (d *openAPIDoc) checkRequest(method string, u *url.URL, contentType string, body []byte) (*operation, error) {
+	op, pathParams := d.find(method, u.Path)
+	if op == nil {
+		return nil, fmt.Errorf("%s %s is not in the document", method, u.Path)
+	}
+
+	query := u.Query()
+	known := map[string]bool{}
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			if err := d.validateParam(p, pathParams[p.Name]); err != nil {
+				return op, err
+			}
+		case "query":
+			known[p.Name] = true
+			if raw, ok := query[p.Name]; ok {
+				if err := d.validateParam(p, raw[0]); err != nil {
+					return op, err
+				}
+			} else if p.Required {
+				return op, fmt.Errorf("missing required query parameter %s", p.Name)
+			}
+		}
+	}
+	for name := range query {
+		if !known[name] {
+			return op, fmt.Errorf("unknown query parameter %s", name)
+		}
+	}
+
+	if op.RequestBody == nil {
+		if len(body) > 0 {
+			return op, fmt.Errorf("%s %s takes no body", method, u.Path)
+		}
+		return op, nil
+	}
+	if len(body) == 0 {
+		if op.RequestBody.Required {
+			return op, fmt.Errorf("%s %s needs a body", method, u.Path)
+		}
+		return op, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		return op, fmt.Errorf("%s %s does not take %q", method, u.Path, contentType)
+	}
+	if mediaType != "application/json" {
+		return op, nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return op, fmt.Errorf("request body is not JSON: %v", err)
+	}
+	return op, d.validate(media.Schema, v, "body")
+}
+
+// checkResponse validates a response against what op declares.
+func (d *openAPIDoc) checkResponse(op *operation, status int, contentType string, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("%s: undeclared status %d", op.OperationID, status)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		if len(resp.Content) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s: undeclared content type %q for %d", op.OperationID, contentType, status)
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("%s: response is not JSON: %v", op.OperationID, err)
+	}
+	if err := d.validate(media.Schema, v, "response"); err != nil {
+		return fmt.Errorf("%s %d: %w", op.OperationID, status, err)
+	}
+	return nil
+}
+
+// specTransport fails the test for any request or response that strays
+// from the document.
+type specTransport struct {
+	t   *testing.T
+	doc *openAPIDoc
+}
+
+func (st specTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	op, err := st.doc.checkRequest(req.Method, req.URL, req.Header.Get("Content-Type"), body)
+	if err != nil {
+		st.t.Errorf("request does not match openapi.json: %v", err)
+	}
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil || op == nil {
+		return resp, err
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// Streams never end; only their status and media type are checked.
+		if err := st.doc.checkResponse(op, resp.StatusCode, resp.Header.Get("Content-Type"), nil); err != nil {
+			st.t.Errorf("%s %s: response does not match openapi.json: %v", req.Method, req.URL.Path, err)
+		}
+		return resp, nil
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err := st.doc.checkResponse(op, resp.StatusCode, resp.Header.Get("Content-Type"), respBody); err != nil {
+		st.t.Errorf("%s %s: response does not match openapi.json: %v\n%s", req.Method, req.URL.Path, err, respBody)
+	}
+	return resp, nil
+}
+
+func newTestServer(t *testing.T) (*Server, *store.Queries) {
+	t.Helper()
+	// Migrations are found relative to the repository root.
+	t.Chdir("../../..")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	box, err := secret.NewBox("test key")
+	if err != nil {
+		t.Fatalf("secret.NewBox() error = %v", err)
+	}
+	q := store.NewQueries(db).WithSecretBox(box)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{HTTPTimeout: time.Second, DeleteGracePeriod: time.Hour}
+	bus := events.New()
+	return NewServer(db, q, syncer.New(q, cfg, bus, logger), bus, logger), q
+}
+
+func TestOpenAPI_CoversEveryRoute(t *testing.T) {
+	doc := loadOpenAPI(t)
+	s := &Server{}
+	s.Routes()
+
+	routes := map[string]bool{}
+	for _, pattern := range s.patterns {
+		method, path, _ := strings.Cut(pattern, " ")
+		switch {
+		case path == "/{$}":
+			path = "/"
+		case strings.HasSuffix(path, "/"):
+			path += "{path}"
+		}
+		key := strings.ToLower(method) + " " + path
+		routes[key] = true
+		if _, ok := doc.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("route %q is missing from openapi.json", pattern)
+		}
+	}
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			if !routes[method+" "+path] {
+				t.Errorf("openapi.json documents %s %s, which is not routed", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestOpenAPI_RejectsInvalidRequests(t *testing.T) {
+	doc := loadOpenAPI(t)
+	tests := []struct {
+		method, target, body string
+	}{
+		{"POST", "/api/feeds", `{"url": 5}`},
+		{"POST", "/api/feeds", `{"name": "missing url"}`},
+		{"POST", "/api/feeds", `{"url": "https://example.com", "kind": "rss"}`},
+		{"POST", "/api/articles/bulk", `{"action": "explode", "ids": [1]}`},
+		{"PATCH", "/api/feeds/1", `{}`},
+		{"GET", "/api/daily?sort=random", ""},
+		{"GET", "/api/daily?limit=500", ""},
+		{"GET", "/api/daily?page=2", ""},
+		{"GET", "/api/articles/abc", ""},
+		{"GET", "/api/nothing", ""},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.target)
+		if _, err := doc.checkRequest(tt.method, u, "application/json", []byte(tt.body)); err == nil {
+			t.Errorf("checkRequest(%s %s %s) = nil, want an error", tt.method, tt.target, tt.body)
+		}
+	}
+}
+
+// TestOpenAPI_ClientRoundTrip drives the API through pkg/client, checking
+// every request and response against the document on the way.
+func TestOpenAPI_ClientRoundTrip(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+
+	c := client.New(ts.URL, client.WithHTTPClient(&http.Client{Transport: specTransport{t: t, doc: loadOpenAPI(t)}}))
+	ctx := context.Background()
+
+	if err := c.Health(ctx); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if err := c.Ready(ctx); err != nil {
+		t.Fatalf("Ready() error = %v", err)
+	}
+
+	feed, err := c.CreateFeed(ctx, client.CreateFeedRequest{URL: "https://example.com/feed.xml", Name: "Example"})
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if _, err := c.CreateFeed(ctx, client.CreateFeedRequest{URL: "https://example.com/feed.xml"}); !isCode(err, http.StatusConflict, "feed_exists") {
+		t.Errorf("CreateFeed() duplicate error = %v, want 409 feed_exists", err)
+	}
+	// The document's enum would reject an unknown type before the handler.
+	_, err = client.New(ts.URL).CreateFeed(ctx, client.CreateFeedRequest{URL: "https://example.com/other.xml", Type: "gopher"})
+	var typeErr *client.Error
+	if !errors.As(err, &typeErr) || typeErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("CreateFeed() unknown type error = %v, want 400", err)
+	} else {
+		for _, typ := range syncer.SourceTypes {
+			if !strings.Contains(typeErr.Message, typ) {
+				t.Errorf("CreateFeed() unknown type message = %q, want it to list %s", typeErr.Message, typ)
+			}
+		}
+	}
+	if feeds, err := c.ListFeeds(ctx); err != nil || len(feeds) != 1 {
+		t.Fatalf("ListFeeds() = %d feeds, %v, want 1", len(feeds), err)
+	}
+
+	category, err := c.CreateCategory(ctx, client.CategoryRequest{Name: "Tech", MinScore: 10})
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	if _, err := c.CreateCategory(ctx, client.CategoryRequest{Name: "Tech"}); !isCode(err, http.StatusConflict, "category_exists") {
+		t.Errorf("CreateCategory() duplicate error = %v, want 409 category_exists", err)
+	}
+	if _, err := c.UpdateCategory(ctx, category.ID, client.CategoryRequest{Name: "Technology", MinScore: 20}); err != nil {
+		t.Errorf("UpdateCategory() error = %v", err)
+	}
+	name, paused := "Renamed", true
+	updated, err := c.UpdateFeed(ctx, feed.ID, client.FeedPatch{Name: &name, Paused: &paused, CategoryID: &category.ID})
+	if err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+	if updated.Name != name || updated.Status != "paused" || updated.CategoryID != category.ID {
+		t.Errorf("UpdateFeed() = %+v", updated)
+	}
+	if _, err := c.ListCategories(ctx); err != nil {
+		t.Errorf("ListCategories() error = %v", err)
+	}
+
+	opml := `<opml version="2.0"><body><outline text="Example" xmlUrl="https://example.com/feed.xml"/><outline text="Bad" xmlUrl="ftp://x"/></body></opml>`
+	result, err := c.ImportOPML(ctx, strings.NewReader(opml))
+	if err != nil {
+		t.Fatalf("ImportOPML() error = %v", err)
+	}
+	if result.Skipped != 1 || result.Failed != 1 {
+		t.Errorf("ImportOPML() = %+v, want 1 skipped and 1 failed", result)
+	}
+
+	if _, err := c.UpdateFeedSettings(ctx, feed.ID, client.FeedSettingsRequest{UserAgent: "test", Password: "hunter2", Username: "me"}); err != nil {
+		t.Errorf("UpdateFeedSettings() error = %v", err)
+	}
+	settings, err := c.GetFeedSettings(ctx, feed.ID)
+	if err != nil || !settings.HasPassword || settings.UserAgent != "test" {
+		t.Errorf("GetFeedSettings() = %+v, %v", settings, err)
+	}
+	both := client.FeedSettingsRequest{Username: "me", Password: "hunter2", BearerToken: "abc123"}
+	if _, err := c.UpdateFeedSettings(ctx, feed.ID, both); !isCode(err, http.StatusBadRequest, CodeInvalidRequest) {
+		t.Errorf("UpdateFeedSettings() with basic auth and a bearer token error = %v, want 400 %s", err, CodeInvalidRequest)
+	}
+	if _, err := c.GetFeedSettings(ctx, 9999); !isCode(err, http.StatusNotFound, "feed_not_found") {
+		t.Errorf("GetFeedSettings() missing error = %v, want 404 feed_not_found", err)
+	}
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID: feed.ID, Title: fmt.Sprintf("Article %d", i), URL: fmt.Sprintf("https://example.com/%d", i),
+			PublishedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, 70+i, "Summary", "Why", "model", []string{"Go"}); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	first, err := c.Daily(ctx, client.ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("Daily() error = %v", err)
+	}
+	rest, err := c.Daily(ctx, client.ListOptions{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("Daily() next page error = %v", err)
+	}
+	if len(first.Articles) != 2 || len(rest.Articles) != 1 || rest.NextCursor != "" {
+		t.Errorf("Daily() pages = %d then %d, want 2 then 1", len(first.Articles), len(rest.Articles))
+	}
+	for _, a := range first.Articles {
+		if len(a.Tags) != 1 || a.Tags[0] != "Go" {
+			t.Errorf("Daily() article %d tags = %v, want [Go]", a.ID, a.Tags)
+		}
+	}
+	if list, err := c.Articles(ctx, client.ListOptions{Tags: []string{"Go"}, MatchAll: true, MinScore: 71, Sort: "date", Since: time.Now().Add(-24 * time.Hour)}); err != nil || len(list.Articles) != 2 {
+		t.Errorf("Articles() = %d, %v, want 2", len(list.Articles), err)
+	}
+	if err := q.AddArticleTags(ctx, ids[0], []string{"C++"}); err != nil {
+		t.Fatalf("AddArticleTags() error = %v", err)
+	}
+	if list, err := c.Articles(ctx, client.ListOptions{RequireTags: []string{"Go", "C++"}}); err != nil || len(list.Articles) != 1 {
+		t.Errorf("Articles() tagged Go and C++ = %d, %v, want 1", len(list.Articles), err)
+	}
+	if list, err := c.Articles(ctx, client.ListOptions{Tags: []string{"Go"}, ExcludeTags: []string{"C++"}}); err != nil || len(list.Articles) != 2 {
+		t.Errorf("Articles() tagged Go but not C++ = %d, %v, want 2", len(list.Articles), err)
+	}
+	if related, err := c.RelatedTags(ctx, "Go", client.ListOptions{}); err != nil || len(related) != 1 || related[0] != (client.TagCount{Name: "C++", Count: 1}) {
+		t.Errorf("RelatedTags() = %+v, %v, want C++ once", related, err)
+	}
+
+	prefs, err := c.SetPreferences(ctx, client.PreferencesRequest{FollowedTags: []string{"Rust"}, MutedTags: []client.MutedTagRequest{{Tag: "C++"}}})
+	if err != nil || len(prefs.FollowedTags) != 1 || len(prefs.MutedTags) != 1 {
+		t.Errorf("SetPreferences() = %+v, %v", prefs, err)
+	}
+	if _, err := c.SetPreferences(ctx, client.PreferencesRequest{FollowedTags: []string{"Go"}, MutedTags: []client.MutedTagRequest{{Tag: "Go"}}}); !isCode(err, http.StatusBadRequest, CodeInvalidRequest) {
+		t.Errorf("SetPreferences() with a tag followed and muted error = %v, want 400", err)
+	}
+	if daily, err := c.Daily(ctx, client.ListOptions{}); err != nil || len(daily.Articles) != 2 {
+		t.Errorf("Daily() with C++ muted = %d, %v, want 2", len(daily.Articles), err)
+	}
+	if list, err := c.Articles(ctx, client.ListOptions{}); err != nil || len(list.Articles) != 3 {
+		t.Errorf("Articles() with C++ muted = %d, %v, want all 3", len(list.Articles), err)
+	}
+	if _, err := c.SetPreferences(ctx, client.PreferencesRequest{}); err != nil {
+		t.Errorf("SetPreferences() clearing error = %v", err)
+	}
+	if prefs, err := c.GetPreferences(ctx); err != nil || len(prefs.FollowedTags)+len(prefs.MutedTags) != 0 {
+		t.Errorf("GetPreferences() = %+v, %v, want none", prefs, err)
+	}
+
+	if err := c.MarkRead(ctx, ids[0]); err != nil {
+		t.Errorf("MarkRead() error = %v", err)
+	}
+	if err := c.MarkUnread(ctx, ids[0]); err != nil {
+		t.Errorf("MarkUnread() error = %v", err)
+	}
+	if saved, err := c.ToggleSaved(ctx, ids[1]); err != nil || !saved {
+		t.Errorf("ToggleSaved() = %v, %v, want true", saved, err)
+	}
+	if list, err := c.Saved(ctx, client.ListOptions{}); err != nil || len(list.Articles) != 1 {
+		t.Errorf("Saved() = %d, %v, want 1", len(list.Articles), err)
+	}
+	article, err := c.GetArticle(ctx, ids[1])
+	if err != nil || !article.ReadLater {
+		t.Errorf("GetArticle() = %+v, %v", article, err)
+	}
+	if _, err := c.GetArticle(ctx, 9999); !isCode(err, http.StatusNotFound, "article_not_found") {
+		t.Errorf("GetArticle() missing error = %v, want 404 article_not_found", err)
+	}
+	if err := c.MarkRead(ctx, 9999); !isCode(err, http.StatusNotFound, "article_not_found") {
+		t.Errorf("MarkRead() missing error = %v, want 404 article_not_found", err)
+	}
+	if err := c.MarkUnread(ctx, 9999); !isCode(err, http.StatusNotFound, "article_not_found") {
+		t.Errorf("MarkUnread() missing error = %v, want 404 article_not_found", err)
+	}
+	if _, err := c.ToggleSaved(ctx, 9999); !isCode(err, http.StatusNotFound, "article_not_found") {
+		t.Errorf("ToggleSaved() missing error = %v, want 404 article_not_found", err)
+	}
+	if err := c.DismissArticle(ctx, ids[2]); err != nil {
+		t.Errorf("DismissArticle() error = %v", err)
+	}
+	if err := c.RestoreArticle(ctx, ids[2]); err != nil {
+		t.Errorf("RestoreArticle() error = %v", err)
+	}
+	unread := false
+	bulk, err := c.Bulk(ctx, client.BulkRequest{Action: "read", Filter: &client.BulkFilter{Feed: feed.ID, Saved: &unread}})
+	if err != nil || bulk.Affected != 2 {
+		t.Errorf("Bulk() = %+v, %v, want 2 affected", bulk, err)
+	}
+	if tags, err := c.ListTags(ctx); err != nil || len(tags) != 2 {
+		t.Errorf("ListTags() = %v, %v", tags, err)
+	}
+
+	detail, err := c.GetFeed(ctx, feed.ID, 2, 0)
+	if err != nil {
+		t.Fatalf("GetFeed() error = %v", err)
+	}
+	if detail.Total != 3 || len(detail.Articles) != 2 || detail.Stats.ScoredCount != 3 {
+		t.Errorf("GetFeed() total %d, %d articles, %d scored", detail.Total, len(detail.Articles), detail.Stats.ScoredCount)
+	}
+
+	rule, err := c.CreateRule(ctx, client.IngestRuleRequest{Field: "title", MatchType: "substring", Pattern: "sponsored", Action: "drop"})
+	if err != nil {
+		t.Fatalf("CreateRule() error = %v", err)
+	}
+	if _, err := c.GetRule(ctx, rule.ID); err != nil {
+		t.Errorf("GetRule() error = %v", err)
+	}
+	if _, err := c.UpdateRule(ctx, rule.ID, client.IngestRuleRequest{Field: "any", MatchType: "regex", Pattern: "(?i)ad", Action: "tag", Tags: []string{"Ads"}}); err != nil {
+		t.Errorf("UpdateRule() error = %v", err)
+	}
+	if rules, err := c.ListRules(ctx); err != nil || len(rules) != 1 {
+		t.Errorf("ListRules() = %d, %v, want 1", len(rules), err)
+	}
+	if err := c.DeleteRule(ctx, rule.ID); err != nil {
+		t.Errorf("DeleteRule() error = %v", err)
+	}
+
+	if err := c.DeleteCategory(ctx, category.ID); err != nil {
+		t.Errorf("DeleteCategory() error = %v", err)
+	}
+	if err := c.DeleteFeed(ctx, feed.ID); err != nil {
+		t.Errorf("DeleteFeed() error = %v", err)
+	}
+	if _, err := c.RestoreFeed(ctx, feed.ID); err != nil {
+		t.Errorf("RestoreFeed() error = %v", err)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/openapi.json")
+	if err != nil {
+		t.Fatalf("GET /api/openapi.json error = %v", err)
+	}
+	defer resp.Body.Close()
+	served, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(served, openAPISpec) {
+		t.Errorf("GET /api/openapi.json = %d, want the embedded document", resp.StatusCode)
+	}
+}
+
+func isCode(err error, status int, code string) bool {
+	var apiErr *client.Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status && apiErr.Code == code && apiErr.RequestID != ""
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+type mutedTagRequest struct {
+	Tag           string `json:"tag"`
+	OverrideScore int    `json:"override_score"`
+}
+
+type preferencesRequest struct {
+	FollowedTags []string          `json:"followed_tags"`
+	MutedTags    []mutedTagRequest `json:"muted_tags"`
+}
+
+// toPreferences trims tag names and rejects empty or repeated ones, so a
+// tag is either followed or muted.
+func (req preferencesRequest) toPreferences() (core.Preferences, error) {
+	var prefs core.Preferences
+	var seen []string
+	add := func(tag string) (string, error) {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return "", fmt.Errorf("tag names must not be empty")
+		}
+		if slices.Contains(seen, tag) {
+			return "", fmt.Errorf("tag %q is listed more than once", tag)
+		}
+		seen = append(seen, tag)
+		return tag, nil
+	}
+
+	for _, tag := range req.FollowedTags {
+		tag, err := add(tag)
+		if err != nil {
+			return prefs, err
+		}
+		prefs.FollowedTags = append(prefs.FollowedTags, tag)
+	}
+	for _, m := range req.MutedTags {
+		tag, err := add(m.Tag)
+		if err != nil {
+			return prefs, err
+		}
+		if m.OverrideScore < 0 || m.OverrideScore > 100 {
+			return prefs, fmt.Errorf("override_score must be between 0 and 100")
+		}
+		prefs.MutedTags = append(prefs.MutedTags, core.MutedTag{Tag: tag, OverrideScore: m.OverrideScore})
+	}
+	return prefs, nil
+}
+
+func (s *Server) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	prefs, err := s.store.GetPreferences(r.Context())
+	if err != nil {
+		s.fail(w, r, "preferences", err)
+		return
+	}
+	JSON(w, http.StatusOK, prefs)
+}
+
+// handleUpdatePreferences replaces the followed and muted tags.
+func (s *Server) handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req preferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	prefs, err := req.toPreferences()
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.SetPreferences(r.Context(), prefs); err != nil {
+		s.fail(w, r, "preferences", err)
+		return
+	}
+	s.handleGetPreferences(w, r)
+}
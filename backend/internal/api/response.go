@@ -6,8 +6,10 @@ import (
 )
 
 type Response struct {
-	Data  any    `json:"data,omitempty"`
-	Error string `json:"error,omitempty"`
+	Data      any    `json:"data,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Code      string `json:"code,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func JSON(w http.ResponseWriter, status int, data any) {
@@ -16,8 +18,17 @@ func JSON(w http.ResponseWriter, status int, data any) {
 	json.NewEncoder(w).Encode(Response{Data: data})
 }
 
+// Error writes a client-facing message with the generic code for status.
 func Error(w http.ResponseWriter, status int, msg string) {
+	writeError(w, &APIError{Status: status, Code: statusCode(status), Message: msg})
+}
+
+func writeError(w http.ResponseWriter, e *APIError) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(Response{Error: msg})
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(Response{
+		Error:     e.Message,
+		Code:      e.Code,
+		RequestID: w.Header().Get(requestIDHeader),
+	})
 }
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/rules"
+)
+
+type ingestRuleRequest struct {
+	FeedID    int64    `json:"feed_id"`
+	Field     string   `json:"field"`
+	MatchType string   `json:"match_type"`
+	Pattern   string   `json:"pattern"`
+	Action    string   `json:"action"`
+	Tags      []string `json:"tags"`
+	Score     int      `json:"score"`
+	Enabled   *bool    `json:"enabled"`
+}
+
+func (req ingestRuleRequest) toRule() core.IngestRule {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return core.IngestRule{
+		FeedID:    req.FeedID,
+		Field:     req.Field,
+		MatchType: req.MatchType,
+		Pattern:   req.Pattern,
+		Action:    req.Action,
+		Tags:      req.Tags,
+		Score:     req.Score,
+		Enabled:   enabled,
+	}
+}
+
+func (s *Server) handleGetRules(w http.ResponseWriter, r *http.Request) {
+	list, err := s.store.GetIngestRules(r.Context())
+	if err != nil {
+		s.fail(w, r, "rule", err)
+		return
+	}
+	JSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleGetRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid rule id")
+		return
+	}
+
+	rule, err := s.store.GetIngestRule(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "rule", err)
+		return
+	}
+	JSON(w, http.StatusOK, rule)
+}
+
+func (s *Server) handleCreateRule(w http.ResponseWriter, r *http.Request) {
+	var req ingestRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rule := req.toRule()
+	if err := rules.Validate(rule); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := s.store.CreateIngestRule(r.Context(), rule)
+	if err != nil {
+		s.fail(w, r, "rule", err)
+		return
+	}
+	JSON(w, http.StatusCreated, rule)
+}
+
+func (s *Server) handleUpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid rule id")
+		return
+	}
+
+	var req ingestRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rule := req.toRule()
+	rule.ID = id
+	if err := rules.Validate(rule); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.UpdateIngestRule(r.Context(), rule); err != nil {
+		s.fail(w, r, "rule", err)
+		return
+	}
+
+	updated, err := s.store.GetIngestRule(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "rule", err)
+		return
+	}
+	JSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid rule id")
+		return
+	}
+
+	if err := s.store.DeleteIngestRule(r.Context(), id); err != nil {
+		s.fail(w, r, "rule", err)
+		return
+	}
+	JSON(w, http.StatusOK, map[string]string{"message": "rule deleted"})
+}
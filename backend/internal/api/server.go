@@ -7,6 +7,8 @@ import (
 	"log/slog"
 	"net/http"
 
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/notify"
 	"dailysynapse/backend/internal/store"
 	"dailysynapse/backend/internal/syncer"
 )
@@ -15,53 +17,114 @@ import (
 var staticFS embed.FS
 
 type Server struct {
-	db     *sql.DB
-	store  store.Store
-	syncer *syncer.Syncer
-	logger *slog.Logger
+	db       *sql.DB
+	store    store.Store
+	syncer   *syncer.Syncer
+	events   *events.Bus
+	notifier *notify.Notifier
+	logger   *slog.Logger
+
+	patterns []string // registered by Routes, for checking the OpenAPI document
 }
 
-func NewServer(db *sql.DB, s *syncer.Syncer, logger *slog.Logger) *Server {
+func NewServer(db *sql.DB, st store.Store, s *syncer.Syncer, bus *events.Bus, logger *slog.Logger) *Server {
 	return &Server{
 		db:     db,
-		store:  store.NewQueries(db),
+		store:  st,
 		syncer: s,
+		events: bus,
 		logger: logger,
 	}
 }
 
+// WithNotifier returns a copy of s that can send test notifications
+// through n.
+func (s *Server) WithNotifier(n *notify.Notifier) *Server {
+	c := *s
+	c.notifier = n
+	return &c
+}
+
 func (s *Server) Routes() http.Handler {
 	mux := http.NewServeMux()
+	s.patterns = nil
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, h)
+		s.patterns = append(s.patterns, pattern)
+	}
 
 	staticContent, _ := fs.Sub(staticFS, "static")
 	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticContent))))
+	s.patterns = append(s.patterns, "GET /static/")
+
+	handle("GET /{$}", s.handleDailyPage)
+	handle("GET /read/{id}", s.handleReaderPage)
+	handle("GET /feeds", s.handleFeedsPage)
+	handle("POST /feeds", s.handleFeedsPage)
+	handle("POST /feeds/import", s.handleImportOPMLPage)
+	handle("GET /feeds/{id}", s.handleFeedPage)
+	handle("GET /cards/{id}", s.handleArticleCard)
+	handle("GET /digest/{id}/{action}", s.handleDigestConfirm)
+	handle("POST /digest/{id}/{action}", s.handleDigestAction)
+
+	handle("GET /health", s.handleHealth)
+	handle("GET /ready", s.handleReady)
+
+	handle("GET /api/openapi.json", s.handleOpenAPI)
+	handle("POST /api/sync", s.handleSync)
+	handle("GET /api/events", s.handleEvents)
+	handle("GET /api/feeds", s.handleGetFeeds)
+	handle("POST /api/feeds", s.handleCreateFeed)
+	handle("GET /api/feeds/{id}", s.handleGetFeed)
+	handle("PATCH /api/feeds/{id}", s.handleUpdateFeed)
+	handle("DELETE /api/feeds/{id}", s.handleDeleteFeed)
+	handle("POST /api/feeds/{id}/restore", s.handleRestoreFeed)
+	handle("GET /api/feeds/{id}/settings", s.handleGetFeedSettings)
+	handle("PUT /api/feeds/{id}/settings", s.handleUpdateFeedSettings)
+	handle("GET /api/categories", s.handleGetCategories)
+	handle("POST /api/categories", s.handleCreateCategory)
+	handle("PUT /api/categories/{id}", s.handleUpdateCategory)
+	handle("DELETE /api/categories/{id}", s.handleDeleteCategory)
+	handle("POST /api/opml", s.handleImportOPML)
+	handle("GET /api/daily", s.handleGetDaily)
+	handle("GET /api/articles", s.handleGetArticles)
+	handle("POST /api/articles/bulk", s.handleBulkArticles)
+	handle("GET /api/articles/{id}", s.handleGetArticle)
+	handle("POST /api/articles/{id}/read", s.handleMarkRead)
+	handle("POST /api/articles/{id}/unread", s.handleMarkUnread)
+	handle("POST /api/articles/{id}/save", s.handleToggleSaved)
+	handle("DELETE /api/articles/{id}", s.handleDismissArticle)
+	handle("POST /api/articles/{id}/restore", s.handleRestoreArticle)
+	handle("GET /api/saved", s.handleGetSaved)
+	handle("GET /api/export", s.handleExport)
+	handle("GET /api/tags", s.handleGetTags)
+	handle("GET /api/tags/{name}/related", s.handleGetRelatedTags)
+	handle("GET /api/preferences", s.handleGetPreferences)
+	handle("PUT /api/preferences", s.handleUpdatePreferences)
+	handle("GET /api/rules", s.handleGetRules)
+	handle("POST /api/rules", s.handleCreateRule)
+	handle("GET /api/rules/{id}", s.handleGetRule)
+	handle("PUT /api/rules/{id}", s.handleUpdateRule)
+	handle("DELETE /api/rules/{id}", s.handleDeleteRule)
+	handle("GET /api/webhooks", s.handleGetWebhooks)
+	handle("POST /api/webhooks", s.handleCreateWebhook)
+	handle("GET /api/webhooks/{id}", s.handleGetWebhook)
+	handle("PUT /api/webhooks/{id}", s.handleUpdateWebhook)
+	handle("DELETE /api/webhooks/{id}", s.handleDeleteWebhook)
+	handle("GET /api/webhooks/{id}/deliveries", s.handleGetWebhookDeliveries)
+	handle("GET /api/channels", s.handleGetChannels)
+	handle("POST /api/channels", s.handleCreateChannel)
+	handle("GET /api/channels/{id}", s.handleGetChannel)
+	handle("PUT /api/channels/{id}", s.handleUpdateChannel)
+	handle("DELETE /api/channels/{id}", s.handleDeleteChannel)
+	handle("POST /api/channels/{id}/test", s.handleTestChannel)
 
-	mux.HandleFunc("GET /{$}", s.handleDailyPage)
-	mux.HandleFunc("GET /read/{id}", s.handleReaderPage)
-	mux.HandleFunc("GET /feeds", s.handleFeedsPage)
-	mux.HandleFunc("POST /feeds", s.handleFeedsPage)
-
-	mux.HandleFunc("GET /health", s.handleHealth)
-	mux.HandleFunc("GET /ready", s.handleReady)
-
-	mux.HandleFunc("POST /api/sync", s.handleSync)
-	mux.HandleFunc("GET /api/feeds", s.handleGetFeeds)
-	mux.HandleFunc("POST /api/feeds", s.handleCreateFeed)
-	mux.HandleFunc("DELETE /api/feeds/{id}", s.handleDeleteFeed)
-	mux.HandleFunc("GET /api/daily", s.handleGetDaily)
-	mux.HandleFunc("GET /api/articles", s.handleGetArticles)
-	mux.HandleFunc("GET /api/articles/{id}", s.handleGetArticle)
-	mux.HandleFunc("POST /api/articles/{id}/read", s.handleMarkRead)
-	mux.HandleFunc("POST /api/articles/{id}/unread", s.handleMarkUnread)
-	mux.HandleFunc("POST /api/articles/{id}/save", s.handleToggleSaved)
-	mux.HandleFunc("DELETE /api/articles/{id}", s.handleDismissArticle)
-	mux.HandleFunc("GET /api/saved", s.handleGetSaved)
-	mux.HandleFunc("GET /api/tags", s.handleGetTags)
-
-	mux.HandleFunc("GET /saved", s.handleSavedPage)
+	handle("GET /saved", s.handleSavedPage)
+	handle("GET /settings", s.handleSettingsPage)
 
 	return chain(mux,
 		corsMiddleware,
+		requestIDMiddleware,
 		loggingMiddleware(s.logger),
 		recoveryMiddleware(s.logger),
 	)
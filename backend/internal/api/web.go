@@ -3,13 +3,18 @@ package api
 import (
 	"context"
 	"embed"
+	"errors"
+	"fmt"
 	"html/template"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/syncer"
 )
 
 //go:embed templates/*.html
@@ -20,7 +25,7 @@ var pageTemplates map[string]*template.Template
 func init() {
 	pageTemplates = make(map[string]*template.Template)
 
-	pages := []string{"daily", "reader", "feeds", "saved"}
+	pages := []string{"daily", "reader", "feeds", "feed", "saved", "settings", "digest"}
 	for _, page := range pages {
 		t := template.Must(template.ParseFS(templatesFS, "templates/base.html", "templates/"+page+".html"))
 		pageTemplates[page] = t
@@ -31,11 +36,11 @@ type ArticleView struct {
 	core.Article
 	ReadingTime   int
 	FormattedDate string
-	Tags          []string
 	Content       template.HTML
+	HasOriginal   bool // false for newsletters that only exist as mail
 }
 
-func toArticleView(a core.Article, tags []string) ArticleView {
+func toArticleView(a core.Article) ArticleView {
 	wordCount := len(strings.Fields(a.Content))
 	readingTime := wordCount / 200
 	if readingTime < 1 {
@@ -46,8 +51,8 @@ func toArticleView(a core.Article, tags []string) ArticleView {
 		Article:       a,
 		ReadingTime:   readingTime,
 		FormattedDate: a.PublishedAt.Format("January 2, 2006"),
-		Tags:          tags,
 		Content:       template.HTML(a.Content),
+		HasOriginal:   strings.HasPrefix(a.URL, "http://") || strings.HasPrefix(a.URL, "https://"),
 	}
 }
 
@@ -57,53 +62,63 @@ func renderPage(w http.ResponseWriter, page string, data any) error {
 }
 
 func (s *Server) handleDailyPage(w http.ResponseWriter, r *http.Request) {
-	pageStr := r.URL.Query().Get("page")
-	page := 1
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	q, err := parseDailyQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	perPage := 20
-	offset := (page - 1) * perPage
-
-	articles, total, err := s.store.GetTopArticles(r.Context(), perPage, offset)
+	page, err := s.store.ListArticles(r.Context(), q.Filter, q.Cursor, q.Limit)
 	if err != nil {
+		if errors.Is(err, core.ErrBadRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		s.logger.Error("failed to get articles", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
 	var views []ArticleView
-	for _, a := range articles {
-		tags, _ := s.store.GetArticleTags(r.Context(), a.ID)
-		views = append(views, toArticleView(a, tags))
+	for _, a := range page.Articles {
+		views = append(views, toArticleView(a))
 	}
 
-	allTags, _ := s.store.GetAllTags(r.Context())
 	// Limit to top 15 most popular tags for better UX
-	if len(allTags) > 15 {
-		allTags = allTags[:15]
+	related, err := s.store.GetRelatedTags(r.Context(), q.Filter, 15)
+	if err != nil {
+		s.logger.Error("failed to get related tags", "error", err)
 	}
 
-	totalPages := (total + perPage - 1) / perPage
-	if totalPages < 1 {
-		totalPages = 1
+	categories, err := s.store.GetCategories(r.Context())
+	if err != nil {
+		s.logger.Error("failed to get categories", "error", err)
+	}
+
+	read := "all"
+	if q.Filter.Read != nil && *q.Filter.Read {
+		read = "read"
+	} else if q.Filter.Read != nil {
+		read = "unread"
 	}
 
 	data := map[string]any{
 		"Nav":        "daily",
 		"Date":       time.Now().Format("Monday, January 2"),
 		"Articles":   views,
-		"Tags":       allTags,
-		"Page":       page,
-		"TotalPages": totalPages,
-		"Total":      total,
-		"HasPrev":    page > 1,
-		"HasNext":    page < totalPages,
-		"PrevPage":   page - 1,
-		"NextPage":   page + 1,
+		"Tags":       tagChips(r, related),
+		"TagTerms":   tagTermLinks(r),
+		"AllTagsURL": tagsURL(r, nil),
+		"TagQuery":   r.URL.Query().Get("tags"),
+		"Categories": categoryLinks(r, categories, q.Filter.CategoryID),
+		"CategoryID": q.Filter.CategoryID,
+		"AllURL":     categoryURL(r, 0),
+		"Sort":       string(q.Filter.Sort),
+		"Read":       read,
+		"MinScore":   q.Filter.MinScore,
+		"BulkFilter": bulkFilterFromQuery(r.URL.Query()),
+		"NextURL":    pageURL(r, page.NextCursor),
+		"FirstURL":   firstPageURL(r, q.Cursor),
 	}
 
 	if err := renderPage(w, "daily", data); err != nil {
@@ -111,6 +126,120 @@ func (s *Server) handleDailyPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// categoryLink is an entry in the daily page's category bar.
+type categoryLink struct {
+	Name   string
+	URL    string
+	Active bool
+}
+
+func categoryLinks(r *http.Request, categories []core.Category, active int64) []categoryLink {
+	var links []categoryLink
+	for _, c := range categories {
+		links = append(links, categoryLink{Name: c.Name, URL: categoryURL(r, c.ID), Active: c.ID == active})
+	}
+	return links
+}
+
+// categoryURL switches the current list to another category, starting over
+// from the first page. id 0 shows every category.
+func categoryURL(r *http.Request, id int64) string {
+	v := r.URL.Query()
+	v.Del("cursor")
+	v.Del("category")
+	if id != 0 {
+		v.Set("category", strconv.FormatInt(id, 10))
+	}
+	if len(v) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + v.Encode()
+}
+
+// tagChip is a tag in the daily page's chip bar. URL narrows the list to
+// articles that also carry it; for a selected term it drops the term.
+type tagChip struct {
+	Name  string
+	Count int
+	URL   string
+}
+
+// tagChips offers the tags found on the listed articles as the next step of
+// the current tag selection.
+func tagChips(r *http.Request, tags []core.TagCount) []tagChip {
+	terms := tagTerms(r)
+	var chips []tagChip
+	for _, t := range tags {
+		next := "+" + t.Name
+		if len(terms) == 0 {
+			next = t.Name
+		}
+		chips = append(chips, tagChip{Name: t.Name, Count: t.Count, URL: tagsURL(r, append(slices.Clone(terms), next))})
+	}
+	return chips
+}
+
+// tagTermLinks lists the selected tag terms, each linking to the list
+// without it.
+func tagTermLinks(r *http.Request) []tagChip {
+	terms := tagTerms(r)
+	var chips []tagChip
+	for i, term := range terms {
+		chips = append(chips, tagChip{Name: term, URL: tagsURL(r, slices.Delete(slices.Clone(terms), i, i+1))})
+	}
+	return chips
+}
+
+func tagTerms(r *http.Request) []string {
+	var terms []string
+	for _, t := range strings.Split(r.URL.Query().Get("tags"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// tagsURL shows the current list with another tag selection, starting over
+// from the first page.
+func tagsURL(r *http.Request, terms []string) string {
+	v := r.URL.Query()
+	v.Del("cursor")
+	v.Del("tags")
+	if len(terms) > 0 {
+		v.Set("tags", strings.Join(terms, ","))
+	}
+	if len(v) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + v.Encode()
+}
+
+// pageURL links to the page at cursor with the current filters kept, or
+// returns "" when there is no such page.
+func pageURL(r *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	v := r.URL.Query()
+	v.Set("cursor", cursor)
+	return r.URL.Path + "?" + v.Encode()
+}
+
+// firstPageURL links back to the first page when the request is for a
+// later one.
+func firstPageURL(r *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	v := r.URL.Query()
+	v.Del("cursor")
+	if len(v) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + v.Encode()
+}
+
 func (s *Server) handleReaderPage(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -130,8 +259,7 @@ func (s *Server) handleReaderPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tags, _ := s.store.GetArticleTags(r.Context(), article.ID)
-	view := toArticleView(*article, tags)
+	view := toArticleView(*article)
 
 	data := map[string]any{
 		"Nav":     "daily",
@@ -144,15 +272,183 @@ func (s *Server) handleReaderPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// digestActions are the actions the email digest links to, with the
+// button label the confirmation page shows.
+var digestActions = map[string]string{
+	"read": "Mark as read",
+	"save": "Save for later",
+}
+
+// digestArticle loads the article a digest link points at, writing an error
+// response and returning nil if the link is invalid.
+func (s *Server) digestArticle(w http.ResponseWriter, r *http.Request) *core.Article {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return nil
+	}
+	if _, ok := digestActions[r.PathValue("action")]; !ok {
+		http.Error(w, "Unknown action", http.StatusNotFound)
+		return nil
+	}
+
+	article, err := s.store.GetArticleByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "Article not found", http.StatusNotFound)
+			return nil
+		}
+		s.logger.Error("failed to get article", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil
+	}
+	return article
+}
+
+// handleDigestConfirm is where the email digest links land. Mail scanners
+// and link previews follow links, so it only shows a form that posts the
+// action back.
+func (s *Server) handleDigestConfirm(w http.ResponseWriter, r *http.Request) {
+	article := s.digestArticle(w, r)
+	if article == nil {
+		return
+	}
+
+	data := map[string]any{
+		"Nav":     "daily",
+		"Title":   article.Title,
+		"Article": toArticleView(*article),
+		"Action":  r.PathValue("action"),
+		"Label":   digestActions[r.PathValue("action")],
+	}
+	if err := renderPage(w, "digest", data); err != nil {
+		s.logger.Error("template error", "error", err)
+	}
+}
+
+// handleDigestAction applies a confirmed digest action and redirects to the
+// reader page. Both actions are idempotent: save never unsaves.
+func (s *Server) handleDigestAction(w http.ResponseWriter, r *http.Request) {
+	article := s.digestArticle(w, r)
+	if article == nil {
+		return
+	}
+
+	var err error
+	switch r.PathValue("action") {
+	case "read":
+		if !article.IsRead {
+			err = s.store.MarkArticleRead(r.Context(), article.ID)
+			if err == nil {
+				s.events.Publish(events.ArticleRead, events.ArticleState{ArticleID: article.ID})
+			}
+		}
+	case "save":
+		if !article.ReadLater {
+			_, err = s.store.ToggleArticleSaved(r.Context(), article.ID)
+			if err == nil {
+				s.events.Publish(events.ArticleSaved, events.ArticleState{ArticleID: article.ID})
+			}
+		}
+	}
+	if err != nil {
+		s.logger.Error("failed to apply digest action", "action", r.PathValue("action"), "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/read/"+strconv.FormatInt(article.ID, 10), http.StatusSeeOther)
+}
+
+// handleArticleCard renders one daily-page card, which the page fetches
+// to insert articles announced over the event stream.
+func (s *Server) handleArticleCard(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	article, err := s.store.GetArticleByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, core.ErrNotFound) {
+			http.Error(w, "Article not found", http.StatusNotFound)
+			return
+		}
+		s.logger.Error("failed to get article", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplates["daily"].ExecuteTemplate(w, "article-card", toArticleView(*article)); err != nil {
+		s.logger.Error("template error", "error", err)
+	}
+}
+
+// feedGroup is one category section of the feeds page. Category is nil for
+// uncategorized feeds.
+type feedGroup struct {
+	Category *core.Category
+	Feeds    []core.Feed
+}
+
+func groupFeeds(feeds []core.Feed, categories []core.Category) []feedGroup {
+	groups := make([]feedGroup, len(categories))
+	index := make(map[int64]int, len(categories))
+	for i := range categories {
+		groups[i].Category = &categories[i]
+		index[categories[i].ID] = i
+	}
+
+	var uncategorized []core.Feed
+	for _, f := range feeds {
+		if i, ok := index[f.CategoryID]; ok {
+			groups[i].Feeds = append(groups[i].Feeds, f)
+		} else {
+			uncategorized = append(uncategorized, f)
+		}
+	}
+	if len(uncategorized) > 0 {
+		groups = append(groups, feedGroup{Feeds: uncategorized})
+	}
+	return groups
+}
+
+func (s *Server) handleImportOPMLPage(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("opml")
+	if err != nil {
+		s.renderFeedsPage(w, r, "Choose an OPML file to import", "error")
+		return
+	}
+	defer file.Close()
+
+	result, err := s.importOPML(r.Context(), http.MaxBytesReader(w, file, maxOPMLSize))
+	if err != nil {
+		s.logger.Error("failed to import opml", "error", err)
+		s.renderFeedsPage(w, r, "Failed to import OPML file", "error")
+		return
+	}
+
+	message := fmt.Sprintf("Imported %d feeds", result.Imported)
+	if result.Skipped > 0 {
+		message += fmt.Sprintf(", %d already subscribed", result.Skipped)
+	}
+	if result.Failed > 0 {
+		message += fmt.Sprintf(", %d failed", result.Failed)
+	}
+	s.renderFeedsPage(w, r, message, "success")
+}
+
 func (s *Server) handleFeedsPage(w http.ResponseWriter, r *http.Request) {
 	var message, messageType string
 
 	if r.Method == http.MethodPost {
 		url := strings.TrimSpace(r.FormValue("url"))
 		if url != "" {
-			_, err := s.store.CreateFeed(r.Context(), url, "")
+			feed, err := s.store.CreateFeedWithType(r.Context(), url, "", syncer.DetectSourceType(url))
 			if err != nil {
-				if err == core.ErrConflict {
+				if errors.Is(err, core.ErrConflict) {
 					message = "Feed already exists"
 					messageType = "error"
 				} else {
@@ -160,6 +456,7 @@ func (s *Server) handleFeedsPage(w http.ResponseWriter, r *http.Request) {
 					messageType = "error"
 				}
 			} else {
+				s.publishFeedAdded(feed)
 				message = "Feed added successfully"
 				messageType = "success"
 				go s.syncer.TriggerSync(context.Background())
@@ -167,6 +464,10 @@ func (s *Server) handleFeedsPage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	s.renderFeedsPage(w, r, message, messageType)
+}
+
+func (s *Server) renderFeedsPage(w http.ResponseWriter, r *http.Request, message, messageType string) {
 	feeds, err := s.store.GetAllFeeds(r.Context())
 	if err != nil {
 		s.logger.Error("failed to get feeds", "error", err)
@@ -174,10 +475,19 @@ func (s *Server) handleFeedsPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	categories, err := s.store.GetCategories(r.Context())
+	if err != nil {
+		s.logger.Error("failed to get categories", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	data := map[string]any{
 		"Nav":         "feeds",
 		"Title":       "Feeds",
 		"Feeds":       feeds,
+		"Groups":      groupFeeds(feeds, categories),
+		"Categories":  categories,
 		"Message":     message,
 		"MessageType": messageType,
 	}
@@ -187,24 +497,160 @@ func (s *Server) handleFeedsPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type scoreBucketView struct {
+	core.ScoreBucket
+	Height int // percent of the tallest bucket
+}
+
+func (s *Server) handleFeedPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	feed, err := s.store.GetFeed(r.Context(), id)
+	if err != nil {
+		if err == core.ErrNotFound {
+			http.Error(w, "Feed not found", http.StatusNotFound)
+			return
+		}
+		s.logger.Error("failed to get feed", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	perPage := 20
+
+	stats, err := s.store.GetFeedStats(r.Context(), id)
+	if err != nil {
+		s.logger.Error("failed to get feed stats", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	articles, total, err := s.store.GetArticlesByFeed(r.Context(), id, perPage, (page-1)*perPage)
+	if err != nil {
+		s.logger.Error("failed to get feed articles", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	var views []ArticleView
+	for _, a := range articles {
+		views = append(views, toArticleView(a))
+	}
+
+	tallest := 0
+	for _, b := range stats.Distribution {
+		tallest = max(tallest, b.Count)
+	}
+	buckets := make([]scoreBucketView, len(stats.Distribution))
+	for i, b := range stats.Distribution {
+		buckets[i] = scoreBucketView{ScoreBucket: b}
+		if tallest > 0 {
+			buckets[i].Height = b.Count * 100 / tallest
+		}
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	name := feed.Name
+	if name == "" {
+		name = feed.URL
+	}
+
+	data := map[string]any{
+		"Nav":            "feeds",
+		"Title":          name,
+		"Feed":           feed,
+		"Stats":          stats,
+		"AverageScore":   fmt.Sprintf("%.0f", stats.AverageScore),
+		"AcceptanceRate": fmt.Sprintf("%.0f%%", stats.AcceptanceRate*100),
+		"PerWeek":        fmt.Sprintf("%.1f", stats.ArticlesPerWeek),
+		"Buckets":        buckets,
+		"LastSynced":     formatTime(feed.LastSyncedAt),
+		"LastAttempted":  formatTime(feed.LastAttemptedAt),
+		"LastPublished":  formatTime(stats.LastPublishedAt),
+		"Articles":       views,
+		"Page":           page,
+		"TotalPages":     totalPages,
+		"HasPrev":        page > 1,
+		"HasNext":        page < totalPages,
+		"PrevPage":       page - 1,
+		"NextPage":       page + 1,
+	}
+
+	if err := renderPage(w, "feed", data); err != nil {
+		s.logger.Error("template error", "error", err)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("January 2, 2006 15:04")
+}
+
+func (s *Server) handleSettingsPage(w http.ResponseWriter, r *http.Request) {
+	prefs, err := s.store.GetPreferences(r.Context())
+	if err != nil {
+		s.logger.Error("failed to get preferences", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	tags, err := s.store.GetAllTags(r.Context())
+	if err != nil {
+		s.logger.Error("failed to get tags", "error", err)
+	}
+
+	data := map[string]any{
+		"Nav":         "settings",
+		"Preferences": prefs,
+		"Tags":        tags,
+	}
+	if err := renderPage(w, "settings", data); err != nil {
+		s.logger.Error("template error", "error", err)
+	}
+}
+
 func (s *Server) handleSavedPage(w http.ResponseWriter, r *http.Request) {
-	articles, err := s.store.GetSavedArticles(r.Context())
+	q, err := parseSavedQuery(r.URL.Query())
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.store.ListArticles(r.Context(), q.Filter, q.Cursor, q.Limit)
+	if err != nil {
+		if errors.Is(err, core.ErrBadRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		s.logger.Error("failed to get saved articles", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
 	var views []ArticleView
-	for _, a := range articles {
-		tags, _ := s.store.GetArticleTags(r.Context(), a.ID)
-		views = append(views, toArticleView(a, tags))
+	for _, a := range page.Articles {
+		views = append(views, toArticleView(a))
 	}
 
 	data := map[string]any{
 		"Nav":      "saved",
 		"Title":    "Saved",
 		"Articles": views,
+		"NextURL":  pageURL(r, page.NextCursor),
+		"FirstURL": firstPageURL(r, q.Cursor),
 	}
 
 	if err := renderPage(w, "saved", data); err != nil {
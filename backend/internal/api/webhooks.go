@@ -0,0 +1,239 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/webhook"
+)
+
+type webhookRequest struct {
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret"`
+	Events   []string `json:"events"`
+	MinScore int      `json:"min_score"`
+	Tags     []string `json:"tags"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+func (req webhookRequest) toWebhook() core.Webhook {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return core.Webhook{
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Events:   req.Events,
+		MinScore: req.MinScore,
+		Tags:     req.Tags,
+		Enabled:  enabled,
+	}
+}
+
+// webhookView never echoes the secret back, except once in the response to
+// a create that generated it.
+type webhookView struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	MinScore  int       `json:"min_score"`
+	Tags      []string  `json:"tags"`
+	Enabled   bool      `json:"enabled"`
+	HasSecret bool      `json:"has_secret"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toWebhookView(hook core.Webhook) webhookView {
+	tags := hook.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return webhookView{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    hook.Events,
+		MinScore:  hook.MinScore,
+		Tags:      tags,
+		Enabled:   hook.Enabled,
+		HasSecret: hook.Secret != "",
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+type webhookDeliveryView struct {
+	ID         int64     `json:"id"`
+	EventID    uint64    `json:"event_id"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload"`
+	StatusCode int       `json:"status_code"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (s *Server) handleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := s.store.GetWebhooks(r.Context())
+	if err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+
+	views := make([]webhookView, 0, len(hooks))
+	for _, hook := range hooks {
+		views = append(views, toWebhookView(hook))
+	}
+	JSON(w, http.StatusOK, views)
+}
+
+func (s *Server) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	hook, err := s.store.GetWebhook(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+	JSON(w, http.StatusOK, toWebhookView(hook))
+}
+
+// handleCreateWebhook generates a secret when none is given and returns it
+// this one time.
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	hook := req.toWebhook()
+	generated := hook.Secret == ""
+	if generated {
+		hook.Secret = newWebhookSecret()
+	}
+	if err := webhook.Validate(hook); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hook, err := s.store.CreateWebhook(r.Context(), hook)
+	if err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+
+	view := toWebhookView(hook)
+	if generated {
+		view.Secret = hook.Secret
+	}
+	JSON(w, http.StatusCreated, view)
+}
+
+// handleUpdateWebhook replaces a webhook. The secret is kept unless a new
+// one is given.
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	existing, err := s.store.GetWebhook(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+
+	hook := req.toWebhook()
+	hook.ID = id
+	hook.CreatedAt = existing.CreatedAt
+	if hook.Secret == "" {
+		hook.Secret = existing.Secret
+	}
+	if err := webhook.Validate(hook); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.store.UpdateWebhook(r.Context(), hook); err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+	JSON(w, http.StatusOK, toWebhookView(hook))
+}
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := s.store.DeleteWebhook(r.Context(), id); err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+	JSON(w, http.StatusOK, map[string]string{"message": "webhook deleted"})
+}
+
+func (s *Server) handleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	if _, err := s.store.GetWebhook(r.Context(), id); err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+	deliveries, err := s.store.GetWebhookDeliveries(r.Context(), id, limit)
+	if err != nil {
+		s.fail(w, r, "webhook", err)
+		return
+	}
+
+	views := make([]webhookDeliveryView, 0, len(deliveries))
+	for _, d := range deliveries {
+		views = append(views, webhookDeliveryView{
+			ID:         d.ID,
+			EventID:    d.EventID,
+			Event:      d.Event,
+			Payload:    d.Payload,
+			StatusCode: d.StatusCode,
+			Attempts:   d.Attempts,
+			Error:      d.Error,
+			DurationMS: d.Duration.Milliseconds(),
+			CreatedAt:  d.CreatedAt,
+		})
+	}
+	JSON(w, http.StatusOK, views)
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/webhook"
+	"dailysynapse/backend/pkg/client"
+)
+
+func TestWebhooks(t *testing.T) {
+	s, q := newTestServer(t)
+	ts := httptest.NewServer(s.Routes())
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := &config.Config{WebhookTimeout: time.Second, WebhookMaxAttempts: 2}
+	dispatcher := webhook.NewDispatcher(q, s.events, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	received := make(chan request, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{r.Header, body}
+	}))
+	defer receiver.Close()
+
+	_, err := client.New(ts.URL).CreateWebhook(ctx, client.WebhookRequest{URL: receiver.URL, Events: []string{"article.read"}})
+	if !isCode(err, http.StatusBadRequest, CodeInvalidRequest) {
+		t.Errorf("CreateWebhook() with an unknown event error = %v, want 400 invalid_request", err)
+	}
+
+	c := client.New(ts.URL, client.WithHTTPClient(&http.Client{Transport: specTransport{t: t, doc: loadOpenAPI(t)}}))
+
+	hook, err := c.CreateWebhook(ctx, client.WebhookRequest{URL: receiver.URL, Events: []string{"feed.added"}})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	if len(hook.Secret) != 64 || !hook.HasSecret || !hook.Enabled {
+		t.Fatalf("CreateWebhook() = %+v, want an enabled webhook with a generated secret", hook)
+	}
+
+	got, err := c.GetWebhook(ctx, hook.ID)
+	if err != nil {
+		t.Fatalf("GetWebhook() error = %v", err)
+	}
+	if got.Secret != "" || !got.HasSecret {
+		t.Errorf("GetWebhook() = %+v, want the secret withheld", got)
+	}
+
+	feed, err := c.CreateFeed(ctx, client.CreateFeedRequest{URL: "https://example.com/feed", Name: "Example"})
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	var req request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the webhook")
+	}
+	if err := webhook.Verify(hook.Secret, req.header, req.body, time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	var payload struct {
+		Type string `json:"type"`
+		Data struct {
+			FeedID int64  `json:"feed_id"`
+			URL    string `json:"url"`
+		} `json:"data"`
+	}
+	json.Unmarshal(req.body, &payload)
+	if payload.Type != "feed.added" || payload.Data.FeedID != feed.ID || payload.Data.URL != feed.URL {
+		t.Errorf("payload = %s, want feed.added for feed %d", req.body, feed.ID)
+	}
+
+	var deliveries []client.WebhookDelivery
+	for deadline := time.Now().Add(5 * time.Second); len(deliveries) == 0 && time.Now().Before(deadline); {
+		if deliveries, err = c.WebhookDeliveries(ctx, hook.ID, 10); err != nil {
+			t.Fatalf("WebhookDeliveries() error = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(deliveries) != 1 || deliveries[0].StatusCode != 200 || deliveries[0].Event != "feed.added" {
+		t.Errorf("WebhookDeliveries() = %+v, want one successful feed.added delivery", deliveries)
+	}
+
+	disabled := false
+	updated, err := c.UpdateWebhook(ctx, hook.ID, client.WebhookRequest{
+		URL: receiver.URL, Events: []string{"article.scored"}, MinScore: 80, Tags: []string{"Go"}, Enabled: &disabled,
+	})
+	if err != nil {
+		t.Fatalf("UpdateWebhook() error = %v", err)
+	}
+	if updated.Enabled || updated.MinScore != 80 || !updated.HasSecret {
+		t.Errorf("UpdateWebhook() = %+v, want disabled with min score 80 and the secret kept", updated)
+	}
+	stored, err := q.GetWebhook(ctx, hook.ID)
+	if err != nil || stored.Secret != hook.Secret {
+		t.Errorf("stored secret after update = %q, %v; want it unchanged", stored.Secret, err)
+	}
+
+	if err := c.DeleteWebhook(ctx, hook.ID); err != nil {
+		t.Fatalf("DeleteWebhook() error = %v", err)
+	}
+	if _, err := c.WebhookDeliveries(ctx, hook.ID, 0); !isCode(err, http.StatusNotFound, "webhook_not_found") {
+		t.Errorf("WebhookDeliveries() after delete error = %v, want 404 webhook_not_found", err)
+	}
+}
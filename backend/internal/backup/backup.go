@@ -0,0 +1,172 @@
+// Package backup copies the SQLite database while the app keeps serving,
+// and runs the scheduled job that keeps the newest few copies.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+)
+
+const (
+	filePrefix = "synapse-"
+	fileSuffix = ".db"
+	// fileTime sorts lexically, so the newest backup has the largest name.
+	fileTime = "20060102-150405"
+)
+
+// Backup writes a consistent copy of db to path with VACUUM INTO, which
+// reads in a single transaction and so is safe while other connections
+// write. The copy is written beside path and renamed into place, so path
+// is never a partial file. An existing path is left alone.
+func Backup(ctx context.Context, db *sql.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if _, err := db.ExecContext(ctx, `VACUUM INTO ?`, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("backing up database: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("moving backup into place: %w", err)
+	}
+	return nil
+}
+
+// Job backs the database up into a directory on an interval and prunes
+// all but the newest copies.
+type Job struct {
+	db       *sql.DB
+	logger   *slog.Logger
+	dir      string
+	interval time.Duration
+	keep     int
+}
+
+// New creates the scheduled job. Callers should only create one when
+// BackupDir is set.
+func New(db *sql.DB, cfg *config.Config, logger *slog.Logger) *Job {
+	return &Job{
+		db:       db,
+		logger:   logger,
+		dir:      cfg.BackupDir,
+		interval: cfg.BackupInterval,
+		keep:     max(cfg.BackupKeep, 1),
+	}
+}
+
+// Run backs up whenever the newest copy is an interval old, starting
+// straight away if there is none, until ctx is cancelled.
+func (j *Job) Run(ctx context.Context) {
+	for {
+		var wait time.Duration
+		if last, ok := j.latest(); ok {
+			wait = time.Until(last.Add(j.interval))
+		}
+
+		if wait > 0 {
+			j.logger.Info("next backup scheduled", "at", time.Now().Add(wait).Round(time.Second))
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		path, err := j.Backup(ctx, time.Now())
+		if err != nil {
+			j.logger.Error("scheduled backup failed", "error", err)
+			// Try again after a full interval rather than in a tight loop.
+			timer := time.NewTimer(j.interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		j.logger.Info("database backed up", "path", path)
+	}
+}
+
+// Backup writes one timestamped copy and prunes old ones.
+func (j *Job) Backup(ctx context.Context, now time.Time) (string, error) {
+	path := filepath.Join(j.dir, filePrefix+now.UTC().Format(fileTime)+fileSuffix)
+	if err := Backup(ctx, j.db, path); err != nil {
+		return "", err
+	}
+	if err := j.prune(); err != nil {
+		j.logger.Warn("failed to prune old backups", "error", err)
+	}
+	return path, nil
+}
+
+// backups lists the job's copies, oldest first. Other files in the
+// directory are ignored.
+func (j *Job) backups() ([]string, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		stamp, ok := strings.CutPrefix(name, filePrefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if stamp, ok = strings.CutSuffix(stamp, fileSuffix); !ok {
+			continue
+		}
+		if _, err := time.Parse(fileTime, stamp); err == nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (j *Job) latest() (time.Time, bool) {
+	names, err := j.backups()
+	if err != nil || len(names) == 0 {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(names[len(names)-1], filePrefix), fileSuffix)
+	t, err := time.Parse(fileTime, stamp)
+	return t, err == nil
+}
+
+func (j *Job) prune() error {
+	names, err := j.backups()
+	if err != nil {
+		return err
+	}
+	for len(names) > j.keep {
+		if err := os.Remove(filepath.Join(j.dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
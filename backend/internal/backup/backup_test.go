@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, filepath.Join(dir, "synapse.db"))
+	ctx := context.Background()
+	if _, err := db.Exec(`PRAGMA journal_mode=WAL; CREATE TABLE feeds (id INTEGER PRIMARY KEY, url TEXT); INSERT INTO feeds (url) VALUES ('https://example.com')`); err != nil {
+		t.Fatalf("creating database: %v", err)
+	}
+
+	path := filepath.Join(dir, "backups", "copy.db")
+	if err := Backup(ctx, db, path); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	var url string
+	if err := openDB(t, path).QueryRow(`SELECT url FROM feeds`).Scan(&url); err != nil || url != "https://example.com" {
+		t.Errorf("backup holds %q, %v; want the feed", url, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary file was left behind")
+	}
+
+	if err := Backup(ctx, db, path); err == nil {
+		t.Error("Backup() over an existing file succeeded, want an error")
+	}
+}
+
+func TestJob_Prunes(t *testing.T) {
+	dir := t.TempDir()
+	db := openDB(t, filepath.Join(dir, "synapse.db"))
+	if _, err := db.Exec(`CREATE TABLE feeds (id INTEGER PRIMARY KEY)`); err != nil {
+		t.Fatalf("creating database: %v", err)
+	}
+	backups := filepath.Join(dir, "backups")
+	os.MkdirAll(backups, 0o755)
+	os.WriteFile(filepath.Join(backups, "notes.txt"), nil, 0o644)
+
+	cfg := &config.Config{BackupDir: backups, BackupInterval: time.Hour, BackupKeep: 2}
+	j := New(db, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if _, ok := j.latest(); ok {
+		t.Error("latest() found a backup in an empty directory")
+	}
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		if _, err := j.Backup(context.Background(), start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+	}
+
+	entries, _ := os.ReadDir(backups)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	want := []string{"notes.txt", "synapse-20260301-030000.db", "synapse-20260301-040000.db"}
+	if !slices.Equal(names, want) {
+		t.Errorf("backup directory = %v, want %v", names, want)
+	}
+	if last, ok := j.latest(); !ok || !last.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("latest() = %v, %v; want %v", last, ok, start.Add(2*time.Hour))
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,8 +12,10 @@ import (
 type Config struct {
 	DatabaseURL  string
 	GeminiAPIKey string
+	SecretKey    string
 	Port         string
 	LogLevel     string
+	PublicURL    string
 
 	SyncInterval       time.Duration
 	SyncBatchSize      int
@@ -20,9 +23,39 @@ type Config struct {
 	ArticleHorizonDays int
 	RetentionDays      int
 	HTTPTimeout        time.Duration
+	DeleteGracePeriod  time.Duration
 
 	JudgeInterval    time.Duration
 	MaxContentLength int
+
+	SMTPAddr             string
+	SMTPDomain           string
+	NewsletterRecipients []string
+	IMAPAddr             string
+	IMAPUsername         string
+	IMAPPassword         string
+	IMAPMailbox          string
+	IMAPTLS              bool
+	IMAPPollInterval     time.Duration
+
+	WebhookTimeout     time.Duration
+	WebhookMaxAttempts int
+
+	DigestSMTPAddr     string
+	DigestSMTPUsername string
+	DigestSMTPPassword string
+	DigestFrom         string
+	DigestRecipients   []string
+	DigestTime         string
+	DigestTimezone     string
+	DigestMinScore     int
+	DigestLimit        int
+
+	NotifyTimeout time.Duration
+
+	BackupDir      string
+	BackupInterval time.Duration
+	BackupKeep     int
 }
 
 func Load() *Config {
@@ -36,8 +69,10 @@ func Load() *Config {
 	return &Config{
 		DatabaseURL:  getEnv("DATABASE_URL", "synapse.db"),
 		GeminiAPIKey: getEnv("GEMINI_API_KEY", ""),
+		SecretKey:    getEnv("SECRET_KEY", ""),
 		Port:         getEnv("PORT", "8080"),
 		LogLevel:     getEnv("LOG_LEVEL", "info"),
+		PublicURL:    getEnv("PUBLIC_URL", ""),
 
 		SyncInterval:       getDurationEnv("SYNC_INTERVAL", 15*time.Minute),
 		SyncBatchSize:      getIntEnv("SYNC_BATCH_SIZE", 20),
@@ -45,12 +80,51 @@ func Load() *Config {
 		ArticleHorizonDays: getIntEnv("ARTICLE_HORIZON_DAYS", 120),
 		RetentionDays:      getIntEnv("RETENTION_DAYS", 30),
 		HTTPTimeout:        getDurationEnv("HTTP_TIMEOUT", 10*time.Second),
+		DeleteGracePeriod:  getDurationEnv("DELETE_GRACE_PERIOD", 24*time.Hour),
 
 		JudgeInterval:    getDurationEnv("JUDGE_INTERVAL", 6*time.Second),
 		MaxContentLength: getIntEnv("MAX_CONTENT_LENGTH", 20000),
+
+		SMTPAddr:             getEnv("SMTP_ADDR", ""),
+		SMTPDomain:           getEnv("SMTP_DOMAIN", "localhost"),
+		NewsletterRecipients: getListEnv("NEWSLETTER_RECIPIENTS"),
+		IMAPAddr:             getEnv("IMAP_ADDR", ""),
+		IMAPUsername:         getEnv("IMAP_USERNAME", ""),
+		IMAPPassword:         getEnv("IMAP_PASSWORD", ""),
+		IMAPMailbox:          getEnv("IMAP_MAILBOX", "INBOX"),
+		IMAPTLS:              getBoolEnv("IMAP_TLS", true),
+		IMAPPollInterval:     getDurationEnv("IMAP_POLL_INTERVAL", 5*time.Minute),
+
+		WebhookTimeout:     getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookMaxAttempts: getIntEnv("WEBHOOK_MAX_ATTEMPTS", 5),
+
+		DigestSMTPAddr:     getEnv("DIGEST_SMTP_ADDR", ""),
+		DigestSMTPUsername: getEnv("DIGEST_SMTP_USERNAME", ""),
+		DigestSMTPPassword: getEnv("DIGEST_SMTP_PASSWORD", ""),
+		DigestFrom:         getEnv("DIGEST_FROM", "The Daily Synapse <synapse@localhost>"),
+		DigestRecipients:   getListEnv("DIGEST_RECIPIENTS"),
+		DigestTime:         getEnv("DIGEST_TIME", "07:00"),
+		DigestTimezone:     getEnv("DIGEST_TIMEZONE", "Local"),
+		DigestMinScore:     getIntEnv("DIGEST_MIN_SCORE", 70),
+		DigestLimit:        getIntEnv("DIGEST_LIMIT", 10),
+
+		NotifyTimeout: getDurationEnv("NOTIFY_TIMEOUT", 10*time.Second),
+
+		BackupDir:      getEnv("BACKUP_DIR", ""),
+		BackupInterval: getDurationEnv("BACKUP_INTERVAL", 24*time.Hour),
+		BackupKeep:     getIntEnv("BACKUP_KEEP", 7),
 	}
 }
 
+// BaseURL is where users reach the app, for links in emails and chat
+// messages. It has no trailing slash.
+func (c *Config) BaseURL() string {
+	if c.PublicURL != "" {
+		return strings.TrimRight(c.PublicURL, "/")
+	}
+	return "http://localhost:" + c.Port
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -67,6 +141,25 @@ func getIntEnv(key string, fallback int) int {
 	return fallback
 }
 
+func getBoolEnv(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+func getListEnv(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func getDurationEnv(key string, fallback time.Duration) time.Duration {
 	if value, exists := os.LookupEnv(key); exists {
 		if d, err := time.ParseDuration(value); err == nil {
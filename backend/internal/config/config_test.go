@@ -42,6 +42,24 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.MaxContentLength != 20000 {
 		t.Errorf("MaxContentLength = %v, want 20000", cfg.MaxContentLength)
 	}
+	if cfg.DeleteGracePeriod != 24*time.Hour {
+		t.Errorf("DeleteGracePeriod = %v, want 24h", cfg.DeleteGracePeriod)
+	}
+	if cfg.WebhookMaxAttempts != 5 {
+		t.Errorf("WebhookMaxAttempts = %v, want 5", cfg.WebhookMaxAttempts)
+	}
+	if cfg.DigestTime != "07:00" || cfg.DigestMinScore != 70 || cfg.DigestLimit != 10 {
+		t.Errorf("Digest defaults = %v, %v, %v; want 07:00, 70, 10", cfg.DigestTime, cfg.DigestMinScore, cfg.DigestLimit)
+	}
+	if cfg.NotifyTimeout != 10*time.Second {
+		t.Errorf("NotifyTimeout = %v, want 10s", cfg.NotifyTimeout)
+	}
+	if cfg.BackupDir != "" || cfg.BackupInterval != 24*time.Hour || cfg.BackupKeep != 7 {
+		t.Errorf("Backup defaults = %q, %v, %v; want disabled, 24h, 7", cfg.BackupDir, cfg.BackupInterval, cfg.BackupKeep)
+	}
+	if cfg.BaseURL() != "http://localhost:8080" {
+		t.Errorf("BaseURL() = %q, want http://localhost:8080", cfg.BaseURL())
+	}
 }
 
 func TestLoad_FromEnv(t *testing.T) {
@@ -117,3 +135,23 @@ func TestGetDurationEnv_InvalidValue(t *testing.T) {
 	}
 }
 
+func TestGetBoolEnv_InvalidValue(t *testing.T) {
+	os.Setenv("IMAP_TLS", "maybe")
+	defer os.Unsetenv("IMAP_TLS")
+
+	cfg := Load()
+	// Should fall back to default
+	if !cfg.IMAPTLS {
+		t.Errorf("IMAPTLS = %v, want true (default)", cfg.IMAPTLS)
+	}
+}
+
+func TestGetListEnv(t *testing.T) {
+	os.Setenv("NEWSLETTER_RECIPIENTS", " news@example.com, ,digest@example.com ")
+	defer os.Unsetenv("NEWSLETTER_RECIPIENTS")
+
+	cfg := Load()
+	if len(cfg.NewsletterRecipients) != 2 || cfg.NewsletterRecipients[1] != "digest@example.com" {
+		t.Errorf("NewsletterRecipients = %q, want [news@example.com digest@example.com]", cfg.NewsletterRecipients)
+	}
+}
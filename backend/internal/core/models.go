@@ -6,10 +6,139 @@ type Feed struct {
 	ID           int64
 	URL          string
 	Name         string
+	Type         string
 	Status       string
 	Etag         string
 	LastModified string
 	LastSyncedAt time.Time
+
+	// LastAttemptedAt and LastSyncError describe the most recent sync,
+	// successful or not. LastSyncError is empty after a success.
+	LastAttemptedAt time.Time
+	LastSyncError   string
+
+	CategoryID int64 // 0 when uncategorized
+}
+
+// Category groups feeds. Articles from its feeds scoring below MinScore are
+// left out of the daily ranking.
+type Category struct {
+	ID        int64
+	Name      string
+	MinScore  int
+	FeedCount int
+	CreatedAt time.Time
+}
+
+// ArticleSort orders an article list.
+type ArticleSort string
+
+const (
+	// SortPersonalized puts unread articles first, then ranks by score.
+	SortPersonalized ArticleSort = "personalized"
+	SortScore        ArticleSort = "score"
+	SortDate         ArticleSort = "date"
+)
+
+// ArticleFilter narrows an article list. Zero values match everything.
+type ArticleFilter struct {
+	IDs        []int64
+	FeedID     int64
+	CategoryID int64
+
+	// Tags matches articles carrying any of the tags, or all of them
+	// when MatchAllTags is set. Articles must also carry every one of
+	// RequireTags and none of ExcludeTags.
+	Tags         []string
+	MatchAllTags bool
+	RequireTags  []string
+	ExcludeTags  []string
+
+	MinScore, MaxScore int // MaxScore 0 means no upper bound
+	Since, Until       time.Time
+	ScoredSince        time.Time // when the judge or a rule scored it
+
+	Read  *bool
+	Saved *bool
+
+	// HideMuted leaves out articles carrying a muted tag unless they reach
+	// the mute's override score or the filter names the tag. The daily
+	// lists set it.
+	HideMuted bool
+
+	// AllScores also lists unscored articles and those below their
+	// category's threshold. The saved list uses it.
+	AllScores bool
+
+	Sort ArticleSort // defaults to SortPersonalized
+}
+
+// BulkAction is a change applied to every article an ArticleFilter matches.
+type BulkAction string
+
+const (
+	BulkRead    BulkAction = "read"
+	BulkUnread  BulkAction = "unread"
+	BulkSave    BulkAction = "save"
+	BulkUnsave  BulkAction = "unsave"
+	BulkDismiss BulkAction = "dismiss"
+	BulkTag     BulkAction = "tag"
+)
+
+// ArticlePage is one page of an article list. NextCursor is empty on the
+// last page.
+type ArticlePage struct {
+	Articles   []Article
+	NextCursor string
+}
+
+// FeedPatch lists the changes to make to a feed; nil fields are left as is.
+type FeedPatch struct {
+	Name       *string
+	URL        *string
+	Paused     *bool
+	CategoryID *int64 // 0 clears the category
+}
+
+// FeedStats summarises how a feed's articles fare with the judge.
+// Rejected articles are deleted, so they are counted from a separate log.
+type FeedStats struct {
+	FeedID          int64
+	ArticleCount    int
+	ScoredCount     int
+	UnscoredCount   int
+	RejectedCount   int
+	AverageScore    float64
+	AcceptanceRate  float64 // kept / (kept + rejected), 0 when nothing was judged
+	ArticlesPerWeek float64 // over the last 30 days
+	LastPublishedAt time.Time
+	Distribution    []ScoreBucket
+	TopTags         []TagCount
+}
+
+// ScoreBucket counts judged articles scoring within [Min, Max].
+type ScoreBucket struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+// FeedSettings customises how a feed and its articles are fetched.
+// Password, Headers and ProxyURL may carry credentials and are encrypted at rest.
+type FeedSettings struct {
+	FeedID                int64
+	Headers               map[string]string
+	Username              string
+	Password              string
+	UserAgent             string
+	ProxyURL              string
+	TLSInsecureSkipVerify bool
+	TLSServerName         string
+}
+
+func (s FeedSettings) IsZero() bool {
+	return len(s.Headers) == 0 && s.Username == "" && s.Password == "" &&
+		s.UserAgent == "" && s.ProxyURL == "" && !s.TLSInsecureSkipVerify && s.TLSServerName == ""
 }
 
 type Article struct {
@@ -26,6 +155,24 @@ type Article struct {
 	JudgeModel    string
 	IsRead        bool
 	ReadLater     bool
+	GUID          string
+	UpdatedAt     time.Time
+	ImageURL      string
+	Categories    []string
+	Authors       []Author
+	Enclosures    []Enclosure
+	Tags          []string
+}
+
+type Author struct {
+	Name  string
+	Email string
+}
+
+type Enclosure struct {
+	URL      string
+	MIMEType string
+	Length   int64
 }
 
 type Tag struct {
@@ -38,7 +185,94 @@ type TagCount struct {
 	Count int
 }
 
+// Preferences are the reader's standing choices about the daily list:
+// articles with a followed tag rank higher and ones with a muted tag are
+// hidden.
+type Preferences struct {
+	FollowedTags []string
+	MutedTags    []MutedTag
+}
+
+// MutedTag hides articles carrying Tag. Articles scoring at least
+// OverrideScore still show; 0 hides them all.
+type MutedTag struct {
+	Tag           string
+	OverrideScore int
+}
+
 type ArticleTag struct {
 	ArticleID int64
 	TagID     int64
 }
+
+// IngestRule filters or annotates feed items before they reach the judge.
+// A FeedID of 0 makes the rule global.
+type IngestRule struct {
+	ID            int64
+	FeedID        int64
+	Field         string
+	MatchType     string
+	Pattern       string
+	Action        string
+	Tags          []string
+	Score         int
+	Enabled       bool
+	MatchCount    int
+	LastMatchedAt time.Time
+	CreatedAt     time.Time
+}
+
+// Webhook posts matching events to URL, signed with Secret. MinScore and
+// Tags narrow article events; feed events are always sent.
+type Webhook struct {
+	ID        int64
+	URL       string
+	Secret    string
+	Events    []string
+	MinScore  int
+	Tags      []string
+	Enabled   bool
+	CreatedAt time.Time
+}
+
+// WebhookDelivery records one event sent to a webhook, after all retries.
+// StatusCode is 0 when no response was received.
+type WebhookDelivery struct {
+	ID         int64
+	WebhookID  int64
+	EventID    uint64
+	Event      string
+	Payload    string
+	StatusCode int
+	Attempts   int
+	Error      string
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// Notification channel types.
+const (
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+	ChannelMatrix  = "matrix"
+)
+
+// NotificationChannel pings a chat room when an article scoring at least
+// MinScore arrives. URL is the incoming-webhook URL for Slack and Discord
+// and the homeserver for Matrix, which also needs RoomID and an access
+// Token. Tags and FeedIDs, when set, each require a match. RateLimit caps
+// messages per hour.
+type NotificationChannel struct {
+	ID        int64
+	Name      string
+	Type      string
+	URL       string
+	RoomID    string
+	Token     string
+	MinScore  int
+	Tags      []string
+	FeedIDs   []int64
+	RateLimit int
+	Enabled   bool
+	CreatedAt time.Time
+}
@@ -0,0 +1,326 @@
+// Package digest emails a daily summary of the best unread articles scored
+// in the last 24 hours. Each entry links back to the app so it can be
+// marked read or saved in one click.
+package digest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/retry"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/digest.html"))
+	textTemplate = template.Must(template.New("digest.txt").Funcs(template.FuncMap{
+		"inc":  func(i int) int { return i + 1 },
+		"join": strings.Join,
+		"wrap": func(s string) string { return wrap(s, 72, "   ") },
+	}).ParseFS(templateFS, "templates/digest.txt"))
+)
+
+// window is how far back the digest looks for scored articles.
+const window = 24 * time.Hour
+
+type Store interface {
+	ListArticles(ctx context.Context, filter core.ArticleFilter, cursor string, limit int) (core.ArticlePage, error)
+}
+
+// SendFunc delivers a message; smtp.SendMail by default.
+type SendFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+type Digest struct {
+	store  Store
+	logger *slog.Logger
+	send   SendFunc
+
+	addr       string
+	auth       smtp.Auth
+	from       *mail.Address
+	recipients []string
+	hour, min  int
+	location   *time.Location
+	minScore   int
+	limit      int
+	baseURL    string
+}
+
+// New validates the digest settings in cfg. Callers should only create a
+// Digest when DigestSMTPAddr and DigestRecipients are set.
+func New(store Store, cfg *config.Config, logger *slog.Logger) (*Digest, error) {
+	hour, min, err := parseClock(cfg.DigestTime)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(cfg.DigestTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DIGEST_TIMEZONE %q: %w", cfg.DigestTimezone, err)
+	}
+	from, err := mail.ParseAddress(cfg.DigestFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DIGEST_FROM %q: %w", cfg.DigestFrom, err)
+	}
+	if len(cfg.DigestRecipients) == 0 {
+		return nil, fmt.Errorf("DIGEST_RECIPIENTS is empty")
+	}
+	for _, r := range cfg.DigestRecipients {
+		if _, err := mail.ParseAddress(r); err != nil {
+			return nil, fmt.Errorf("invalid digest recipient %q: %w", r, err)
+		}
+	}
+
+	var auth smtp.Auth
+	if cfg.DigestSMTPUsername != "" {
+		host, _, err := net.SplitHostPort(cfg.DigestSMTPAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DIGEST_SMTP_ADDR %q: %w", cfg.DigestSMTPAddr, err)
+		}
+		auth = smtp.PlainAuth("", cfg.DigestSMTPUsername, cfg.DigestSMTPPassword, host)
+	}
+
+	return &Digest{
+		store:      store,
+		logger:     logger,
+		send:       smtp.SendMail,
+		addr:       cfg.DigestSMTPAddr,
+		auth:       auth,
+		from:       from,
+		recipients: cfg.DigestRecipients,
+		hour:       hour,
+		min:        min,
+		location:   location,
+		minScore:   cfg.DigestMinScore,
+		limit:      max(cfg.DigestLimit, 1),
+		baseURL:    cfg.BaseURL(),
+	}, nil
+}
+
+func parseClock(s string) (hour, min int, err error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid DIGEST_TIME %q, want HH:MM", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// Run sends a digest at the configured time every day until ctx is
+// cancelled.
+func (d *Digest) Run(ctx context.Context) {
+	for {
+		next := d.Next(time.Now())
+		d.logger.Info("next digest scheduled", "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		n, err := d.Send(ctx, time.Now())
+		if err != nil {
+			d.logger.Error("failed to send digest", "error", err)
+			continue
+		}
+		d.logger.Info("digest sent", "articles", n, "recipients", len(d.recipients))
+	}
+}
+
+// Next returns the first scheduled time strictly after now. It is computed
+// from the wall clock in the digest's timezone, so it follows DST changes.
+func (d *Digest) Next(now time.Time) time.Time {
+	local := now.In(d.location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), d.hour, d.min, 0, 0, d.location)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, d.hour, d.min, 0, 0, d.location)
+	}
+	return next
+}
+
+// Item is one article in a digest.
+type Item struct {
+	ID        int64
+	Title     string
+	URL       string
+	FeedName  string
+	Summary   string
+	Score     int
+	Tags      []string
+	ReaderURL string
+	ReadURL   string
+	SaveURL   string
+}
+
+// Items selects the top unread articles scored in the 24 hours before now,
+// leaving out those with a muted tag.
+func (d *Digest) Items(ctx context.Context, now time.Time) ([]Item, error) {
+	unread := false
+	filter := core.ArticleFilter{
+		Read:        &unread,
+		MinScore:    d.minScore,
+		ScoredSince: now.Add(-window),
+		HideMuted:   true,
+		Sort:        core.SortScore,
+	}
+	page, err := d.store.ListArticles(ctx, filter, "", d.limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing articles: %w", err)
+	}
+
+	items := make([]Item, 0, len(page.Articles))
+	for _, a := range page.Articles {
+		id := strconv.FormatInt(a.ID, 10)
+		items = append(items, Item{
+			ID:        a.ID,
+			Title:     a.Title,
+			URL:       a.URL,
+			FeedName:  a.FeedName,
+			Summary:   a.Summary,
+			Score:     a.QualityRank,
+			Tags:      a.Tags,
+			ReaderURL: d.baseURL + "/read/" + id,
+			ReadURL:   d.baseURL + "/digest/" + id + "/read",
+			SaveURL:   d.baseURL + "/digest/" + id + "/save",
+		})
+	}
+	return items, nil
+}
+
+// Send emails the digest for now and returns how many articles it listed.
+// Nothing is sent when there are no articles.
+func (d *Digest) Send(ctx context.Context, now time.Time) (int, error) {
+	items, err := d.Items(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	msg, err := d.Compose(now, items)
+	if err != nil {
+		return 0, err
+	}
+	err = retry.Do(ctx, 3, func() error {
+		return d.send(d.addr, d.auth, d.from.Address, d.recipients, msg)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("sending digest: %w", err)
+	}
+	return len(items), nil
+}
+
+// Compose renders items as a multipart/alternative message with a plain
+// text and an HTML part.
+func (d *Digest) Compose(now time.Time, items []Item) ([]byte, error) {
+	date := now.In(d.location).Format("Monday, January 2")
+	data := map[string]any{
+		"Date":     date,
+		"Items":    items,
+		"MinScore": d.minScore,
+		"BaseURL":  d.baseURL,
+	}
+
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("rendering text digest: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("rendering html digest: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating mime part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		qp.Write(part.content)
+		qp.Close()
+	}
+	parts.Close()
+
+	subject := fmt.Sprintf("The Daily Synapse: %d %s for %s", len(items), plural(len(items), "article", "articles"), date)
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", d.from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(d.recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: %s\r\n", messageID(d.from.Address))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "<digest." + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// wrap breaks s into lines of at most width characters, each starting
+// with indent.
+func wrap(s string, width int, indent string) string {
+	var lines []string
+	line := indent
+	for _, word := range strings.Fields(s) {
+		if line != indent && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = indent
+		}
+		if line != indent {
+			line += " "
+		}
+		line += word
+	}
+	if line != indent {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,219 @@
+package digest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/newsletter"
+)
+
+type fakeStore struct {
+	articles []core.Article
+
+	filter core.ArticleFilter
+	limit  int
+}
+
+func (f *fakeStore) ListArticles(ctx context.Context, filter core.ArticleFilter, cursor string, limit int) (core.ArticlePage, error) {
+	f.filter, f.limit = filter, limit
+	return core.ArticlePage{Articles: f.articles}, nil
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Port:             "8080",
+		PublicURL:        "https://synapse.example.com/",
+		DigestSMTPAddr:   "127.0.0.1:25",
+		DigestFrom:       "The Daily Synapse <digest@example.com>",
+		DigestRecipients: []string{"me@example.com"},
+		DigestTime:       "07:30",
+		DigestTimezone:   "Europe/Berlin",
+		DigestMinScore:   70,
+		DigestLimit:      5,
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*config.Config)
+	}{
+		{"bad time", func(c *config.Config) { c.DigestTime = "7am" }},
+		{"bad timezone", func(c *config.Config) { c.DigestTimezone = "Mars/Olympus" }},
+		{"bad from", func(c *config.Config) { c.DigestFrom = "not an address" }},
+		{"no recipients", func(c *config.Config) { c.DigestRecipients = nil }},
+		{"bad recipient", func(c *config.Config) { c.DigestRecipients = []string{"nobody"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(cfg)
+			if _, err := New(&fakeStore{}, cfg, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+				t.Error("New() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	d, err := New(&fakeStore{}, testConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before today's run", time.Date(2026, 3, 10, 6, 0, 0, 0, berlin), time.Date(2026, 3, 10, 7, 30, 0, 0, berlin)},
+		{"at the run", time.Date(2026, 3, 10, 7, 30, 0, 0, berlin), time.Date(2026, 3, 11, 7, 30, 0, 0, berlin)},
+		{"after today's run", time.Date(2026, 3, 10, 20, 0, 0, 0, berlin), time.Date(2026, 3, 11, 7, 30, 0, 0, berlin)},
+		{"other timezone", time.Date(2026, 3, 10, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 7, 30, 0, 0, berlin)},
+		{"across DST", time.Date(2026, 3, 28, 12, 0, 0, 0, berlin), time.Date(2026, 3, 29, 7, 30, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	received := make(chan []byte, 1)
+	server := newsletter.NewSMTPServer("", "example.com", []string{"me@example.com"}, func(ctx context.Context, raw []byte) error {
+		received <- raw
+		return nil
+	}, logger)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	go server.Serve(ctx, ln)
+
+	store := &fakeStore{
+		articles: []core.Article{
+			{ID: 7, Title: "Go 1.30 & friends", URL: "https://go.dev/blog", FeedName: "Go Blog", Summary: "What's new in Go.", QualityRank: 92, Tags: []string{"Go", "Release"}},
+			{ID: 9, Title: "SQLite internals", URL: "https://sqlite.org/arch", FeedName: "SQLite", QualityRank: 75},
+		},
+	}
+	cfg := testConfig()
+	cfg.DigestSMTPAddr = ln.Addr().String()
+	d, err := New(store, cfg, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	now := time.Date(2026, 3, 10, 7, 30, 0, 0, time.UTC)
+	n, err := d.Send(ctx, now)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Send() = %d, want 2", n)
+	}
+
+	if store.limit != 5 {
+		t.Errorf("limit = %d, want 5", store.limit)
+	}
+	f := store.filter
+	if f.Read == nil || *f.Read || f.MinScore != 70 || f.Sort != core.SortScore || !f.ScoredSince.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("filter = %+v, want unread, min score 70, scored since %v, by score", f, now.Add(-24*time.Hour))
+	}
+
+	var raw []byte
+	select {
+	case raw = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the digest")
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if !strings.Contains(subject, "2 articles") {
+		t.Errorf("Subject = %q, want the article count", subject)
+	}
+	if msg.Header.Get("To") != "me@example.com" {
+		t.Errorf("To = %q, want me@example.com", msg.Header.Get("To"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+	parts := map[string]string{}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		body, _ := io.ReadAll(p) // quoted-printable is decoded by NextPart
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+
+	for _, contentType := range []string{"text/plain", "text/html"} {
+		body, ok := parts[contentType]
+		if !ok {
+			t.Errorf("no %s part", contentType)
+			continue
+		}
+		for _, want := range []string{
+			"SQLite internals",
+			"What&#39;s new in Go.",
+			"https://synapse.example.com/digest/7/read",
+			"https://synapse.example.com/digest/9/save",
+			"Release",
+			"92",
+		} {
+			if contentType == "text/plain" {
+				want = strings.ReplaceAll(want, "&#39;", "'")
+			}
+			if !strings.Contains(body, want) {
+				t.Errorf("%s part does not contain %q:\n%s", contentType, want, body)
+			}
+		}
+	}
+	if !strings.Contains(parts["text/html"], "Go 1.30 &amp; friends") {
+		t.Errorf("text/html part does not escape the title:\n%s", parts["text/html"])
+	}
+}
+
+func TestSend_NoArticles(t *testing.T) {
+	d, err := New(&fakeStore{}, testConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	d.send = func(string, smtp.Auth, string, []string, []byte) error {
+		t.Error("send called with no articles")
+		return nil
+	}
+	if n, err := d.Send(context.Background(), time.Now()); n != 0 || err != nil {
+		t.Errorf("Send() = %d, %v; want 0, nil", n, err)
+	}
+}
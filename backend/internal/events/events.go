@@ -0,0 +1,201 @@
+// Package events is an in-process publish/subscribe bus. The judge, the
+// syncer and the API handlers publish what happened; the SSE endpoint and
+// the webhook dispatcher subscribe and forward it.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	ArticleScored    Type = "article.scored"
+	ArticleRead      Type = "article.read"
+	ArticleUnread    Type = "article.unread"
+	ArticleSaved     Type = "article.saved"
+	ArticleUnsaved   Type = "article.unsaved"
+	ArticleDismissed Type = "article.dismissed"
+	ArticleRestored  Type = "article.restored"
+	ArticlesBulk     Type = "articles.bulk"
+	FeedSynced       Type = "feed.synced"
+	FeedFailed       Type = "feed.failed"
+	FeedAdded        Type = "feed.added"
+)
+
+// Event is one published change. IDs increase by one per event and restart
+// with the process.
+type Event struct {
+	ID   uint64    `json:"id"`
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// ArticleScore is the payload of ArticleScored.
+type ArticleScore struct {
+	ArticleID int64    `json:"article_id"`
+	FeedID    int64    `json:"feed_id"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url"`
+	Score     int      `json:"score"`
+	Summary   string   `json:"summary"`
+	Tags      []string `json:"tags"`
+}
+
+// ArticleState is the payload of the events that change one article's
+// read, saved or dismissed state.
+type ArticleState struct {
+	ArticleID int64 `json:"article_id"`
+}
+
+// BulkChange is the payload of ArticlesBulk. IDs lists the articles that
+// changed.
+type BulkChange struct {
+	Action   string  `json:"action"`
+	IDs      []int64 `json:"ids,omitempty"`
+	Affected int64   `json:"affected"`
+}
+
+// FeedSync is the payload of FeedSynced and FeedFailed.
+type FeedSync struct {
+	FeedID      int64  `json:"feed_id"`
+	Name        string `json:"name"`
+	NewArticles int    `json:"new_articles"`
+	Error       string `json:"error,omitempty"`
+}
+
+// FeedInfo is the payload of FeedAdded.
+type FeedInfo struct {
+	FeedID int64  `json:"feed_id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Type   string `json:"type"`
+}
+
+const (
+	// historySize is how many events are kept for subscribers that
+	// reconnect and ask for what they missed.
+	historySize = 256
+	// subscriberBuffer is how far a subscriber may fall behind before it
+	// is dropped.
+	subscriberBuffer = 64
+)
+
+// Bus fans events out to subscribers. A nil *Bus discards everything
+// published to it, so components work without one.
+type Bus struct {
+	mu      sync.Mutex
+	nextID  uint64
+	history []Event
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Publish never blocks: a subscriber whose buffer is full is dropped and
+// its channel closed, and it is expected to resubscribe with the last ID
+// it saw.
+func (b *Bus) Publish(typ Type, data any) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	ev := Event{ID: b.nextID, Type: typ, Time: time.Now(), Data: data}
+	b.history = append(b.history, ev)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.c <- ev:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscription receives events on C until it is closed, dropped for
+// falling behind, or the bus shuts down.
+type Subscription struct {
+	C <-chan Event
+
+	c   chan Event
+	bus *Bus
+}
+
+// Subscribe starts receiving events. With after > 0 it first replays the
+// retained events newer than after. On a nil *Bus the subscription is
+// already closed.
+func (b *Bus) Subscribe(after uint64) *Subscription {
+	if b == nil {
+		c := make(chan Event)
+		close(c)
+		return &Subscription{C: c, c: c}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	if after > 0 {
+		for _, ev := range b.history {
+			if ev.ID > after {
+				missed = append(missed, ev)
+			}
+		}
+	}
+
+	c := make(chan Event, len(missed)+subscriberBuffer)
+	for _, ev := range missed {
+		c <- ev
+	}
+	sub := &Subscription{C: c, c: c, bus: b}
+	if b.closed {
+		close(c)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	if s.bus == nil {
+		return
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+// Close ends every subscription, so long-lived readers such as SSE
+// streams return when the server shuts down.
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+func (b *Bus) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
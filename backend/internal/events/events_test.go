@@ -0,0 +1,111 @@
+package events
+
+import (
+	"testing"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription closed, want an event")
+		}
+		return ev
+	default:
+		t.Fatal("no event received")
+	}
+	return Event{}
+}
+
+func TestBus_PublishSubscribe(t *testing.T) {
+	bus := New()
+	a := bus.Subscribe(0)
+	b := bus.Subscribe(0)
+
+	bus.Publish(ArticleRead, ArticleState{ArticleID: 7})
+
+	for _, sub := range []*Subscription{a, b} {
+		ev := receive(t, sub)
+		if ev.ID != 1 || ev.Type != ArticleRead || ev.Data.(ArticleState).ArticleID != 7 {
+			t.Errorf("received %+v, want article.read for 7 with ID 1", ev)
+		}
+	}
+
+	a.Close()
+	a.Close()
+	bus.Publish(ArticleUnread, ArticleState{ArticleID: 7})
+	if _, ok := <-a.C; ok {
+		t.Error("closed subscription still receives events")
+	}
+	if ev := receive(t, b); ev.Type != ArticleUnread {
+		t.Errorf("received %s, want %s", ev.Type, ArticleUnread)
+	}
+}
+
+func TestBus_ReplaysMissedEvents(t *testing.T) {
+	bus := New()
+	for i := 0; i < 5; i++ {
+		bus.Publish(FeedSynced, FeedSync{FeedID: int64(i)})
+	}
+
+	sub := bus.Subscribe(3)
+	for _, want := range []uint64{4, 5} {
+		if ev := receive(t, sub); ev.ID != want {
+			t.Errorf("replayed ID %d, want %d", ev.ID, want)
+		}
+	}
+
+	bus.Publish(FeedFailed, FeedSync{FeedID: 9, Error: "timeout"})
+	if ev := receive(t, sub); ev.ID != 6 || ev.Type != FeedFailed {
+		t.Errorf("received %+v after replay, want feed.failed with ID 6", ev)
+	}
+
+	// History is bounded.
+	for i := 0; i < historySize+10; i++ {
+		bus.Publish(FeedSynced, FeedSync{})
+	}
+	if got := len(bus.history); got != historySize {
+		t.Errorf("history length = %d, want %d", got, historySize)
+	}
+}
+
+func TestBus_DropsSlowSubscribers(t *testing.T) {
+	bus := New()
+	slow := bus.Subscribe(0)
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(ArticleSaved, ArticleState{ArticleID: int64(i)})
+	}
+
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber received %d events before being dropped, want %d", n, subscriberBuffer)
+	}
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := New()
+	sub := bus.Subscribe(0)
+	bus.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription open after Bus.Close")
+	}
+	bus.Publish(ArticleRead, ArticleState{})
+	if _, ok := <-bus.Subscribe(0).C; ok {
+		t.Error("Subscribe after Bus.Close returned an open subscription")
+	}
+
+	var nilBus *Bus
+	nilBus.Publish(ArticleRead, ArticleState{})
+	sub = nilBus.Subscribe(0)
+	if _, ok := <-sub.C; ok {
+		t.Error("Subscribe on a nil Bus returned an open subscription")
+	}
+	sub.Close()
+	nilBus.Close()
+}
@@ -0,0 +1,313 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"x":   escapeXML,
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(templateFS, "templates/*"))
+
+const (
+	epubMimetype = "application/epub+zip"
+	// maxImageSize skips images too large to be worth carrying around.
+	maxImageSize = 5 << 20
+)
+
+// imageExtensions lists the image types EPUB readers must support.
+var imageExtensions = map[string]string{
+	"image/gif":     ".gif",
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/svg+xml": ".svg",
+	"image/webp":    ".webp",
+}
+
+var errNotBundled = errors.New("image cannot be bundled")
+
+// Book is what WriteEPUB puts in an EPUB.
+type Book struct {
+	Title   string
+	Entries []Entry
+	Created time.Time
+}
+
+type chapter struct {
+	ID, Href, Title string
+
+	Feed, Published, Tags, Summary, URL string
+	Score                               int
+	Content                             string // clean XHTML
+}
+
+type image struct {
+	ID, Href, MediaType string
+	data                []byte
+}
+
+// epubBuilder collects the images chapters refer to while they are
+// cleaned.
+type epubBuilder struct {
+	ctx     context.Context
+	client  *http.Client
+	images  []image
+	sources map[string]string // image source to bundled path, "" if dropped
+	hashes  map[[sha256.Size]byte]string
+}
+
+// WriteEPUB writes book as an EPUB 3 file with one chapter per article.
+// Images are bundled so the book reads offline: data URIs are decoded and,
+// when client is not nil, remote images are downloaded. Images that cannot
+// be bundled are left out.
+func WriteEPUB(ctx context.Context, w io.Writer, book Book, client *http.Client) error {
+	b := &epubBuilder{
+		ctx:     ctx,
+		client:  client,
+		sources: make(map[string]string),
+		hashes:  make(map[[sha256.Size]byte]string),
+	}
+
+	chapters := make([]chapter, 0, len(book.Entries))
+	for i, e := range book.Entries {
+		ch, err := b.chapter(i+1, e)
+		if err != nil {
+			return fmt.Errorf("article %d: %w", e.Article.ID, err)
+		}
+		chapters = append(chapters, ch)
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	data := map[string]any{
+		"ID":       "urn:uuid:" + formatUUID(id),
+		"Title":    book.Title,
+		"Date":     book.Created.UTC().Format(time.DateOnly),
+		"Modified": book.Created.UTC().Format("2006-01-02T15:04:05Z"),
+		"Chapters": chapters,
+		"Images":   b.images,
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype must come first, stored uncompressed and without extra
+	// fields, so readers can sniff it at a fixed offset.
+	mimetype, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(epubMimetype)),
+		CompressedSize64:   uint64(len(epubMimetype)),
+		UncompressedSize64: uint64(len(epubMimetype)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, epubMimetype); err != nil {
+		return err
+	}
+
+	create := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: book.Created})
+	}
+	files := []struct{ name, template string }{
+		{"META-INF/container.xml", "container.xml"},
+		{"OEBPS/content.opf", "content.opf"},
+		{"OEBPS/nav.xhtml", "nav.xhtml"},
+		{"OEBPS/toc.ncx", "toc.ncx"},
+		{"OEBPS/style.css", "style.css"},
+	}
+	for _, file := range files {
+		f, err := create(file.name)
+		if err != nil {
+			return err
+		}
+		if err := templates.ExecuteTemplate(f, file.template, data); err != nil {
+			return fmt.Errorf("rendering %s: %w", file.template, err)
+		}
+	}
+	for _, ch := range chapters {
+		f, err := create("OEBPS/" + ch.Href)
+		if err != nil {
+			return err
+		}
+		if err := templates.ExecuteTemplate(f, "chapter.xhtml", ch); err != nil {
+			return fmt.Errorf("rendering chapter: %w", err)
+		}
+	}
+	for _, img := range b.images {
+		f, err := create("OEBPS/" + img.Href)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(img.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (b *epubBuilder) chapter(n int, e Entry) (chapter, error) {
+	a := e.Article
+	ch := chapter{
+		ID:      "article-" + strconv.Itoa(n),
+		Href:    "article-" + strconv.Itoa(n) + ".xhtml",
+		Title:   a.Title,
+		Feed:    a.FeedName,
+		Tags:    strings.Join(e.Tags, ", "),
+		Summary: a.Summary,
+		URL:     a.URL,
+		Score:   a.QualityRank,
+	}
+	if !a.PublishedAt.IsZero() {
+		ch.Published = a.PublishedAt.Format("2 January 2006")
+	}
+	if a.Content != "" {
+		content, err := b.xhtml(a.Content)
+		if err != nil {
+			return chapter{}, err
+		}
+		ch.Content = content
+	}
+	return ch, nil
+}
+
+// image returns the path of src inside the book, bundling it on first use,
+// or "" when it cannot be bundled.
+func (b *epubBuilder) image(src string) string {
+	if href, ok := b.sources[src]; ok {
+		return href
+	}
+	var href string
+	if data, mediaType, err := b.load(src); err == nil {
+		href = b.add(data, mediaType)
+	}
+	b.sources[src] = href
+	return href
+}
+
+func (b *epubBuilder) load(src string) ([]byte, string, error) {
+	if rest, ok := strings.CutPrefix(src, "data:"); ok {
+		return decodeDataURI(rest)
+	}
+	if b.client == nil || !isHTTP(src) {
+		return nil, "", errNotBundled
+	}
+
+	req, err := http.NewRequestWithContext(b.ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "TheDailySynapse/1.0")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageSize {
+		return nil, "", errNotBundled
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return data, mediaType, nil
+}
+
+// add stores an image once however often it is used and returns its path.
+func (b *epubBuilder) add(data []byte, mediaType string) string {
+	ext, ok := imageExtensions[mediaType]
+	if !ok {
+		// Servers often send a generic type; trust the bytes instead.
+		mediaType = http.DetectContentType(data)
+		if ext, ok = imageExtensions[mediaType]; !ok {
+			return ""
+		}
+	}
+	sum := sha256.Sum256(data)
+	if href, ok := b.hashes[sum]; ok {
+		return href
+	}
+
+	n := len(b.images) + 1
+	img := image{
+		ID:        "image-" + strconv.Itoa(n),
+		Href:      "images/" + strconv.Itoa(n) + ext,
+		MediaType: mediaType,
+		data:      data,
+	}
+	b.images = append(b.images, img)
+	b.hashes[sum] = img.Href
+	return img.Href
+}
+
+// decodeDataURI decodes the part of a base64 data URI after "data:".
+func decodeDataURI(s string) ([]byte, string, error) {
+	meta, payload, ok := strings.Cut(s, ",")
+	if !ok {
+		return nil, "", errNotBundled
+	}
+	meta, isBase64 := strings.CutSuffix(meta, ";base64")
+	if !isBase64 {
+		return nil, "", errNotBundled
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(meta)
+	return data, mediaType, nil
+}
+
+func formatUUID(b []byte) string {
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// escapeXML escapes text and attribute values, dropping characters XML
+// does not allow at all.
+func escapeXML(s string) string {
+	return html.EscapeString(validXML(s))
+}
+
+func validXML(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t', r == '\n', r == '\r',
+			r >= 0x20 && r <= 0xD7FF,
+			r >= 0xE000 && r <= 0xFFFD,
+			r >= 0x10000 && r <= 0x10FFFF:
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func isHTTP(rawURL string) bool {
+	lower := strings.ToLower(strings.TrimSpace(rawURL))
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,102 @@
+// Package export writes articles out for keeping: JSON and CSV for other
+// tools, a zip of Markdown notes with front matter for Obsidian-style
+// vaults, and an EPUB book with the full text and images for reading
+// offline.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Entry is an article with its tags. Only WriteEPUB uses Article.Content.
+type Entry struct {
+	Article core.Article
+	Tags    []string
+}
+
+// record is one article in the JSON export.
+type record struct {
+	ID            int64     `json:"id"`
+	Title         string    `json:"title"`
+	URL           string    `json:"url"`
+	Feed          string    `json:"feed"`
+	PublishedAt   time.Time `json:"published_at"`
+	Score         int       `json:"score"`
+	Tags          []string  `json:"tags"`
+	Summary       string    `json:"summary"`
+	Justification string    `json:"justification"`
+	Read          bool      `json:"read"`
+	Saved         bool      `json:"saved"`
+}
+
+type document struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Articles   []record  `json:"articles"`
+}
+
+// WriteJSON writes entries as one indented JSON document.
+func WriteJSON(w io.Writer, entries []Entry, now time.Time) error {
+	doc := document{ExportedAt: now.UTC(), Articles: make([]record, 0, len(entries))}
+	for _, e := range entries {
+		a := e.Article
+		tags := e.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		doc.Articles = append(doc.Articles, record{
+			ID:            a.ID,
+			Title:         a.Title,
+			URL:           a.URL,
+			Feed:          a.FeedName,
+			PublishedAt:   a.PublishedAt,
+			Score:         a.QualityRank,
+			Tags:          tags,
+			Summary:       a.Summary,
+			Justification: a.Justification,
+			Read:          a.IsRead,
+			Saved:         a.ReadLater,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+var csvHeader = []string{"id", "title", "url", "feed", "published_at", "score", "tags", "summary", "justification", "read", "saved"}
+
+// WriteCSV writes entries with a header row. Tags are joined with "; ".
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		a := e.Article
+		row := []string{
+			strconv.FormatInt(a.ID, 10),
+			a.Title,
+			a.URL,
+			a.FeedName,
+			a.PublishedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(a.QualityRank),
+			strings.Join(e.Tags, "; "),
+			a.Summary,
+			a.Justification,
+			strconv.FormatBool(a.IsRead),
+			strconv.FormatBool(a.ReadLater),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
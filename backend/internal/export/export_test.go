@@ -0,0 +1,237 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// pngPixel is a 1x1 transparent PNG.
+var pngPixel, _ = base64.StdEncoding.DecodeString("iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg==")
+
+func testEntries() []Entry {
+	published := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	return []Entry{
+		{
+			Article: core.Article{
+				ID: 1, FeedName: "Go Blog", Title: `Profiling "hot" paths: a guide`, URL: "https://example.com/pgo",
+				PublishedAt: published, QualityRank: 92, Summary: "How PGO works.",
+				Justification: "Deep and practical.", ReadLater: true,
+			},
+			Tags: []string{"Go", "Performance Tuning"},
+		},
+		{
+			Article: core.Article{
+				ID: 2, FeedName: "Other", Title: "Profiling \"hot\" paths: a guide", URL: "https://example.com/dup",
+				PublishedAt: published, QualityRank: 70, IsRead: true,
+			},
+		},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	if err := WriteJSON(&buf, testEntries(), now); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if !doc.ExportedAt.Equal(now) || len(doc.Articles) != 2 {
+		t.Fatalf("document = %+v, want two articles exported at %v", doc, now)
+	}
+	first := doc.Articles[0]
+	if first.Score != 92 || first.Justification != "Deep and practical." || !first.Saved || len(first.Tags) != 2 {
+		t.Errorf("first article = %+v", first)
+	}
+	if !strings.Contains(buf.String(), `"tags": []`) {
+		t.Errorf("untagged article should have an empty tags list:\n%s", buf.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testEntries()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not CSV: %v", err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Fatalf("rows = %q, want a header and two articles", rows)
+	}
+	want := []string{"1", `Profiling "hot" paths: a guide`, "https://example.com/pgo", "Go Blog", "2026-03-14T09:00:00Z",
+		"92", "Go; Performance Tuning", "How PGO works.", "Deep and practical.", "false", "true"}
+	if strings.Join(rows[1], "|") != strings.Join(want, "|") {
+		t.Errorf("row = %q, want %q", rows[1], want)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, testEntries(), time.Now()); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	files := unzip(t, buf.Bytes())
+
+	note, ok := files["Profiling hot paths a guide.md"]
+	if !ok {
+		t.Fatalf("files = %v, want a note named after the title", keys(files))
+	}
+	for _, want := range []string{
+		"---\ntitle: \"Profiling \\\"hot\\\" paths: a guide\"\n",
+		"published: 2026-03-14\n",
+		"score: 92\n",
+		"tags:\n  - \"Go\"\n  - \"Performance-Tuning\"\n",
+		"justification: \"Deep and practical.\"\n",
+		"saved: true\n",
+		"---\n\n# Profiling \"hot\" paths: a guide\n\nHow PGO works.\n\n[Original article](<https://example.com/pgo>)\n",
+	} {
+		if !strings.Contains(note, want) {
+			t.Errorf("note is missing %q:\n%s", want, note)
+		}
+	}
+	if _, ok := files["Profiling hot paths a guide (2).md"]; !ok {
+		t.Errorf("files = %v, want the second article with the same title numbered", keys(files))
+	}
+}
+
+func TestWriteEPUB(t *testing.T) {
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pixel" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(pngPixel)
+	}))
+	defer images.Close()
+
+	entries := testEntries()
+	entries[0].Article.Content = `<p>Intro &amp; <b>bold</b><br>line</p>` +
+		`<script>alert(1)</script>` +
+		`<p onclick="x()"><img src="data:image/png;base64,` + base64.StdEncoding.EncodeToString(pngPixel) + `" alt="inline">` +
+		`<img src="` + images.URL + `/pixel"><img src="` + images.URL + `/missing"></p>` +
+		`<custom-widget>kept text</custom-widget><svg><circle r="1"/></svg>` +
+		`<a href="javascript:alert(1)">bad</a> <a href="https://go.dev">good</a>` + "\x0b"
+
+	var buf bytes.Buffer
+	book := Book{Title: "Saved & sound", Entries: entries, Created: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)}
+	if err := WriteEPUB(context.Background(), &buf, book, images.Client()); err != nil {
+		t.Fatalf("WriteEPUB() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip: %v", err)
+	}
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store || len(first.Extra) != 0 {
+		t.Errorf("first entry = %s (method %d, %d extra bytes), want an uncompressed mimetype", first.Name, first.Method, len(first.Extra))
+	}
+	files := unzip(t, buf.Bytes())
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("mimetype = %q", files["mimetype"])
+	}
+
+	for name, content := range files {
+		if strings.HasSuffix(name, ".xhtml") || strings.HasSuffix(name, ".opf") || strings.HasSuffix(name, ".ncx") || strings.HasSuffix(name, ".xml") {
+			checkXML(t, name, content)
+		}
+	}
+
+	// Both copies of the pixel are bundled as one file.
+	if _, ok := files["OEBPS/images/1.png"]; !ok || len(files) != 9 {
+		t.Errorf("files = %v, want one bundled image", keys(files))
+	}
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		`<dc:title>Saved &amp; sound</dc:title>`,
+		`<item id="image-1" href="images/1.png" media-type="image/png"/>`,
+		`<itemref idref="article-2"/>`,
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf is missing %q:\n%s", want, opf)
+		}
+	}
+
+	chapter := files["OEBPS/article-1.xhtml"]
+	for _, want := range []string{
+		`<p>Intro &amp; <b>bold</b><br/>line</p>`,
+		`<img src="images/1.png" alt="inline"/><img src="images/1.png"/></p>`,
+		`kept text`,
+		`<a>bad</a> <a href="https://go.dev">good</a>`,
+		`Go, Performance Tuning`,
+	} {
+		if !strings.Contains(chapter, want) {
+			t.Errorf("chapter is missing %q:\n%s", want, chapter)
+		}
+	}
+	for _, unwanted := range []string{"script", "onclick", "svg", "custom-widget", "/missing", "javascript"} {
+		if strings.Contains(chapter, unwanted) {
+			t.Errorf("chapter still contains %q:\n%s", unwanted, chapter)
+		}
+	}
+	if !strings.Contains(files["OEBPS/article-2.xhtml"], "could not be fetched") {
+		t.Errorf("chapter without content should say so:\n%s", files["OEBPS/article-2.xhtml"])
+	}
+}
+
+func unzip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func checkXML(t *testing.T, name, content string) {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(content))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Errorf("%s is not well-formed XML: %v\n%s", name, err, content)
+			return
+		}
+	}
+}
+
+func keys(m map[string]string) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxNoteName keeps note file names well under common path limits.
+const maxNoteName = 100
+
+// WriteMarkdown writes a zip with one note per article, named after its
+// title, ready to drop into an Obsidian vault.
+func WriteMarkdown(w io.Writer, entries []Entry, now time.Time) error {
+	zw := zip.NewWriter(w)
+	taken := make(map[string]bool)
+	for _, e := range entries {
+		name := noteName(e, taken) + ".md"
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, note(e)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// note renders an article as Markdown with YAML front matter holding its
+// score, tags and the judge's justification.
+func note(e Entry) string {
+	a := e.Article
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", yamlString(a.Title))
+	fmt.Fprintf(&b, "url: %s\n", yamlString(a.URL))
+	if a.FeedName != "" {
+		fmt.Fprintf(&b, "feed: %s\n", yamlString(a.FeedName))
+	}
+	if !a.PublishedAt.IsZero() {
+		fmt.Fprintf(&b, "published: %s\n", a.PublishedAt.Format(time.DateOnly))
+	}
+	fmt.Fprintf(&b, "score: %d\n", a.QualityRank)
+	if len(e.Tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range e.Tags {
+			fmt.Fprintf(&b, "  - %s\n", yamlString(noteTag(tag)))
+		}
+	}
+	if a.Justification != "" {
+		fmt.Fprintf(&b, "justification: %s\n", yamlString(a.Justification))
+	}
+	fmt.Fprintf(&b, "saved: %t\n", a.ReadLater)
+	fmt.Fprintf(&b, "read: %t\n", a.IsRead)
+	fmt.Fprintf(&b, "synapse_id: %d\n", a.ID)
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", a.Title)
+	if a.Summary != "" {
+		fmt.Fprintf(&b, "%s\n\n", a.Summary)
+	}
+	fmt.Fprintf(&b, "[Original article](<%s>)\n", a.URL)
+	return b.String()
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, whose escapes are a
+// superset of Go's.
+func yamlString(s string) string {
+	return strconv.Quote(s)
+}
+
+// noteTag makes a tag usable in Obsidian, which does not allow spaces or a
+// leading #.
+func noteTag(tag string) string {
+	tag = strings.TrimLeft(strings.TrimSpace(tag), "#")
+	return strings.Join(strings.Fields(tag), "-")
+}
+
+// noteName turns a title into a file name that is valid on every common
+// file system and not yet taken, ignoring case.
+func noteName(e Entry, taken map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r), strings.ContainsRune(`\/:*?"<>|#^[]`, r):
+			return ' '
+		}
+		return r
+	}, e.Article.Title)
+	name = strings.Join(strings.Fields(name), " ")
+	if runes := []rune(name); len(runes) > maxNoteName {
+		name = string(runes[:maxNoteName])
+	}
+	name = strings.Trim(name, " .")
+	if name == "" {
+		name = "Article " + strconv.FormatInt(e.Article.ID, 10)
+	}
+	if taken[strings.ToLower(name)] {
+		name += " (" + strconv.FormatInt(e.Article.ID, 10) + ")"
+	}
+	taken[strings.ToLower(name)] = true
+	return name
+}
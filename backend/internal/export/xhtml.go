@@ -0,0 +1,129 @@
+package export
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// droppedElements go with everything inside them.
+	droppedElements = map[string]bool{
+		"script": true, "style": true, "head": true, "meta": true, "link": true, "title": true,
+		"iframe": true, "object": true, "embed": true, "form": true, "input": true, "button": true,
+		"select": true, "textarea": true, "noscript": true, "template": true, "canvas": true,
+		"video": true, "audio": true, "source": true, "track": true,
+	}
+
+	// keptElements are plain text markup every reader renders. Anything
+	// else is unwrapped, keeping its content.
+	keptElements = map[string]bool{
+		"p": true, "br": true, "hr": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true,
+		"blockquote": true, "pre": true, "code": true, "kbd": true, "samp": true, "var": true,
+		"em": true, "strong": true, "b": true, "i": true, "u": true, "s": true, "del": true, "ins": true,
+		"sub": true, "sup": true, "small": true, "mark": true, "abbr": true, "cite": true, "q": true,
+		"a": true, "img": true, "figure": true, "figcaption": true,
+		"table": true, "caption": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "th": true, "td": true,
+		"div": true, "span": true, "section": true, "article": true, "aside": true, "header": true, "footer": true,
+	}
+
+	keptAttrs = map[string]bool{
+		"href": true, "src": true, "alt": true, "title": true,
+		"colspan": true, "rowspan": true,
+	}
+)
+
+// xhtml cleans an HTML fragment into well-formed XHTML for a chapter,
+// bundling its images on the way.
+func (b *epubBuilder) xhtml(fragment string) (string, error) {
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), root)
+	if err != nil {
+		return "", err
+	}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	b.clean(root)
+
+	var out strings.Builder
+	for n := root.FirstChild; n != nil; n = n.NextSibling {
+		if err := html.Render(&out, n); err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
+
+func (b *epubBuilder) clean(parent *html.Node) {
+	for n := parent.FirstChild; n != nil; {
+		next := n.NextSibling
+		switch n.Type {
+		case html.ElementNode:
+			switch {
+			// SVG and MathML would need their namespaces declared.
+			case droppedElements[n.Data], n.Namespace != "":
+				parent.RemoveChild(n)
+			case !keptElements[n.Data]:
+				b.clean(n)
+				for child := n.FirstChild; child != nil; child = n.FirstChild {
+					n.RemoveChild(child)
+					parent.InsertBefore(child, n)
+				}
+				parent.RemoveChild(n)
+			default:
+				b.cleanAttrs(n)
+				if n.Data == "img" && !hasAttr(n, "src") {
+					parent.RemoveChild(n)
+					break
+				}
+				b.clean(n)
+			}
+		case html.TextNode:
+			n.Data = validXML(n.Data)
+		default:
+			parent.RemoveChild(n)
+		}
+		n = next
+	}
+}
+
+// cleanAttrs keeps the attributes on the allow list, links to http(s) and
+// mail, and images that could be bundled.
+func (b *epubBuilder) cleanAttrs(n *html.Node) {
+	kept := n.Attr[:0]
+	for _, attr := range n.Attr {
+		key := strings.ToLower(attr.Key)
+		if attr.Namespace != "" || !keptAttrs[key] {
+			continue
+		}
+		switch key {
+		case "href":
+			if !isHTTP(attr.Val) && !strings.HasPrefix(attr.Val, "mailto:") {
+				continue
+			}
+		case "src":
+			if n.Data != "img" {
+				continue
+			}
+			if attr.Val = b.image(strings.TrimSpace(attr.Val)); attr.Val == "" {
+				continue
+			}
+		}
+		attr.Key = key
+		attr.Val = validXML(attr.Val)
+		kept = append(kept, attr)
+	}
+	n.Attr = kept
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/events"
 	"dailysynapse/backend/internal/store"
 	"dailysynapse/backend/pkg/judge"
 	"dailysynapse/backend/pkg/retry"
@@ -15,14 +16,16 @@ type Worker struct {
 	store  store.ArticleStore
 	scorer judge.Scorer
 	cfg    *config.Config
+	events *events.Bus
 	logger *slog.Logger
 }
 
-func NewWorker(s store.ArticleStore, scorer judge.Scorer, cfg *config.Config, logger *slog.Logger) *Worker {
+func NewWorker(s store.ArticleStore, scorer judge.Scorer, cfg *config.Config, bus *events.Bus, logger *slog.Logger) *Worker {
 	return &Worker{
 		store:  s,
 		scorer: scorer,
 		cfg:    cfg,
+		events: bus,
 		logger: logger,
 	}
 }
@@ -78,7 +81,7 @@ func (w *Worker) processNextArticle(ctx context.Context) {
 	}
 
 	if result.TotalScore < 50 {
-		if err := w.store.DeleteArticle(ctx, article.ID); err != nil {
+		if err := w.store.RejectArticle(ctx, article.ID, result.TotalScore); err != nil {
 			w.logger.Error("failed to delete low-score article",
 				slog.Int64("id", article.ID),
 				slog.String("error", err.Error()),
@@ -105,10 +108,20 @@ func (w *Worker) processNextArticle(ctx context.Context) {
 			slog.Int64("id", article.ID),
 			slog.String("error", err.Error()),
 		)
-	} else {
-		w.logger.Info("scored article",
-			slog.String("title", article.Title),
-			slog.Int("score", result.TotalScore),
-		)
+		return
 	}
+
+	w.logger.Info("scored article",
+		slog.String("title", article.Title),
+		slog.Int("score", result.TotalScore),
+	)
+	w.events.Publish(events.ArticleScored, events.ArticleScore{
+		ArticleID: article.ID,
+		FeedID:    article.FeedID,
+		Title:     article.Title,
+		URL:       article.URL,
+		Score:     result.TotalScore,
+		Summary:   result.Summary,
+		Tags:      result.Tags,
+	})
 }
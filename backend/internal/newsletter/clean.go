@@ -0,0 +1,173 @@
+package newsletter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Cleaned is a newsletter body ready to be stored and judged.
+type Cleaned struct {
+	HTML   string
+	Text   string
+	WebURL string // "view in browser" link, if the sender offers one
+}
+
+// Footers and preheaders are small blocks; anything larger is likely the
+// layout table that holds the whole issue and must not be removed.
+const maxBoilerplateText = 600
+
+var (
+	strippedElements = "script, style, head, meta, link, title, iframe, object, embed, form, input, button, select, textarea, noscript, svg"
+
+	allowedAttrs = map[string]bool{
+		"href": true, "src": true, "alt": true, "title": true,
+		"colspan": true, "rowspan": true,
+	}
+
+	hiddenStyle    = regexp.MustCompile(`(?i)display\s*:\s*none|visibility\s*:\s*hidden|max-height\s*:\s*0|font-size\s*:\s*0|opacity\s*:\s*0(\.0+)?\s*(;|$)`)
+	tinyStyle      = regexp.MustCompile(`(?i)(width|height)\s*:\s*[01]px`)
+	footerPhrase   = regexp.MustCompile(`(?i)unsubscribe|manage (your )?(email )?(preferences|subscription)|update (your )?preferences|opt[ -]out`)
+	webViewPhrase  = regexp.MustCompile(`(?i)view (this )?(email |newsletter |issue )?(in (your |a )?browser|online|on (the )?web)|read (it )?online|web version`)
+	whitespaceRuns = regexp.MustCompile(`\s+`)
+)
+
+// Clean strips tracking pixels, hidden preheaders, unsubscribe footers and
+// anything that could run script, leaving markup safe to render in the
+// reader.
+func Clean(body string) (Cleaned, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return Cleaned{}, err
+	}
+
+	var c Cleaned
+
+	doc.Find(strippedElements).Remove()
+	doc.Find("[style]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return hiddenStyle.MatchString(s.AttrOr("style", ""))
+	}).Remove()
+	doc.Find("img").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return isTrackingPixel(s)
+	}).Remove()
+
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		label := a.Text() + " " + a.AttrOr("title", "")
+		if c.WebURL == "" && webViewPhrase.MatchString(label) && isHTTP(a.AttrOr("href", "")) {
+			c.WebURL = a.AttrOr("href", "")
+			removeBlock(a, false)
+		}
+	})
+
+	doc.Find("footer").Remove()
+	doc.Find("a").FilterFunction(func(_ int, a *goquery.Selection) bool {
+		return footerPhrase.MatchString(a.Text()) || strings.Contains(strings.ToLower(a.AttrOr("href", "")), "unsubscribe")
+	}).Each(func(_ int, a *goquery.Selection) {
+		removeBlock(a, true)
+	})
+
+	sanitize(doc.Selection)
+
+	root := doc.Find("body")
+	if root.Length() == 0 {
+		root = doc.Selection
+	}
+	if c.HTML, err = root.Html(); err != nil {
+		return Cleaned{}, err
+	}
+	c.HTML = strings.TrimSpace(c.HTML)
+	c.Text = strings.TrimSpace(whitespaceRuns.ReplaceAllString(blockText(root), " "))
+	return c, nil
+}
+
+func isTrackingPixel(img *goquery.Selection) bool {
+	if strings.TrimSpace(img.AttrOr("src", "")) == "" {
+		return true
+	}
+	for _, attr := range []string{"width", "height"} {
+		if v, ok := img.Attr(attr); ok {
+			if n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(v), "px")); err == nil && n <= 1 {
+				return true
+			}
+		}
+	}
+	return tinyStyle.MatchString(img.AttrOr("style", ""))
+}
+
+// removeBlock removes the smallest block around a link. Footers usually hold
+// a postal address and social links after the unsubscribe line, so with
+// trailing the following siblings go too.
+func removeBlock(link *goquery.Selection, trailing bool) {
+	block := link.Closest("p, div, td, tr, li, table, section")
+	if block.Length() == 0 || len(strings.TrimSpace(block.Text())) > maxBoilerplateText {
+		link.Remove()
+		return
+	}
+	if trailing {
+		block.NextAll().FilterFunction(func(_ int, s *goquery.Selection) bool {
+			return len(strings.TrimSpace(s.Text())) <= maxBoilerplateText
+		}).Remove()
+	}
+	block.Remove()
+}
+
+// sanitize drops every attribute outside the allow list, along with links
+// and images that don't point at http(s).
+func sanitize(sel *goquery.Selection) {
+	for _, n := range sel.Nodes {
+		sanitizeNode(n)
+	}
+}
+
+func sanitizeNode(n *html.Node) {
+	if n.Type == html.ElementNode {
+		kept := n.Attr[:0]
+		for _, attr := range n.Attr {
+			key := strings.ToLower(attr.Key)
+			if !allowedAttrs[key] {
+				continue
+			}
+			if (key == "href" || key == "src") && !isHTTP(attr.Val) && !strings.HasPrefix(attr.Val, "mailto:") {
+				continue
+			}
+			kept = append(kept, attr)
+		}
+		n.Attr = kept
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sanitizeNode(child)
+	}
+}
+
+// blockText extracts text with block elements separated, so words in
+// adjacent table cells don't run together.
+func blockText(sel *goquery.Selection) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+		case html.ElementNode:
+			switch n.Data {
+			case "p", "div", "td", "tr", "li", "br", "h1", "h2", "h3", "h4", "h5", "h6", "table", "blockquote":
+				b.WriteString("\n")
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+	return b.String()
+}
+
+func isHTTP(rawURL string) bool {
+	lower := strings.ToLower(strings.TrimSpace(rawURL))
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,276 @@
+package newsletter
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IMAPPoller fetches unseen messages from a mailbox and marks them seen once
+// delivered. It speaks the handful of IMAP4rev1 commands that requires
+// (LOGIN, SELECT, UID SEARCH, UID FETCH, UID STORE, LOGOUT), which every
+// mainstream provider supports with an app password.
+type IMAPPoller struct {
+	Addr     string
+	Username string
+	Password string
+	Mailbox  string
+	TLS      bool
+	Timeout  time.Duration
+
+	Deliver DeliverFunc
+	Logger  *slog.Logger
+}
+
+func NewIMAPPoller(addr, username, password, mailbox string, useTLS bool, deliver DeliverFunc, logger *slog.Logger) *IMAPPoller {
+	if mailbox == "" {
+		mailbox = "INBOX"
+	}
+	return &IMAPPoller{
+		Addr:     addr,
+		Username: username,
+		Password: password,
+		Mailbox:  mailbox,
+		TLS:      useTLS,
+		Timeout:  time.Minute,
+		Deliver:  deliver,
+		Logger:   logger,
+	}
+}
+
+// Run polls every interval until ctx is cancelled.
+func (p *IMAPPoller) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := p.Poll(ctx); err != nil {
+			p.Logger.Error("imap poll failed", slog.String("addr", p.Addr), slog.String("error", err.Error()))
+		} else if n > 0 {
+			p.Logger.Info("imap poll complete", slog.Int("delivered", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll delivers every unseen message and returns how many were handled.
+// Messages that fail with a transient error stay unseen for the next poll;
+// malformed ones and those for a deleted feed are marked seen so they aren't
+// retried forever.
+func (p *IMAPPoller) Poll(ctx context.Context) (int, error) {
+	c, err := p.dial(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.close()
+
+	if _, err := c.command("LOGIN %s %s", quote(p.Username), quote(p.Password)); err != nil {
+		return 0, fmt.Errorf("login: %w", err)
+	}
+	if _, err := c.command("SELECT %s", quote(p.Mailbox)); err != nil {
+		return 0, fmt.Errorf("selecting %s: %w", p.Mailbox, err)
+	}
+
+	lines, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return 0, fmt.Errorf("searching: %w", err)
+	}
+	uids := searchResults(lines)
+
+	delivered := 0
+	for _, uid := range uids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		lines, err := c.command("UID FETCH %d BODY.PEEK[]", uid)
+		if err != nil {
+			return delivered, fmt.Errorf("fetching uid %d: %w", uid, err)
+		}
+		raw := fetchedLiteral(lines)
+		if raw == nil {
+			continue
+		}
+
+		if err := p.Deliver(ctx, raw); err != nil {
+			p.Logger.Error("failed to deliver newsletter",
+				slog.Uint64("uid", uint64(uid)),
+				slog.String("error", err.Error()),
+			)
+			if !errors.Is(err, ErrMalformed) && !errors.Is(err, ErrFeedDeleted) {
+				continue
+			}
+		} else {
+			delivered++
+		}
+
+		if _, err := c.command("UID STORE %d +FLAGS.SILENT (\\Seen)", uid); err != nil {
+			return delivered, fmt.Errorf("marking uid %d seen: %w", uid, err)
+		}
+	}
+
+	c.command("LOGOUT")
+	return delivered, nil
+}
+
+func (p *IMAPPoller) dial(ctx context.Context) (*imapConn, error) {
+	dialer := &net.Dialer{Timeout: p.Timeout}
+
+	var conn net.Conn
+	var err error
+	if p.TLS {
+		host, _, _ := net.SplitHostPort(p.Addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", p.Addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", p.Addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", p.Addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(p.Timeout))
+
+	c := &imapConn{conn: conn, r: bufio.NewReader(conn), timeout: p.Timeout}
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.text, "* OK") && !strings.HasPrefix(greeting.text, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting: %s", greeting.text)
+	}
+	return c, nil
+}
+
+// maxLiteralSize caps a single fetched message.
+const maxLiteralSize = 25 << 20
+
+type imapConn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	tag     int
+	timeout time.Duration
+}
+
+// imapLine is one response line; a trailing {n} literal is read into literal
+// and the rest of the line after it is appended to text.
+type imapLine struct {
+	text    string
+	literal []byte
+}
+
+// command sends a tagged command and returns the untagged responses, or an
+// error if the server doesn't answer OK.
+func (c *imapConn) command(format string, args ...any) ([]imapLine, error) {
+	c.tag++
+	tag := "a" + strconv.Itoa(c.tag)
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var untagged []imapLine
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if rest, ok := strings.CutPrefix(line.text, tag+" "); ok {
+			if strings.HasPrefix(strings.ToUpper(rest), "OK") {
+				return untagged, nil
+			}
+			return nil, errors.New(rest)
+		}
+		untagged = append(untagged, line)
+	}
+}
+
+func (c *imapConn) readLine() (imapLine, error) {
+	var line imapLine
+	for {
+		text, err := c.r.ReadString('\n')
+		if err != nil {
+			return line, err
+		}
+		text = strings.TrimRight(text, "\r\n")
+		line.text += text
+
+		size, ok := literalSize(text)
+		if !ok {
+			return line, nil
+		}
+		if size > maxLiteralSize {
+			return line, fmt.Errorf("literal of %d bytes exceeds limit", size)
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return line, err
+		}
+		line.literal = append(line.literal, buf...)
+	}
+}
+
+func (c *imapConn) close() error {
+	return c.conn.Close()
+}
+
+// literalSize parses a trailing "{123}" literal marker.
+func literalSize(text string) (int, bool) {
+	if !strings.HasSuffix(text, "}") {
+		return 0, false
+	}
+	open := strings.LastIndexByte(text, '{')
+	if open < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(text[open+1:len(text)-1], "+"))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func searchResults(lines []imapLine) []uint32 {
+	var uids []uint32
+	for _, line := range lines {
+		rest, ok := strings.CutPrefix(line.text, "* SEARCH")
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Fields(rest) {
+			if n, err := strconv.ParseUint(field, 10, 32); err == nil {
+				uids = append(uids, uint32(n))
+			}
+		}
+	}
+	return uids
+}
+
+func fetchedLiteral(lines []imapLine) []byte {
+	for _, line := range lines {
+		if strings.Contains(line.text, " FETCH ") && line.literal != nil {
+			return line.literal
+		}
+	}
+	return nil
+}
+
+// quote renders s as an IMAP quoted string.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,163 @@
+package newsletter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeIMAP is an in-process server that understands the commands the poller
+// sends, backed by a map of UID to message.
+type fakeIMAP struct {
+	mu       sync.Mutex
+	messages map[uint32][]byte
+	seen     map[uint32]bool
+	password string
+}
+
+func startFakeIMAP(t *testing.T, f *fakeIMAP) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func (f *fakeIMAP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "* OK fake IMAP ready\r\n")
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, cmd, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+		f.mu.Lock()
+		switch {
+		case strings.HasPrefix(cmd, "LOGIN "):
+			if strings.HasSuffix(cmd, strconv.Quote(f.password)) {
+				fmt.Fprintf(conn, "%s OK LOGIN completed\r\n", tag)
+			} else {
+				fmt.Fprintf(conn, "%s NO [AUTHENTICATIONFAILED] invalid credentials\r\n", tag)
+			}
+		case strings.HasPrefix(cmd, "SELECT "):
+			fmt.Fprintf(conn, "* %d EXISTS\r\n%s OK [READ-WRITE] SELECT completed\r\n", len(f.messages), tag)
+		case cmd == "UID SEARCH UNSEEN":
+			var uids []string
+			for uid := uint32(1); uid <= uint32(len(f.messages)); uid++ {
+				if !f.seen[uid] {
+					uids = append(uids, strconv.Itoa(int(uid)))
+				}
+			}
+			fmt.Fprintf(conn, "* SEARCH %s\r\n%s OK SEARCH completed\r\n", strings.Join(uids, " "), tag)
+		case strings.HasPrefix(cmd, "UID FETCH "):
+			uid := parseUID(cmd, "UID FETCH ")
+			raw := f.messages[uid]
+			fmt.Fprintf(conn, "* %d FETCH (UID %d BODY[] {%d}\r\n", uid, uid, len(raw))
+			conn.Write(raw)
+			fmt.Fprintf(conn, ")\r\n%s OK FETCH completed\r\n", tag)
+		case strings.HasPrefix(cmd, "UID STORE "):
+			f.seen[parseUID(cmd, "UID STORE ")] = true
+			fmt.Fprintf(conn, "%s OK STORE completed\r\n", tag)
+		case cmd == "LOGOUT":
+			fmt.Fprintf(conn, "* BYE\r\n%s OK LOGOUT completed\r\n", tag)
+			f.mu.Unlock()
+			return
+		default:
+			fmt.Fprintf(conn, "%s BAD unknown command\r\n", tag)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func parseUID(cmd, prefix string) uint32 {
+	field, _, _ := strings.Cut(strings.TrimPrefix(cmd, prefix), " ")
+	n, _ := strconv.ParseUint(field, 10, 32)
+	return uint32(n)
+}
+
+func TestIMAPPoller_Poll(t *testing.T) {
+	broken := []byte("this is not an email")
+	transient := []byte("From: flaky@example.com\r\nSubject: retry me\r\n\r\nbody\r\n")
+	deleted := []byte("From: gone@example.com\r\nSubject: unsubscribed\r\n\r\nbody\r\n")
+	f := &fakeIMAP{
+		messages: map[uint32][]byte{1: readFixture(t, "issue.eml"), 2: broken, 3: transient, 4: deleted},
+		seen:     map[uint32]bool{},
+		password: `p"ss`,
+	}
+	addr := startFakeIMAP(t, f)
+
+	var delivered []string
+	deliver := func(ctx context.Context, raw []byte) error {
+		msg, err := Parse(raw)
+		if err != nil {
+			return err
+		}
+		switch msg.FromEmail {
+		case "flaky@example.com":
+			return errors.New("database is locked")
+		case "gone@example.com":
+			return ErrFeedDeleted
+		}
+		delivered = append(delivered, msg.MessageID)
+		return nil
+	}
+
+	p := NewIMAPPoller(addr, "me", `p"ss`, "", false, deliver, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	n, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if n != 1 || len(delivered) != 1 || delivered[0] != "issue42@dbweekly.example" {
+		t.Errorf("Poll() = %d %v, want the fixture issue delivered", n, delivered)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.seen[1] {
+		t.Error("delivered message not marked seen")
+	}
+	if !f.seen[2] {
+		t.Error("malformed message not marked seen")
+	}
+	if !f.seen[4] {
+		t.Error("message for a deleted feed not marked seen")
+	}
+	if f.seen[3] {
+		t.Error("message with a transient failure marked seen, want it retried")
+	}
+}
+
+func TestIMAPPoller_LoginFailure(t *testing.T) {
+	addr := startFakeIMAP(t, &fakeIMAP{messages: map[uint32][]byte{}, seen: map[uint32]bool{}, password: "right"})
+
+	p := NewIMAPPoller(addr, "me", "wrong", "", false, func(context.Context, []byte) error { return nil },
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := p.Poll(context.Background()); err == nil || !strings.Contains(err.Error(), "AUTHENTICATIONFAILED") {
+		t.Errorf("Poll() error = %v, want login failure", err)
+	}
+}
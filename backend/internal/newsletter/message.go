@@ -0,0 +1,178 @@
+package newsletter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrMalformed marks messages that can never be ingested, so receivers can
+// drop them instead of retrying.
+var ErrMalformed = errors.New("malformed message")
+
+// maxPartDepth bounds multipart nesting; real newsletters rarely go past 3.
+const maxPartDepth = 8
+
+// Message is the subset of an email that matters for ingestion.
+type Message struct {
+	FromName  string
+	FromEmail string
+	Subject   string
+	MessageID string
+	Date      time.Time
+	HTML      string
+	Text      string
+}
+
+var headerDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// Parse reads an RFC 5322 message and picks its HTML body, falling back to
+// the plain text part.
+func Parse(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid From header: %v", ErrMalformed, err)
+	}
+
+	m := &Message{
+		FromName:  from.Name,
+		FromEmail: strings.ToLower(from.Address),
+		Subject:   decodeHeader(msg.Header.Get("Subject")),
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-ID")), "<>"),
+	}
+	if m.Date, err = msg.Header.Date(); err != nil {
+		m.Date = time.Now()
+	}
+	if m.MessageID == "" {
+		sum := sha256.Sum256(raw)
+		m.MessageID = hex.EncodeToString(sum[:16]) + "@synapse"
+	}
+
+	if err := m.readPart(msg.Header, msg.Body, 0); err != nil {
+		return nil, err
+	}
+	if m.HTML == "" && m.Text == "" {
+		return nil, fmt.Errorf("%w: no text or html body", ErrMalformed)
+	}
+	return m, nil
+}
+
+func (m *Message) readPart(header map[string][]string, body io.Reader, depth int) error {
+	if depth > maxPartDepth {
+		return fmt.Errorf("%w: multipart nesting too deep", ErrMalformed)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(first(header, "Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: reading multipart: %v", ErrMalformed, err)
+			}
+			if err := m.readPart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if mediaType != "text/html" && mediaType != "text/plain" {
+		return nil
+	}
+	if strings.HasPrefix(strings.ToLower(first(header, "Content-Disposition")), "attachment") {
+		return nil
+	}
+	// Keep the first alternative of each kind.
+	if (mediaType == "text/html" && m.HTML != "") || (mediaType == "text/plain" && m.Text != "") {
+		return nil
+	}
+
+	text, err := decodeBody(body, first(header, "Content-Transfer-Encoding"), params["charset"])
+	if err != nil {
+		return fmt.Errorf("%w: decoding %s part: %v", ErrMalformed, mediaType, err)
+	}
+	if mediaType == "text/html" {
+		m.HTML = text
+	} else {
+		m.Text = text
+	}
+	return nil
+}
+
+func decodeBody(body io.Reader, encoding, charsetLabel string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	if charsetLabel != "" && !strings.EqualFold(charsetLabel, "utf-8") && !strings.EqualFold(charsetLabel, "us-ascii") {
+		r, err := charset.NewReaderLabel(charsetLabel, body)
+		if err != nil {
+			return "", err
+		}
+		body = r
+	}
+
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func decodeHeader(v string) string {
+	decoded, err := headerDecoder.DecodeHeader(v)
+	if err != nil {
+		return v
+	}
+	return decoded
+}
+
+func first(header map[string][]string, key string) string {
+	if values := header[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// textToHTML wraps a plain text body so it can be shown like an HTML one.
+func textToHTML(text string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package newsletter
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+	return raw
+}
+
+func TestParse(t *testing.T) {
+	msg, err := Parse(readFixture(t, "issue.eml"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if msg.FromEmail != "editor@dbweekly.example" {
+		t.Errorf("FromEmail = %q, want editor@dbweekly.example", msg.FromEmail)
+	}
+	if msg.FromName != "Database Weekly" {
+		t.Errorf("FromName = %q, want Database Weekly", msg.FromName)
+	}
+	if msg.Subject != "Issue 42: Postgres ❤ indexes" {
+		t.Errorf("Subject = %q, want decoded subject", msg.Subject)
+	}
+	if msg.MessageID != "issue42@dbweekly.example" {
+		t.Errorf("MessageID = %q, want issue42@dbweekly.example", msg.MessageID)
+	}
+	if !msg.Date.Equal(time.Date(2025, 10, 14, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want 2025-10-14 09:30 UTC", msg.Date)
+	}
+	if !strings.Contains(msg.HTML, `<a href="https://dbweekly.example/issues/42">`) {
+		t.Errorf("HTML was not quoted-printable decoded: %q", msg.HTML)
+	}
+	if !strings.HasPrefix(msg.Text, "Plain text version.") {
+		t.Errorf("Text = %q, want plain text part", msg.Text)
+	}
+}
+
+func TestParse_Base64PlainText(t *testing.T) {
+	raw := "From: news@example.com\r\n" +
+		"Subject: Hello\r\n" +
+		"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"Q2Fm6SBuZXdzCg==\r\n"
+
+	msg, err := Parse([]byte(raw))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if msg.Text != "Café news\n" {
+		t.Errorf("Text = %q, want Café news", msg.Text)
+	}
+	if msg.MessageID == "" {
+		t.Error("MessageID should be synthesized when the header is missing")
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	if _, err := Parse([]byte("Subject: no sender\r\n\r\nbody")); err == nil {
+		t.Error("Parse() error = nil, want ErrMalformed for missing From")
+	}
+}
+
+func TestClean(t *testing.T) {
+	msg, err := Parse(readFixture(t, "issue.eml"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	c, err := Clean(msg.HTML)
+	if err != nil {
+		t.Fatalf("Clean() error = %v", err)
+	}
+
+	if c.WebURL != "https://dbweekly.example/issues/42" {
+		t.Errorf("WebURL = %q, want the web version link", c.WebURL)
+	}
+
+	for _, unwanted := range []string{
+		"track.example", "Preheader", "<script", "<style", "onclick",
+		"javascript:", "Unsubscribe", "123 Main St", "View this email",
+	} {
+		if strings.Contains(c.HTML, unwanted) {
+			t.Errorf("cleaned HTML still contains %q:\n%s", unwanted, c.HTML)
+		}
+	}
+	for _, wanted := range []string{"Partial indexes in practice", "chart.png", `href="https://example.com/story"`} {
+		if !strings.Contains(c.HTML, wanted) {
+			t.Errorf("cleaned HTML lost %q:\n%s", wanted, c.HTML)
+		}
+	}
+
+	if !strings.Contains(c.Text, "Partial indexes in practice Postgres partial indexes") {
+		t.Errorf("Text = %q, want block text separated by spaces", c.Text)
+	}
+}
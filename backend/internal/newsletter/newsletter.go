@@ -0,0 +1,141 @@
+// Package newsletter turns emailed newsletters into articles. Messages arrive
+// through a built-in SMTP listener or by polling an IMAP mailbox; each sender
+// becomes a virtual feed keyed by its mailto: URL, and every issue is judged
+// like any other feed item.
+package newsletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/syncer"
+)
+
+// ErrFeedDeleted marks mail for a newsletter whose feed is being deleted.
+// Receivers reject it for good rather than asking the sender to retry.
+var ErrFeedDeleted = errors.New("newsletter feed is being deleted")
+
+type FeedStore interface {
+	GetFeedByURL(ctx context.Context, url string) (core.Feed, error)
+	CreateFeedWithType(ctx context.Context, url, name, sourceType string) (core.Feed, error)
+}
+
+// Sink stores articles for a feed; *syncer.Syncer applies ingest rules on
+// the way in.
+type Sink interface {
+	Ingest(ctx context.Context, feed core.Feed, articles []core.Article) error
+}
+
+type Ingester struct {
+	feeds  FeedStore
+	sink   Sink
+	events *events.Bus
+	logger *slog.Logger
+}
+
+func NewIngester(feeds FeedStore, sink Sink, bus *events.Bus, logger *slog.Logger) *Ingester {
+	return &Ingester{feeds: feeds, sink: sink, events: bus, logger: logger}
+}
+
+// Deliver parses one raw message and ingests it into the sender's feed,
+// creating the feed on first contact. Issues for a paused feed are dropped.
+// It satisfies DeliverFunc.
+func (i *Ingester) Deliver(ctx context.Context, raw []byte) error {
+	msg, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	body := msg.HTML
+	if body == "" {
+		body = textToHTML(msg.Text)
+	}
+	cleaned, err := Clean(body)
+	if err != nil {
+		return fmt.Errorf("%w: cleaning body: %v", ErrMalformed, err)
+	}
+
+	feed, err := i.feedFor(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if feed.Status == "paused" {
+		i.logger.Info("dropping newsletter for paused feed",
+			slog.String("from", msg.FromEmail),
+			slog.String("subject", msg.Subject),
+		)
+		return nil
+	}
+
+	article := ArticleFromMessage(feed.ID, msg, cleaned)
+	if err := i.sink.Ingest(ctx, feed, []core.Article{article}); err != nil {
+		return err
+	}
+
+	i.logger.Info("newsletter received",
+		slog.String("from", msg.FromEmail),
+		slog.String("subject", msg.Subject),
+	)
+	return nil
+}
+
+func (i *Ingester) feedFor(ctx context.Context, msg *Message) (core.Feed, error) {
+	url := "mailto:" + msg.FromEmail
+
+	feed, err := i.feeds.GetFeedByURL(ctx, url)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, core.ErrNotFound) {
+		return core.Feed{}, fmt.Errorf("looking up feed for %s: %w", msg.FromEmail, err)
+	}
+
+	name := msg.FromName
+	if name == "" {
+		name = msg.FromEmail
+	}
+	feed, err = i.feeds.CreateFeedWithType(ctx, url, name, syncer.TypeNewsletter)
+	if errors.Is(err, core.ErrConflict) {
+		// The address is taken by a feed created concurrently, or by one
+		// pending deletion that lookups no longer return.
+		if feed, err := i.feeds.GetFeedByURL(ctx, url); err == nil {
+			return feed, nil
+		}
+		return core.Feed{}, fmt.Errorf("%w: %s", ErrFeedDeleted, msg.FromEmail)
+	}
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("creating feed for %s: %w", msg.FromEmail, err)
+	}
+	i.events.Publish(events.FeedAdded, events.FeedInfo{FeedID: feed.ID, Name: feed.Name, URL: feed.URL, Type: feed.Type})
+	return feed, nil
+}
+
+// ArticleFromMessage maps a newsletter issue onto an article. The issue
+// links to its web version when the sender provides one, otherwise to the
+// message itself as an RFC 2392 mid: URL.
+func ArticleFromMessage(feedID int64, msg *Message, cleaned Cleaned) core.Article {
+	link := cleaned.WebURL
+	if link == "" {
+		link = "mid:" + msg.MessageID
+	}
+
+	title := msg.Subject
+	if title == "" {
+		title = "(no subject)"
+	}
+
+	return core.Article{
+		FeedID:      feedID,
+		Title:       title,
+		URL:         link,
+		PublishedAt: msg.Date,
+		Summary:     cleaned.Text,
+		Content:     cleaned.HTML,
+		GUID:        msg.MessageID,
+		Authors:     []core.Author{{Name: msg.FromName, Email: msg.FromEmail}},
+	}
+}
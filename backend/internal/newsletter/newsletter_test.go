@@ -0,0 +1,131 @@
+package newsletter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+)
+
+type fakeFeeds struct {
+	feeds map[string]core.Feed
+}
+
+// GetFeedByURL hides feeds pending deletion, as the store does.
+func (f *fakeFeeds) GetFeedByURL(ctx context.Context, url string) (core.Feed, error) {
+	if feed, ok := f.feeds[url]; ok && feed.Status != "pending_deletion" {
+		return feed, nil
+	}
+	return core.Feed{}, core.ErrNotFound
+}
+
+func (f *fakeFeeds) CreateFeedWithType(ctx context.Context, url, name, sourceType string) (core.Feed, error) {
+	if _, ok := f.feeds[url]; ok {
+		return core.Feed{}, core.ErrConflict
+	}
+	feed := core.Feed{ID: int64(len(f.feeds) + 1), URL: url, Name: name, Type: sourceType}
+	f.feeds[url] = feed
+	return feed, nil
+}
+
+type fakeSink struct {
+	feeds    []core.Feed
+	articles []core.Article
+}
+
+func (s *fakeSink) Ingest(ctx context.Context, feed core.Feed, articles []core.Article) error {
+	s.feeds = append(s.feeds, feed)
+	s.articles = append(s.articles, articles...)
+	return nil
+}
+
+func TestIngester_Deliver(t *testing.T) {
+	feeds := &fakeFeeds{feeds: map[string]core.Feed{}}
+	sink := &fakeSink{}
+	bus := events.New()
+	sub := bus.Subscribe(0)
+	ing := NewIngester(feeds, sink, bus, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	raw := readFixture(t, "issue.eml")
+	for i := 0; i < 2; i++ {
+		if err := ing.Deliver(context.Background(), raw); err != nil {
+			t.Fatalf("Deliver() error = %v", err)
+		}
+	}
+
+	if len(feeds.feeds) != 1 {
+		t.Fatalf("created %d feeds, want one per sender", len(feeds.feeds))
+	}
+	feed := feeds.feeds["mailto:editor@dbweekly.example"]
+	if feed.Name != "Database Weekly" || feed.Type != "newsletter" {
+		t.Errorf("feed = %+v, want newsletter named after the sender", feed)
+	}
+	bus.Close()
+	var added []events.Event
+	for ev := range sub.C {
+		added = append(added, ev)
+	}
+	if len(added) != 1 || added[0].Type != events.FeedAdded || added[0].Data.(events.FeedInfo).FeedID != feed.ID {
+		t.Errorf("published %+v, want one feed.added for the new feed", added)
+	}
+
+	if len(sink.articles) != 2 {
+		t.Fatalf("ingested %d articles, want 2", len(sink.articles))
+	}
+	a := sink.articles[0]
+	if a.FeedID != feed.ID {
+		t.Errorf("FeedID = %d, want %d", a.FeedID, feed.ID)
+	}
+	if a.URL != "https://dbweekly.example/issues/42" {
+		t.Errorf("URL = %q, want the web version", a.URL)
+	}
+	if a.GUID != "issue42@dbweekly.example" {
+		t.Errorf("GUID = %q, want the Message-ID", a.GUID)
+	}
+	if !strings.Contains(a.Content, "Partial indexes in practice") || strings.Contains(a.Content, "Unsubscribe") {
+		t.Errorf("Content = %q, want cleaned body", a.Content)
+	}
+	if len(a.Summary) < 50 {
+		t.Errorf("Summary = %q, want body text long enough to judge", a.Summary)
+	}
+}
+
+func TestIngester_DeliverInactiveFeed(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr error
+	}{
+		{"paused", nil},
+		{"pending_deletion", ErrFeedDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			url := "mailto:editor@dbweekly.example"
+			feeds := &fakeFeeds{feeds: map[string]core.Feed{url: {ID: 1, URL: url, Status: tt.status}}}
+			sink := &fakeSink{}
+			ing := NewIngester(feeds, sink, events.New(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			err := ing.Deliver(context.Background(), readFixture(t, "issue.eml"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Deliver() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(sink.articles) != 0 || len(feeds.feeds) != 1 {
+				t.Errorf("ingested %d articles into %d feeds, want none and no new feed", len(sink.articles), len(feeds.feeds))
+			}
+		})
+	}
+}
+
+func TestArticleFromMessage_NoWebVersion(t *testing.T) {
+	msg := &Message{FromEmail: "news@example.com", Subject: "Hi", MessageID: "abc@example.com"}
+	a := ArticleFromMessage(1, msg, Cleaned{HTML: "<p>hello</p>", Text: "hello"})
+	if a.URL != "mid:abc@example.com" {
+		t.Errorf("URL = %q, want mid: URL", a.URL)
+	}
+}
@@ -0,0 +1,224 @@
+package newsletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// DeliverFunc receives one raw message per accepted SMTP transaction or
+// fetched IMAP message.
+type DeliverFunc func(ctx context.Context, raw []byte) error
+
+// ErrNoRecipients is returned by Serve when no recipient addresses are
+// configured.
+var ErrNoRecipients = errors.New("no newsletter recipients configured")
+
+// SMTPServer is a receive-only SMTP listener for newsletter subscriptions.
+// It implements just enough of RFC 5321 for MTAs and mail clients to hand
+// over a message: no relaying, no AUTH and no STARTTLS, so it belongs behind
+// a real MX or on a private network.
+type SMTPServer struct {
+	Addr    string
+	Domain  string
+	MaxSize int64
+	Timeout time.Duration
+
+	// Recipients limits RCPT TO to these addresses. Serve refuses to
+	// start without any, so the listener never takes mail for anyone.
+	Recipients []string
+
+	Deliver DeliverFunc
+	Logger  *slog.Logger
+}
+
+func NewSMTPServer(addr, domain string, recipients []string, deliver DeliverFunc, logger *slog.Logger) *SMTPServer {
+	return &SMTPServer{
+		Addr:       addr,
+		Domain:     domain,
+		MaxSize:    10 << 20,
+		Timeout:    5 * time.Minute,
+		Recipients: recipients,
+		Deliver:    deliver,
+		Logger:     logger,
+	}
+}
+
+func (s *SMTPServer) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.Addr, err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections until ctx is cancelled. It closes ln and
+// returns ErrNoRecipients if Recipients is empty.
+func (s *SMTPServer) Serve(ctx context.Context, ln net.Listener) error {
+	if len(s.Recipients) == 0 {
+		ln.Close()
+		return ErrNoRecipients
+	}
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+type smtpSession struct {
+	from       string
+	recipients []string
+}
+
+func (s *SMTPServer) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	reply := func(format string, args ...any) error {
+		return tp.PrintfLine(format, args...)
+	}
+
+	conn.SetDeadline(time.Now().Add(s.Timeout))
+	if err := reply("220 %s ESMTP synapse", s.Domain); err != nil {
+		return
+	}
+
+	var sess smtpSession
+	for {
+		conn.SetDeadline(time.Now().Add(s.Timeout))
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			sess = smtpSession{}
+			err = reply("250 %s", s.Domain)
+		case "EHLO":
+			sess = smtpSession{}
+			err = reply("250-%s\r\n250-SIZE %d\r\n250-8BITMIME\r\n250 PIPELINING", s.Domain, s.MaxSize)
+		case "MAIL":
+			addr, ok := pathArg(arg, "FROM:")
+			if !ok {
+				err = reply("501 5.5.4 Syntax: MAIL FROM:<address>")
+				break
+			}
+			sess = smtpSession{from: addr}
+			err = reply("250 2.1.0 OK")
+		case "RCPT":
+			addr, ok := pathArg(arg, "TO:")
+			switch {
+			case !ok:
+				err = reply("501 5.5.4 Syntax: RCPT TO:<address>")
+			case sess.from == "":
+				err = reply("503 5.5.1 MAIL first")
+			case !s.accepts(addr):
+				err = reply("550 5.1.1 No such mailbox")
+			default:
+				sess.recipients = append(sess.recipients, addr)
+				err = reply("250 2.1.5 OK")
+			}
+		case "DATA":
+			if len(sess.recipients) == 0 {
+				err = reply("503 5.5.1 RCPT first")
+				break
+			}
+			if err = reply("354 End data with <CR><LF>.<CR><LF>"); err != nil {
+				return
+			}
+			err = s.receive(ctx, tp, reply)
+			sess = smtpSession{}
+		case "RSET":
+			sess = smtpSession{}
+			err = reply("250 2.0.0 OK")
+		case "NOOP":
+			err = reply("250 2.0.0 OK")
+		case "VRFY":
+			err = reply("252 2.5.0 Cannot verify")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			err = reply("502 5.5.2 Command not implemented")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *SMTPServer) receive(ctx context.Context, tp *textproto.Conn, reply func(string, ...any) error) error {
+	dr := tp.DotReader()
+	raw, err := io.ReadAll(io.LimitReader(dr, s.MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(raw)) > s.MaxSize {
+		// Drain the rest so the connection stays in sync.
+		io.Copy(io.Discard, dr)
+		return reply("552 5.3.4 Message too big")
+	}
+
+	if err := s.Deliver(ctx, raw); err != nil {
+		s.Logger.Error("failed to deliver newsletter", slog.String("error", err.Error()))
+		if errors.Is(err, ErrMalformed) {
+			return reply("554 5.6.0 Message rejected")
+		}
+		if errors.Is(err, ErrFeedDeleted) {
+			return reply("550 5.2.1 Mailbox disabled")
+		}
+		return reply("451 4.3.0 Temporary failure, try again later")
+	}
+	return reply("250 2.0.0 OK queued")
+}
+
+func (s *SMTPServer) accepts(addr string) bool {
+	for _, r := range s.Recipients {
+		if strings.EqualFold(r, addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathArg extracts the address from "FROM:<a@b> SIZE=123" style arguments.
+// The null reverse path "<>" is returned as "<>" so bounces are accepted.
+func pathArg(arg, prefix string) (string, bool) {
+	arg = strings.TrimSpace(arg)
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	path, _, _ := strings.Cut(strings.TrimSpace(arg[len(prefix):]), " ")
+	if path == "<>" {
+		return path, true
+	}
+	addr, err := mail.ParseAddress(path)
+	if err != nil {
+		return "", false
+	}
+	return addr.Address, true
+}
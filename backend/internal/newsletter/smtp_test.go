@@ -0,0 +1,135 @@
+package newsletter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func startSMTP(t *testing.T, recipients []string, deliver DeliverFunc) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := NewSMTPServer(ln.Addr().String(), "synapse.test", recipients, deliver, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	srv.MaxSize = 64 << 10
+
+	done := make(chan struct{})
+	go func() {
+		srv.Serve(ctx, ln)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return ln.Addr().String()
+}
+
+func TestSMTPServer_Deliver(t *testing.T) {
+	var mu sync.Mutex
+	var got [][]byte
+	addr := startSMTP(t, []string{"news@synapse.example"}, func(ctx context.Context, raw []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, raw)
+		return nil
+	})
+
+	raw := readFixture(t, "issue.eml")
+	if err := smtp.SendMail(addr, nil, "editor@dbweekly.example", []string{"news@synapse.example"}, raw); err != nil {
+		t.Fatalf("SendMail() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("delivered %d messages, want 1", len(got))
+	}
+	msg, err := Parse(got[0])
+	if err != nil {
+		t.Fatalf("Parse() delivered message error = %v", err)
+	}
+	if msg.MessageID != "issue42@dbweekly.example" {
+		t.Errorf("MessageID = %q, want issue42@dbweekly.example", msg.MessageID)
+	}
+}
+
+func TestSMTPServer_RejectsUnknownRecipient(t *testing.T) {
+	addr := startSMTP(t, []string{"news@synapse.example"}, func(ctx context.Context, raw []byte) error {
+		t.Error("message delivered to unknown recipient")
+		return nil
+	})
+
+	err := smtp.SendMail(addr, nil, "editor@dbweekly.example", []string{"someone@else.example"}, readFixture(t, "issue.eml"))
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("SendMail() error = %v, want 550", err)
+	}
+}
+
+func TestSMTPServer_RequiresRecipients(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := NewSMTPServer(ln.Addr().String(), "synapse.test", nil, func(ctx context.Context, raw []byte) error {
+		t.Error("message delivered without configured recipients")
+		return nil
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := srv.Serve(context.Background(), ln); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("Serve() error = %v, want ErrNoRecipients", err)
+	}
+	if conn, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		conn.Close()
+		t.Error("listener still accepts connections")
+	}
+}
+
+func TestSMTPServer_DeliveryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"malformed", ErrMalformed, "554"},
+		{"feed deleted", ErrFeedDeleted, "550"},
+		{"transient", errors.New("database is locked"), "451"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startSMTP(t, []string{"news@synapse.example"}, func(ctx context.Context, raw []byte) error {
+				return tt.err
+			})
+
+			err := smtp.SendMail(addr, nil, "editor@dbweekly.example", []string{"news@synapse.example"}, readFixture(t, "issue.eml"))
+			if err == nil || !strings.Contains(err.Error(), tt.code) {
+				t.Errorf("SendMail() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestSMTPServer_TooLarge(t *testing.T) {
+	addr := startSMTP(t, []string{"news@synapse.example"}, func(ctx context.Context, raw []byte) error {
+		t.Error("oversized message delivered")
+		return nil
+	})
+
+	body := "From: a@example.com\r\n\r\n" + strings.Repeat("x", 70<<10) + "\r\n"
+	err := smtp.SendMail(addr, nil, "a@example.com", []string{"news@synapse.example"}, []byte(body))
+	if err == nil || !strings.Contains(err.Error(), "552") {
+		t.Errorf("SendMail() error = %v, want 552", err)
+	}
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Discord posts an embed to a channel webhook. Mentions are disabled so
+// an article title cannot ping anyone.
+type Discord struct{}
+
+func (Discord) Send(ctx context.Context, client *http.Client, ch core.NotificationChannel, msg Message) error {
+	return send(ctx, client, http.MethodPost, ch.URL, nil, discordPayload(msg))
+}
+
+func discordPayload(msg Message) map[string]any {
+	fields := []map[string]any{
+		{"name": "Score", "value": strconv.Itoa(msg.Score), "inline": true},
+	}
+	if msg.FeedName != "" {
+		fields = append(fields, map[string]any{"name": "Feed", "value": truncate(msg.FeedName, 1024), "inline": true})
+	}
+	if len(msg.Tags) > 0 {
+		fields = append(fields, map[string]any{"name": "Tags", "value": truncate(strings.Join(msg.Tags, ", "), 1024), "inline": true})
+	}
+	fields = append(fields, map[string]any{"name": "Original", "value": truncate(msg.URL, 1024)})
+
+	embed := map[string]any{
+		"title":  truncate(msg.Title, 256),
+		"url":    msg.ReaderURL,
+		"color":  discordColor(msg.Score),
+		"fields": fields,
+	}
+	if msg.Summary != "" {
+		embed["description"] = truncate(msg.Summary, 4096)
+	}
+
+	return map[string]any{
+		"username":         "The Daily Synapse",
+		"embeds":           []map[string]any{embed},
+		"allowed_mentions": map[string]any{"parse": []string{}},
+	}
+}
+
+// discordColor matches the score badge colors of the web UI.
+func discordColor(score int) int {
+	switch {
+	case score >= 80:
+		return 0x3fb950
+	case score >= 60:
+		return 0xd29922
+	default:
+		return 0x8b949e
+	}
+}
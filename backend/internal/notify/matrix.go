@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Matrix sends an m.notice to a room through the client-server API, as the
+// user whose access token the channel holds. That user must already have
+// joined the room.
+type Matrix struct{}
+
+func (Matrix) Send(ctx context.Context, client *http.Client, ch core.NotificationChannel, msg Message) error {
+	// Homeservers drop a request that reuses a transaction ID, so every
+	// message needs a fresh one.
+	txn := make([]byte, 8)
+	rand.Read(txn)
+	target := strings.TrimRight(ch.URL, "/") + "/_matrix/client/v3/rooms/" +
+		url.PathEscape(ch.RoomID) + "/send/m.room.message/" + hex.EncodeToString(txn)
+
+	header := http.Header{"Authorization": {"Bearer " + ch.Token}}
+	return send(ctx, client, http.MethodPut, target, header, matrixPayload(msg))
+}
+
+func matrixPayload(msg Message) map[string]any {
+	var text, formatted strings.Builder
+	fmt.Fprintf(&text, "%s (%d)\n", msg.Title, msg.Score)
+	fmt.Fprintf(&formatted, `<p><strong><a href="%s">%s</a></strong> · score <strong>%d</strong>`,
+		html.EscapeString(msg.ReaderURL), html.EscapeString(msg.Title), msg.Score)
+	if msg.FeedName != "" {
+		fmt.Fprintf(&text, "%s\n", msg.FeedName)
+		fmt.Fprintf(&formatted, "<br><em>%s</em>", html.EscapeString(msg.FeedName))
+	}
+	formatted.WriteString("</p>")
+	if msg.Summary != "" {
+		fmt.Fprintf(&text, "\n%s\n", msg.Summary)
+		fmt.Fprintf(&formatted, "<p>%s</p>", html.EscapeString(msg.Summary))
+	}
+	if len(msg.Tags) > 0 {
+		tags := strings.Join(msg.Tags, ", ")
+		fmt.Fprintf(&text, "\nTags: %s\n", tags)
+		fmt.Fprintf(&formatted, "<p>Tags: %s</p>", html.EscapeString(tags))
+	}
+	fmt.Fprintf(&text, "\nRead: %s\nOriginal: %s", msg.ReaderURL, msg.URL)
+	fmt.Fprintf(&formatted, `<p><a href="%s">Original article</a></p>`, html.EscapeString(msg.URL))
+
+	return map[string]any{
+		"msgtype":        "m.notice",
+		"body":           text.String(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	}
+}
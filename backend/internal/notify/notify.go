@@ -0,0 +1,375 @@
+// Package notify pings Slack, Discord and Matrix rooms when an exceptional
+// article is scored. Each channel has its own score, tag and feed filters
+// and a rate limit, so a burst of good articles cannot flood a room.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/pkg/retry"
+)
+
+const (
+	userAgent = "TheDailySynapse/1.0"
+	// maxAttempts is how often a message is tried before it is dropped.
+	maxAttempts = 3
+	// resubscribeDelay spaces out resubscribing after the bus drops us.
+	resubscribeDelay = time.Second
+
+	DefaultMinScore  = 90
+	DefaultRateLimit = 10
+)
+
+// ErrRateLimited is returned by SendTest when the channel has used up its
+// messages for the hour.
+var ErrRateLimited = fmt.Errorf("%w: channel sent its hourly messages", core.ErrRateLimited)
+
+// Message is what a notification says about an article.
+type Message struct {
+	ArticleID int64
+	Title     string
+	URL       string // the original article
+	ReaderURL string // the article's /read/{id} page
+	FeedName  string
+	Score     int
+	Summary   string
+	Tags      []string
+}
+
+// Adapter formats and posts a message to one kind of chat service.
+type Adapter interface {
+	Send(ctx context.Context, client *http.Client, ch core.NotificationChannel, msg Message) error
+}
+
+// Adapters maps channel types to their adapters.
+var Adapters = map[string]Adapter{
+	core.ChannelSlack:   Slack{},
+	core.ChannelDiscord: Discord{},
+	core.ChannelMatrix:  Matrix{},
+}
+
+func Validate(ch core.NotificationChannel) error {
+	if strings.TrimSpace(ch.Name) == "" {
+		return fmt.Errorf("%w: name is required", core.ErrBadRequest)
+	}
+	if _, ok := Adapters[ch.Type]; !ok {
+		return fmt.Errorf("%w: type must be slack, discord or matrix", core.ErrBadRequest)
+	}
+	u, err := url.Parse(ch.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", core.ErrBadRequest)
+	}
+	if ch.Type == core.ChannelMatrix && (ch.RoomID == "" || ch.Token == "") {
+		return fmt.Errorf("%w: matrix channels need a room_id and token", core.ErrBadRequest)
+	}
+	if ch.MinScore < 0 || ch.MinScore > 100 {
+		return fmt.Errorf("%w: min_score must be between 0 and 100", core.ErrBadRequest)
+	}
+	if ch.RateLimit < 1 {
+		return fmt.Errorf("%w: rate_limit must be at least 1 message per hour", core.ErrBadRequest)
+	}
+	return nil
+}
+
+// Matches reports whether an article passes a channel's filters: a score
+// of at least MinScore, one of Tags when set and one of FeedIDs when set.
+func Matches(ch core.NotificationChannel, article core.Article, tags []string) bool {
+	if article.QualityRank < ch.MinScore {
+		return false
+	}
+	if len(ch.FeedIDs) > 0 && !slices.Contains(ch.FeedIDs, article.FeedID) {
+		return false
+	}
+	if len(ch.Tags) == 0 {
+		return true
+	}
+	for _, want := range ch.Tags {
+		for _, tag := range tags {
+			if strings.EqualFold(want, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type Store interface {
+	GetNotificationChannels(ctx context.Context) ([]core.NotificationChannel, error)
+	GetArticleByID(ctx context.Context, id int64) (*core.Article, error)
+	GetArticleTags(ctx context.Context, articleID int64) ([]string, error)
+}
+
+type Notifier struct {
+	store   Store
+	bus     *events.Bus
+	client  *http.Client
+	baseURL string
+	logger  *slog.Logger
+	limiter *limiter
+
+	sub *events.Subscription
+	wg  sync.WaitGroup
+}
+
+// NewNotifier subscribes to bus straight away, so articles scored before
+// Run starts are not missed.
+func NewNotifier(store Store, bus *events.Bus, cfg *config.Config, logger *slog.Logger) *Notifier {
+	return &Notifier{
+		store:   store,
+		bus:     bus,
+		client:  &http.Client{Timeout: cfg.NotifyTimeout},
+		baseURL: cfg.BaseURL(),
+		logger:  logger,
+		limiter: newLimiter(),
+		sub:     bus.Subscribe(0),
+	}
+}
+
+// Run notifies channels about scored articles until ctx is cancelled, then
+// waits for messages in flight.
+func (n *Notifier) Run(ctx context.Context) {
+	defer n.wg.Wait()
+
+	var last uint64
+	for {
+		last = n.consume(ctx, n.sub, last)
+		n.sub.Close()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+		n.sub = n.bus.Subscribe(last)
+	}
+}
+
+func (n *Notifier) consume(ctx context.Context, sub *events.Subscription, last uint64) uint64 {
+	for {
+		select {
+		case <-ctx.Done():
+			return last
+		case ev, ok := <-sub.C:
+			if !ok {
+				return last
+			}
+			last = ev.ID
+			if score, ok := ev.Data.(events.ArticleScore); ok && ev.Type == events.ArticleScored {
+				n.notify(ctx, score.ArticleID)
+			}
+		}
+	}
+}
+
+// notify sends an article to every enabled channel it matches. Channels
+// over their rate limit skip it.
+func (n *Notifier) notify(ctx context.Context, articleID int64) {
+	channels, err := n.store.GetNotificationChannels(ctx)
+	if err != nil {
+		n.logger.Error("failed to load notification channels", "error", err)
+		return
+	}
+	channels = slices.DeleteFunc(channels, func(ch core.NotificationChannel) bool { return !ch.Enabled })
+	if len(channels) == 0 {
+		return
+	}
+
+	article, err := n.store.GetArticleByID(ctx, articleID)
+	if err != nil {
+		n.logger.Error("failed to load article for notification", "article_id", articleID, "error", err)
+		return
+	}
+	tags, err := n.store.GetArticleTags(ctx, articleID)
+	if err != nil {
+		n.logger.Error("failed to load tags for notification", "article_id", articleID, "error", err)
+		return
+	}
+	msg := n.message(*article, tags)
+
+	for _, ch := range channels {
+		if !Matches(ch, *article, tags) {
+			continue
+		}
+		if !n.limiter.allow(ch.ID, ch.RateLimit, time.Now()) {
+			n.logger.Warn("notification skipped, channel rate limit reached",
+				"channel_id", ch.ID, "article_id", articleID)
+			continue
+		}
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			if err := n.Send(ctx, ch, msg); err != nil {
+				n.logger.Warn("notification failed", "channel_id", ch.ID, "article_id", articleID, "error", err)
+			}
+		}()
+	}
+}
+
+func (n *Notifier) message(a core.Article, tags []string) Message {
+	return Message{
+		ArticleID: a.ID,
+		Title:     a.Title,
+		URL:       a.URL,
+		ReaderURL: n.baseURL + "/read/" + strconv.FormatInt(a.ID, 10),
+		FeedName:  a.FeedName,
+		Score:     a.QualityRank,
+		Summary:   a.Summary,
+		Tags:      tags,
+	}
+}
+
+// Send posts msg to ch, retrying failures other than rejected requests.
+func (n *Notifier) Send(ctx context.Context, ch core.NotificationChannel, msg Message) error {
+	adapter, ok := Adapters[ch.Type]
+	if !ok {
+		return fmt.Errorf("unknown channel type %q", ch.Type)
+	}
+
+	var rejected error
+	err := retry.Do(ctx, maxAttempts, func() error {
+		err := adapter.Send(ctx, n.client, ch, msg)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.Permanent() {
+			rejected = err
+			return nil
+		}
+		return err
+	})
+	if rejected != nil {
+		return rejected
+	}
+	return err
+}
+
+// SendTest posts a sample message to ch, ignoring its filters but not its
+// rate limit. It makes a single attempt, so the caller hears about a
+// problem straight away.
+func (n *Notifier) SendTest(ctx context.Context, ch core.NotificationChannel) error {
+	adapter, ok := Adapters[ch.Type]
+	if !ok {
+		return fmt.Errorf("unknown channel type %q", ch.Type)
+	}
+	if !n.limiter.allow(ch.ID, ch.RateLimit, time.Now()) {
+		return ErrRateLimited
+	}
+	return adapter.Send(ctx, n.client, ch, Message{
+		Title:     "Test notification from The Daily Synapse",
+		URL:       n.baseURL + "/",
+		ReaderURL: n.baseURL + "/",
+		FeedName:  "The Daily Synapse",
+		Score:     100,
+		Summary:   fmt.Sprintf("If you can read this, %q is set up. Articles scoring %d or more will show up here.", ch.Name, ch.MinScore),
+		Tags:      ch.Tags,
+	})
+}
+
+// StatusError is a non-2xx response from a chat service.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "service responded " + e.Status
+}
+
+// Permanent reports whether retrying cannot help: a 4xx other than 408 or
+// 429 means the service rejected the request.
+func (e *StatusError) Permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusRequestTimeout && e.StatusCode != http.StatusTooManyRequests
+}
+
+// send makes one JSON request and checks the response status.
+func send(ctx context.Context, client *http.Client, method, target string, header http.Header, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Drop the URL: for Slack and Discord it is the credential.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return nil
+}
+
+// truncate shortens s to at most n runes, ending with an ellipsis when cut.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// limiter is a token bucket per channel, refilled at the channel's hourly
+// rate and holding at most an hour's worth of messages.
+type limiter struct {
+	mu      sync.Mutex
+	buckets map[int64]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{buckets: make(map[int64]*bucket)}
+}
+
+func (l *limiter) allow(id int64, perHour int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := float64(perHour)
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[id] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Hours()*capacity)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,367 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+)
+
+type fakeStore struct {
+	channels []core.NotificationChannel
+	articles map[int64]core.Article
+	tags     map[int64][]string
+}
+
+func (s *fakeStore) GetNotificationChannels(ctx context.Context) ([]core.NotificationChannel, error) {
+	return append([]core.NotificationChannel(nil), s.channels...), nil
+}
+
+func (s *fakeStore) GetArticleByID(ctx context.Context, id int64) (*core.Article, error) {
+	a, ok := s.articles[id]
+	if !ok {
+		return nil, core.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (s *fakeStore) GetArticleTags(ctx context.Context, id int64) ([]string, error) {
+	return s.tags[id], nil
+}
+
+// request is what a stand-in chat service received.
+type request struct {
+	method string
+	path   string
+	header http.Header
+	body   map[string]any
+}
+
+// newService is an httptest stand-in for a chat service that answers with
+// the queued statuses, then 200, and reports every request.
+func newService(t *testing.T, statuses ...int) (*httptest.Server, chan request) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := make(chan request, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding message: %v", err)
+		}
+		requests <- request{r.Method, r.URL.EscapedPath(), r.Header, body}
+
+		mu.Lock()
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func newTestNotifier(store Store) *Notifier {
+	cfg := &config.Config{NotifyTimeout: time.Second, PublicURL: "https://synapse.example.com"}
+	return NewNotifier(store, events.New(), cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+var testMessage = Message{
+	ArticleID: 7,
+	Title:     "Go <generics> & you",
+	URL:       "https://go.dev/blog/generics",
+	ReaderURL: "https://synapse.example.com/read/7",
+	FeedName:  "Go Blog",
+	Score:     94,
+	Summary:   "A deep dive into type parameters.",
+	Tags:      []string{"Go", "Generics"},
+}
+
+func TestValidate(t *testing.T) {
+	valid := core.NotificationChannel{Name: "Team", Type: core.ChannelSlack, URL: "https://hooks.slack.com/services/T/B/X", MinScore: 90, RateLimit: 10}
+	tests := []struct {
+		name   string
+		modify func(*core.NotificationChannel)
+		ok     bool
+	}{
+		{"valid", func(*core.NotificationChannel) {}, true},
+		{"no name", func(c *core.NotificationChannel) { c.Name = " " }, false},
+		{"unknown type", func(c *core.NotificationChannel) { c.Type = "irc" }, false},
+		{"relative url", func(c *core.NotificationChannel) { c.URL = "/hooks" }, false},
+		{"matrix without token", func(c *core.NotificationChannel) { c.Type, c.RoomID = core.ChannelMatrix, "!r:example.com" }, false},
+		{"matrix", func(c *core.NotificationChannel) {
+			c.Type, c.RoomID, c.Token = core.ChannelMatrix, "!r:example.com", "t"
+		}, true},
+		{"score too high", func(c *core.NotificationChannel) { c.MinScore = 101 }, false},
+		{"no rate limit", func(c *core.NotificationChannel) { c.RateLimit = 0 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := valid
+			tt.modify(&ch)
+			err := Validate(ch)
+			if tt.ok && err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+			if !tt.ok && !errors.Is(err, core.ErrBadRequest) {
+				t.Errorf("Validate() error = %v, want ErrBadRequest", err)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	article := core.Article{FeedID: 3, QualityRank: 92}
+	tags := []string{"Go", "Databases"}
+	tests := []struct {
+		name string
+		ch   core.NotificationChannel
+		want bool
+	}{
+		{"score", core.NotificationChannel{MinScore: 90}, true},
+		{"score too low", core.NotificationChannel{MinScore: 95}, false},
+		{"tag", core.NotificationChannel{MinScore: 90, Tags: []string{"rust", "go"}}, true},
+		{"no tag", core.NotificationChannel{MinScore: 90, Tags: []string{"Rust"}}, false},
+		{"feed", core.NotificationChannel{MinScore: 90, FeedIDs: []int64{1, 3}}, true},
+		{"other feed", core.NotificationChannel{MinScore: 90, FeedIDs: []int64{1}}, false},
+		{"feed and no tag", core.NotificationChannel{MinScore: 90, FeedIDs: []int64{3}, Tags: []string{"Rust"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.ch, article, tags); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter()
+	now := time.Now()
+	for i := range 3 {
+		if !l.allow(1, 3, now) {
+			t.Fatalf("allow() #%d = false, want true", i+1)
+		}
+	}
+	if l.allow(1, 3, now) {
+		t.Error("allow() over the limit = true, want false")
+	}
+	if !l.allow(2, 3, now) {
+		t.Error("allow() for another channel = false, want true")
+	}
+	if l.allow(1, 3, now.Add(10*time.Minute)) {
+		t.Error("allow() after 10 minutes = true, want false until a third of an hour passes")
+	}
+	if !l.allow(1, 3, now.Add(21*time.Minute)) {
+		t.Error("allow() after 21 minutes = false, want true")
+	}
+}
+
+func TestSlack(t *testing.T) {
+	srv, requests := newService(t)
+	ch := core.NotificationChannel{ID: 1, Type: core.ChannelSlack, URL: srv.URL + "/services/T/B/X"}
+	if err := (Slack{}).Send(context.Background(), srv.Client(), ch, testMessage); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	req := <-requests
+	if req.method != http.MethodPost || req.path != "/services/T/B/X" {
+		t.Errorf("request = %s %s, want POST /services/T/B/X", req.method, req.path)
+	}
+	if text, _ := req.body["text"].(string); !strings.Contains(text, "94") {
+		t.Errorf("fallback text = %q, want the score", text)
+	}
+	var blocks strings.Builder
+	enc := json.NewEncoder(&blocks)
+	enc.SetEscapeHTML(false)
+	enc.Encode(req.body["blocks"])
+	for _, want := range []string{
+		"<https://synapse.example.com/read/7|Go &lt;generics&gt; &amp; you>",
+		"score *94*",
+		"A deep dive into type parameters.",
+		"Go, Generics",
+		"<https://go.dev/blog/generics|Original article>",
+	} {
+		if !strings.Contains(blocks.String(), want) {
+			t.Errorf("blocks = %s, want %q", blocks.String(), want)
+		}
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	srv, requests := newService(t)
+	ch := core.NotificationChannel{ID: 1, Type: core.ChannelDiscord, URL: srv.URL + "/api/webhooks/1/token"}
+	if err := (Discord{}).Send(context.Background(), srv.Client(), ch, testMessage); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	req := <-requests
+	if req.method != http.MethodPost || req.path != "/api/webhooks/1/token" {
+		t.Errorf("request = %s %s, want POST /api/webhooks/1/token", req.method, req.path)
+	}
+	var payload struct {
+		Embeds []struct {
+			Title       string
+			URL         string
+			Description string
+			Fields      []struct{ Name, Value string }
+		}
+		AllowedMentions struct {
+			Parse []string
+		} `json:"allowed_mentions"`
+	}
+	b, _ := json.Marshal(req.body)
+	json.Unmarshal(b, &payload)
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("embeds = %s, want one", b)
+	}
+	embed := payload.Embeds[0]
+	if embed.Title != testMessage.Title || embed.URL != testMessage.ReaderURL || embed.Description != testMessage.Summary {
+		t.Errorf("embed = %+v, want the title linking to the reader page and the summary", embed)
+	}
+	fields := map[string]string{}
+	for _, f := range embed.Fields {
+		fields[f.Name] = f.Value
+	}
+	if fields["Score"] != "94" || fields["Tags"] != "Go, Generics" || fields["Original"] != testMessage.URL {
+		t.Errorf("fields = %v, want score, tags and original link", fields)
+	}
+	if payload.AllowedMentions.Parse == nil || len(payload.AllowedMentions.Parse) != 0 {
+		t.Errorf("allowed_mentions = %s, want mentions disabled", b)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	srv, requests := newService(t)
+	ch := core.NotificationChannel{ID: 1, Type: core.ChannelMatrix, URL: srv.URL + "/", RoomID: "!room:example.com", Token: "syt_token"}
+	for range 2 {
+		if err := (Matrix{}).Send(context.Background(), srv.Client(), ch, testMessage); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	first, second := <-requests, <-requests
+	prefix := "/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/"
+	if first.method != http.MethodPut || !strings.HasPrefix(first.path, prefix) {
+		t.Errorf("request = %s %s, want PUT %s{txn}", first.method, first.path, prefix)
+	}
+	if first.path == second.path {
+		t.Errorf("both messages used transaction %s, want a fresh one each", first.path)
+	}
+	if got := first.header.Get("Authorization"); got != "Bearer syt_token" {
+		t.Errorf("Authorization = %q, want the access token", got)
+	}
+	if first.body["msgtype"] != "m.notice" || first.body["format"] != "org.matrix.custom.html" {
+		t.Errorf("body = %v, want an HTML m.notice", first.body)
+	}
+	body, _ := first.body["body"].(string)
+	formatted, _ := first.body["formatted_body"].(string)
+	if !strings.Contains(body, "Go <generics> & you (94)") || !strings.Contains(body, testMessage.ReaderURL) {
+		t.Errorf("body = %q, want the title, score and reader link", body)
+	}
+	if !strings.Contains(formatted, `<a href="https://synapse.example.com/read/7">Go &lt;generics&gt; &amp; you</a>`) {
+		t.Errorf("formatted_body = %q, want an escaped link to the reader page", formatted)
+	}
+}
+
+func TestSend_Retries(t *testing.T) {
+	n := newTestNotifier(&fakeStore{})
+
+	srv, requests := newService(t, http.StatusBadGateway)
+	ch := core.NotificationChannel{ID: 1, Type: core.ChannelSlack, URL: srv.URL}
+	if err := n.Send(context.Background(), ch, testMessage); err != nil {
+		t.Errorf("Send() after a 502 error = %v, want it retried", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("requests = %d, want 2", len(requests))
+	}
+
+	srv, requests = newService(t, http.StatusNotFound)
+	ch.URL = srv.URL
+	var statusErr *StatusError
+	if err := n.Send(context.Background(), ch, testMessage); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Send() after a 404 error = %v, want the 404", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("requests = %d, want 1: a 404 is not retried", len(requests))
+	}
+}
+
+func TestNotifier_Run(t *testing.T) {
+	slack, slackRequests := newService(t)
+	discord, discordRequests := newService(t)
+
+	store := &fakeStore{
+		channels: []core.NotificationChannel{
+			{ID: 1, Type: core.ChannelSlack, URL: slack.URL, MinScore: 90, Tags: []string{"go"}, RateLimit: 1, Enabled: true},
+			{ID: 2, Type: core.ChannelDiscord, URL: discord.URL, MinScore: 90, FeedIDs: []int64{99}, RateLimit: 10, Enabled: true},
+			{ID: 3, Type: core.ChannelDiscord, URL: discord.URL, MinScore: 0, RateLimit: 10},
+		},
+		articles: map[int64]core.Article{
+			1: {ID: 1, FeedID: 3, Title: "Great", QualityRank: 95},
+			2: {ID: 2, FeedID: 3, Title: "Also great", QualityRank: 93},
+			3: {ID: 3, FeedID: 3, Title: "Fine", QualityRank: 70},
+		},
+		tags: map[int64][]string{1: {"Go"}, 2: {"Go"}, 3: {"Go"}},
+	}
+	n := newTestNotifier(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		n.Run(ctx)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	n.bus.Publish(events.ArticleScored, events.ArticleScore{ArticleID: 1})
+	select {
+	case req := <-slackRequests:
+		if text, _ := req.body["text"].(string); text != "Great (95)" {
+			t.Errorf("Slack message = %q, want article 1", text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the Slack message")
+	}
+
+	// Article 2 is over the Slack channel's limit of one per hour, article 3
+	// scores too low, and the Discord channels are filtered or disabled.
+	n.notify(ctx, 2)
+	n.notify(ctx, 3)
+	n.wg.Wait()
+	if len(slackRequests) != 0 || len(discordRequests) != 0 {
+		t.Errorf("got %d more Slack and %d Discord messages, want none", len(slackRequests), len(discordRequests))
+	}
+}
+
+func TestSendTest(t *testing.T) {
+	srv, requests := newService(t)
+	n := newTestNotifier(&fakeStore{})
+	ch := core.NotificationChannel{ID: 1, Name: "Team", Type: core.ChannelSlack, URL: srv.URL, MinScore: 99, Tags: []string{"Rust"}, RateLimit: 1}
+
+	if err := n.SendTest(context.Background(), ch); err != nil {
+		t.Fatalf("SendTest() error = %v", err)
+	}
+	req := <-requests
+	if text, _ := req.body["text"].(string); !strings.Contains(text, "Test notification") {
+		t.Errorf("text = %q, want a test message", text)
+	}
+	if err := n.SendTest(context.Background(), ch); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("SendTest() over the limit error = %v, want ErrRateLimited", err)
+	}
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Slack posts to an incoming webhook using Block Kit, with a plain-text
+// fallback for notifications.
+type Slack struct{}
+
+func (Slack) Send(ctx context.Context, client *http.Client, ch core.NotificationChannel, msg Message) error {
+	return send(ctx, client, http.MethodPost, ch.URL, nil, slackPayload(msg))
+}
+
+func slackPayload(msg Message) map[string]any {
+	headline := fmt.Sprintf("*<%s|%s>*  ·  score *%d*", msg.ReaderURL, slackEscape(msg.Title), msg.Score)
+	if msg.FeedName != "" {
+		headline += "\n_" + slackEscape(msg.FeedName) + "_"
+	}
+	blocks := []map[string]any{
+		{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": headline}},
+	}
+	if msg.Summary != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": slackEscape(truncate(msg.Summary, 2900))},
+		})
+	}
+	context := []map[string]any{
+		{"type": "mrkdwn", "text": fmt.Sprintf("<%s|Original article>", msg.URL)},
+	}
+	if len(msg.Tags) > 0 {
+		context = append(context, map[string]any{"type": "mrkdwn", "text": slackEscape(strings.Join(msg.Tags, ", "))})
+	}
+	blocks = append(blocks, map[string]any{"type": "context", "elements": context})
+
+	return map[string]any{
+		"text":   fmt.Sprintf("%s (%d)", msg.Title, msg.Score),
+		"blocks": blocks,
+	}
+}
+
+// slackEscape escapes the characters Slack treats as markup in mrkdwn.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,217 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const (
+	FieldTitle      = "title"
+	FieldURL        = "url"
+	FieldAuthor     = "author"
+	FieldCategories = "categories"
+	FieldContent    = "content"
+	FieldAny        = "any"
+
+	MatchSubstring = "substring"
+	MatchRegex     = "regex"
+	MatchLanguage  = "language"
+
+	ActionDrop   = "drop"
+	ActionAccept = "accept"
+	ActionTag    = "tag"
+
+	// DefaultAcceptScore is used when an accept rule doesn't set a score.
+	DefaultAcceptScore = 75
+)
+
+// Item is the subset of a feed item that rules can match against.
+type Item struct {
+	Title      string
+	URL        string
+	Authors    []string
+	Categories []string
+	Content    string
+	Language   string
+}
+
+// Decision is the combined outcome of every rule that matched an item.
+type Decision struct {
+	Drop        bool
+	Accept      bool
+	AcceptScore int
+	Tags        []string
+	Matched     []int64
+}
+
+type compiled struct {
+	rule core.IngestRule
+	re   *regexp.Regexp
+}
+
+type Engine struct {
+	rules []compiled
+}
+
+// Validate reports whether a rule is well formed, compiling regex patterns.
+func Validate(rule core.IngestRule) error {
+	switch rule.Field {
+	case FieldTitle, FieldURL, FieldAuthor, FieldCategories, FieldContent, FieldAny:
+	default:
+		return fmt.Errorf("%w: unknown field %q", core.ErrBadRequest, rule.Field)
+	}
+
+	switch rule.MatchType {
+	case MatchSubstring, MatchLanguage:
+	case MatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("%w: invalid regex: %v", core.ErrBadRequest, err)
+		}
+	default:
+		return fmt.Errorf("%w: unknown match type %q", core.ErrBadRequest, rule.MatchType)
+	}
+
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("%w: pattern is required", core.ErrBadRequest)
+	}
+
+	switch rule.Action {
+	case ActionDrop:
+	case ActionAccept:
+		if rule.Score < 0 || rule.Score > 100 {
+			return fmt.Errorf("%w: score must be between 0 and 100", core.ErrBadRequest)
+		}
+	case ActionTag:
+		if len(rule.Tags) == 0 {
+			return fmt.Errorf("%w: tag action requires tags", core.ErrBadRequest)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", core.ErrBadRequest, rule.Action)
+	}
+
+	return nil
+}
+
+// New compiles the enabled rules. Invalid rules are skipped rather than
+// failing the whole sync.
+func New(rules []core.IngestRule) *Engine {
+	e := &Engine{}
+	for _, r := range rules {
+		if !r.Enabled || Validate(r) != nil {
+			continue
+		}
+		c := compiled{rule: r}
+		if r.MatchType == MatchRegex {
+			c.re = regexp.MustCompile(r.Pattern)
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e
+}
+
+func (e *Engine) Evaluate(item Item) Decision {
+	var d Decision
+	seen := make(map[string]bool)
+
+	for _, c := range e.rules {
+		if !c.matches(item) {
+			continue
+		}
+		d.Matched = append(d.Matched, c.rule.ID)
+
+		switch c.rule.Action {
+		case ActionDrop:
+			d.Drop = true
+		case ActionAccept:
+			score := c.rule.Score
+			if score == 0 {
+				score = DefaultAcceptScore
+			}
+			d.Accept = true
+			if score > d.AcceptScore {
+				d.AcceptScore = score
+			}
+		case ActionTag:
+			for _, t := range c.rule.Tags {
+				if !seen[t] {
+					seen[t] = true
+					d.Tags = append(d.Tags, t)
+				}
+			}
+		}
+	}
+
+	// Dropping wins over everything else.
+	if d.Drop {
+		d.Accept = false
+		d.AcceptScore = 0
+		d.Tags = nil
+	}
+	return d
+}
+
+func (c compiled) matches(item Item) bool {
+	if c.rule.MatchType == MatchLanguage {
+		return matchLanguage(item.Language, c.rule.Pattern)
+	}
+
+	for _, value := range fieldValues(item, c.rule.Field) {
+		if c.re != nil {
+			if c.re.MatchString(value) {
+				return true
+			}
+			continue
+		}
+		if strings.Contains(strings.ToLower(value), strings.ToLower(c.rule.Pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValues(item Item, field string) []string {
+	switch field {
+	case FieldTitle:
+		return []string{item.Title}
+	case FieldURL:
+		return []string{item.URL}
+	case FieldAuthor:
+		return item.Authors
+	case FieldCategories:
+		return item.Categories
+	case FieldContent:
+		return []string{item.Content}
+	case FieldAny:
+		values := []string{item.Title, item.URL, item.Content}
+		values = append(values, item.Authors...)
+		return append(values, item.Categories...)
+	}
+	return nil
+}
+
+// matchLanguage compares BCP 47 primary subtags, so "en" matches "en-US".
+// A pattern starting with "!" matches any other language.
+func matchLanguage(lang, pattern string) bool {
+	negate := strings.HasPrefix(pattern, "!")
+	pattern = strings.TrimPrefix(pattern, "!")
+
+	primary := func(s string) string {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if i := strings.IndexAny(s, "-_"); i >= 0 {
+			s = s[:i]
+		}
+		return s
+	}
+
+	if lang == "" {
+		return false
+	}
+	matched := primary(lang) == primary(pattern)
+	if negate {
+		return !matched
+	}
+	return matched
+}
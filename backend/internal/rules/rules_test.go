@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestEvaluate(t *testing.T) {
+	engine := New([]core.IngestRule{
+		{ID: 1, Field: FieldTitle, MatchType: MatchSubstring, Pattern: "sponsored:", Action: ActionDrop, Enabled: true},
+		{ID: 2, Field: FieldURL, MatchType: MatchRegex, Pattern: `/jobs?/`, Action: ActionDrop, Enabled: true},
+		{ID: 3, Field: FieldCategories, MatchType: MatchSubstring, Pattern: "postgres", Action: ActionTag, Tags: []string{"Databases"}, Enabled: true},
+		{ID: 4, Field: FieldAuthor, MatchType: MatchSubstring, Pattern: "Dan Luu", Action: ActionAccept, Score: 90, Enabled: true},
+		{ID: 5, Field: FieldTitle, MatchType: MatchSubstring, Pattern: "anything", Action: ActionDrop, Enabled: false},
+		{ID: 6, Field: FieldAny, MatchType: MatchLanguage, Pattern: "!en", Action: ActionDrop, Enabled: true},
+	})
+
+	tests := []struct {
+		name       string
+		item       Item
+		wantDrop   bool
+		wantAccept bool
+		wantScore  int
+		wantTags   []string
+		wantIDs    []int64
+	}{
+		{
+			name:     "case insensitive substring drop",
+			item:     Item{Title: "SPONSORED: Buy our database", Language: "en"},
+			wantDrop: true,
+			wantIDs:  []int64{1},
+		},
+		{
+			name:     "regex on url",
+			item:     Item{Title: "We're hiring", URL: "https://example.com/jobs/123", Language: "en"},
+			wantDrop: true,
+			wantIDs:  []int64{2},
+		},
+		{
+			name:     "tag from categories",
+			item:     Item{Title: "MVCC internals", Categories: []string{"PostgreSQL"}, Language: "en-US"},
+			wantTags: []string{"Databases"},
+			wantIDs:  []int64{3},
+		},
+		{
+			name:       "accept with tag",
+			item:       Item{Title: "Latency", Authors: []string{"Dan Luu"}, Categories: []string{"postgres"}},
+			wantAccept: true,
+			wantScore:  90,
+			wantTags:   []string{"Databases"},
+			wantIDs:    []int64{3, 4},
+		},
+		{
+			name:     "drop wins over accept",
+			item:     Item{Title: "Sponsored: latency", Authors: []string{"Dan Luu"}},
+			wantDrop: true,
+			wantIDs:  []int64{1, 4},
+		},
+		{
+			name:     "language mismatch",
+			item:     Item{Title: "Über Datenbanken", Language: "de"},
+			wantDrop: true,
+			wantIDs:  []int64{6},
+		},
+		{
+			name: "disabled rule ignored",
+			item: Item{Title: "anything goes", Language: "en"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := engine.Evaluate(tt.item)
+			if d.Drop != tt.wantDrop {
+				t.Errorf("Drop = %v, want %v", d.Drop, tt.wantDrop)
+			}
+			if d.Accept != tt.wantAccept {
+				t.Errorf("Accept = %v, want %v", d.Accept, tt.wantAccept)
+			}
+			if d.AcceptScore != tt.wantScore {
+				t.Errorf("AcceptScore = %v, want %v", d.AcceptScore, tt.wantScore)
+			}
+			if len(d.Tags) != len(tt.wantTags) {
+				t.Errorf("Tags = %v, want %v", d.Tags, tt.wantTags)
+			}
+			if len(d.Matched) != len(tt.wantIDs) {
+				t.Fatalf("Matched = %v, want %v", d.Matched, tt.wantIDs)
+			}
+			for i := range d.Matched {
+				if d.Matched[i] != tt.wantIDs[i] {
+					t.Errorf("Matched = %v, want %v", d.Matched, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    core.IngestRule
+		wantErr bool
+	}{
+		{"valid", core.IngestRule{Field: FieldTitle, MatchType: MatchSubstring, Pattern: "x", Action: ActionDrop}, false},
+		{"bad field", core.IngestRule{Field: "body", MatchType: MatchSubstring, Pattern: "x", Action: ActionDrop}, true},
+		{"bad regex", core.IngestRule{Field: FieldTitle, MatchType: MatchRegex, Pattern: "(", Action: ActionDrop}, true},
+		{"empty pattern", core.IngestRule{Field: FieldTitle, MatchType: MatchSubstring, Pattern: " ", Action: ActionDrop}, true},
+		{"tag without tags", core.IngestRule{Field: FieldTitle, MatchType: MatchSubstring, Pattern: "x", Action: ActionTag}, true},
+		{"score out of range", core.IngestRule{Field: FieldTitle, MatchType: MatchSubstring, Pattern: "x", Action: ActionAccept, Score: 101}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, core.ErrBadRequest) {
+				t.Errorf("Validate() error = %v, want ErrBadRequest", err)
+			}
+		})
+	}
+}
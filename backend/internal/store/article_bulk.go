@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// BulkUpdateArticles applies action to every article filter matches in one
+// transaction and returns the IDs of the articles that changed. tags is only
+// used by core.BulkTag, which pins them like manually added tags.
+func (q *Queries) BulkUpdateArticles(ctx context.Context, filter core.ArticleFilter, action core.BulkAction, tags []string) ([]int64, error) {
+	where, args := filterClause(filter)
+	matching := `SELECT a.id FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		LEFT JOIN categories c ON f.category_id = c.id
+		WHERE ` + where
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var update string
+	switch action {
+	case core.BulkRead:
+		update = `UPDATE articles SET is_read = TRUE WHERE is_read = FALSE AND id IN (` + matching + `)`
+	case core.BulkUnread:
+		update = `UPDATE articles SET is_read = FALSE WHERE is_read = TRUE AND id IN (` + matching + `)`
+	case core.BulkSave:
+		update = `UPDATE articles SET read_later = TRUE WHERE read_later = FALSE AND id IN (` + matching + `)`
+	case core.BulkUnsave:
+		update = `UPDATE articles SET read_later = FALSE WHERE read_later = TRUE AND id IN (` + matching + `)`
+	case core.BulkDismiss:
+		update = `UPDATE articles SET deleted_at = ? WHERE deleted_at IS NULL AND id IN (` + matching + `)`
+		args = append([]any{time.Now()}, args...)
+	case core.BulkTag:
+		return bulkTag(ctx, tx, matching, args, tags)
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", core.ErrBadRequest, action)
+	}
+
+	ids, err := queryIDs(ctx, tx, update+` RETURNING id`, args)
+	if err != nil {
+		return nil, fmt.Errorf("applying %s: %w", action, err)
+	}
+	return ids, tx.Commit()
+}
+
+func bulkTag(ctx context.Context, tx *sql.Tx, matching string, args []any, tags []string) ([]int64, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%w: tag needs at least one tag", core.ErrBadRequest)
+	}
+
+	ids, err := queryIDs(ctx, tx, matching, args)
+	if err != nil {
+		return nil, fmt.Errorf("selecting articles: %w", err)
+	}
+	for _, id := range ids {
+		if err := linkTags(ctx, tx, id, tags, true); err != nil {
+			return nil, err
+		}
+	}
+	return ids, tx.Commit()
+}
+
+// queryIDs runs a query returning one id column and collects the ids.
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args []any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestBulkUpdateArticles(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	other, err := q.CreateFeed(ctx, "https://example.com/other", "Other Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	var ids []int64
+	for i, feedID := range []int64{feed.ID, feed.ID, feed.ID, other.ID} {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID:      feedID,
+			Title:       fmt.Sprintf("Article %d", i),
+			URL:         fmt.Sprintf("https://example.com/%d", i),
+			PublishedAt: time.Now().Add(-time.Duration(i) * 72 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, 60+10*i, "", "", "test", nil); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	unread := false
+	count := func(filter core.ArticleFilter) int {
+		t.Helper()
+		page, err := q.ListArticles(ctx, filter, "", 100)
+		if err != nil {
+			t.Fatalf("ListArticles() error = %v", err)
+		}
+		return len(page.Articles)
+	}
+
+	changed, err := q.BulkUpdateArticles(ctx, core.ArticleFilter{FeedID: feed.ID}, core.BulkRead, nil)
+	if err != nil {
+		t.Fatalf("BulkUpdateArticles(read) error = %v", err)
+	}
+	if len(changed) != 3 {
+		t.Errorf("BulkUpdateArticles(read) = %v, want 3 ids", changed)
+	}
+	if got := count(core.ArticleFilter{Read: &unread}); got != 1 {
+		t.Errorf("unread articles after bulk read = %d, want 1", got)
+	}
+
+	// Already-read articles are not counted again.
+	if changed, _ := q.BulkUpdateArticles(ctx, core.ArticleFilter{IDs: ids[:2]}, core.BulkRead, nil); len(changed) != 0 {
+		t.Errorf("BulkUpdateArticles(read) on read articles = %v, want none", changed)
+	}
+
+	changed, err = q.BulkUpdateArticles(ctx, core.ArticleFilter{Until: time.Now().Add(-24 * time.Hour), MaxScore: 80}, core.BulkSave, nil)
+	if err != nil {
+		t.Fatalf("BulkUpdateArticles(save) error = %v", err)
+	}
+	if len(changed) != 2 {
+		t.Errorf("BulkUpdateArticles(save) older than a day with score <= 80 = %v, want 2 ids", changed)
+	}
+
+	changed, err = q.BulkUpdateArticles(ctx, core.ArticleFilter{IDs: ids[2:]}, core.BulkTag, []string{"Backlog"})
+	if err != nil {
+		t.Fatalf("BulkUpdateArticles(tag) error = %v", err)
+	}
+	slices.Sort(changed)
+	if !slices.Equal(changed, ids[2:]) {
+		t.Errorf("BulkUpdateArticles(tag) = %v, want %v", changed, ids[2:])
+	}
+	if got := count(core.ArticleFilter{Tags: []string{"Backlog"}}); got != 2 {
+		t.Errorf("articles tagged Backlog = %d, want 2", got)
+	}
+	if _, err := q.BulkUpdateArticles(ctx, core.ArticleFilter{}, core.BulkTag, nil); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("BulkUpdateArticles(tag) without tags error = %v, want ErrBadRequest", err)
+	}
+
+	changed, err = q.BulkUpdateArticles(ctx, core.ArticleFilter{IDs: ids[:1]}, core.BulkDismiss, nil)
+	if err != nil {
+		t.Fatalf("BulkUpdateArticles(dismiss) error = %v", err)
+	}
+	if !slices.Equal(changed, ids[:1]) {
+		t.Errorf("BulkUpdateArticles(dismiss) = %v, want %v", changed, ids[:1])
+	}
+	if got := count(core.ArticleFilter{}); got != 3 {
+		t.Errorf("articles after dismiss = %d, want 3", got)
+	}
+	if err := q.RestoreArticle(ctx, ids[0]); err != nil {
+		t.Errorf("RestoreArticle() after bulk dismiss error = %v", err)
+	}
+
+	if _, err := q.BulkUpdateArticles(ctx, core.ArticleFilter{}, core.BulkAction("explode"), nil); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("BulkUpdateArticles(unknown) error = %v, want ErrBadRequest", err)
+	}
+}
@@ -0,0 +1,288 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// listCursor is the position after the last row of a page. PublishedAt
+// holds the column's stored text so comparisons match ORDER BY exactly.
+type listCursor struct {
+	Sort        core.ArticleSort `json:"s"`
+	IsRead      bool             `json:"r,omitempty"`
+	Score       int              `json:"q,omitempty"`
+	PublishedAt string           `json:"p"`
+	ID          int64            `json:"i"`
+}
+
+func encodeCursor(c listCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string, sort core.ArticleSort) (listCursor, error) {
+	var c listCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", core.ErrBadRequest)
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, fmt.Errorf("%w: malformed cursor", core.ErrBadRequest)
+	}
+	if c.Sort != sort {
+		return c, fmt.Errorf("%w: cursor was issued for sort %q", core.ErrBadRequest, c.Sort)
+	}
+	return c, nil
+}
+
+// sortKey is one ORDER BY term, all of them descending except is_read.
+type sortKey struct {
+	expr string
+	asc  bool
+	val  func(listCursor) any
+}
+
+var (
+	readKey      = sortKey{"a.is_read", true, func(c listCursor) any { return c.IsRead }}
+	scoreKey     = sortKey{"COALESCE(a.quality_rank, 0)", false, func(c listCursor) any { return c.Score }}
+	publishedKey = sortKey{"a.published_at", false, func(c listCursor) any { return c.PublishedAt }}
+	idKey        = sortKey{"a.id", false, func(c listCursor) any { return c.ID }}
+
+	// rankKey is the score plus followBoost for articles with a followed tag.
+	rankKey = sortKey{fmt.Sprintf(`COALESCE(a.quality_rank, 0) + CASE WHEN EXISTS (
+			SELECT 1 FROM article_tags at
+			JOIN tags t ON at.tag_id = t.id
+			JOIN tag_preferences p ON p.tag = t.name
+			WHERE at.article_id = a.id AND p.muted = FALSE) THEN %d ELSE 0 END`, followBoost),
+		false, func(c listCursor) any { return c.Score }}
+)
+
+func sortKeys(sort core.ArticleSort) ([]sortKey, error) {
+	switch sort {
+	case core.SortPersonalized:
+		return []sortKey{readKey, rankKey, publishedKey, idKey}, nil
+	case core.SortScore:
+		return []sortKey{scoreKey, publishedKey, idKey}, nil
+	case core.SortDate:
+		return []sortKey{publishedKey, idKey}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown sort %q", core.ErrBadRequest, sort)
+}
+
+// seekAfter builds the keyset condition for rows ordered after the cursor:
+// k1 past v1 OR (k1 = v1 AND (k2 past v2 OR ...)).
+func seekAfter(keys []sortKey, c listCursor) (string, []any) {
+	k := keys[0]
+	op := "<"
+	if k.asc {
+		op = ">"
+	}
+	cond := fmt.Sprintf("%s %s ?", k.expr, op)
+	args := []any{k.val(c)}
+	if len(keys) == 1 {
+		return cond, args
+	}
+	rest, restArgs := seekAfter(keys[1:], c)
+	args = append(args, k.val(c))
+	args = append(args, restArgs...)
+	return fmt.Sprintf("(%s OR (%s = ? AND %s))", cond, k.expr, rest), args
+}
+
+// filterClause turns an ArticleFilter into a WHERE clause over articles a,
+// feeds f and categories c.
+func filterClause(filter core.ArticleFilter) (string, []any) {
+	conds := []string{"a.deleted_at IS NULL", "f.status != 'pending_deletion'"}
+	var args []any
+	if !filter.AllScores {
+		conds = append(conds, "a.quality_rank IS NOT NULL", "a.quality_rank >= COALESCE(c.min_score, 0)")
+	}
+	if len(filter.IDs) > 0 {
+		conds = append(conds, "a.id IN ("+placeholders(len(filter.IDs))+")")
+		for _, id := range filter.IDs {
+			args = append(args, id)
+		}
+	}
+	if filter.FeedID != 0 {
+		conds = append(conds, "a.feed_id = ?")
+		args = append(args, filter.FeedID)
+	}
+	if filter.CategoryID != 0 {
+		conds = append(conds, "f.category_id = ?")
+		args = append(args, filter.CategoryID)
+	}
+	anyTags, allTags := filter.Tags, filter.RequireTags
+	if filter.MatchAllTags {
+		anyTags, allTags = nil, append(slices.Clone(allTags), filter.Tags...)
+	}
+	if len(anyTags) > 0 {
+		conds = append(conds, `a.id IN (
+			SELECT at.article_id FROM article_tags at
+			JOIN tags t ON at.tag_id = t.id
+			WHERE t.name IN (`+placeholders(len(anyTags))+`))`)
+		args = appendStrings(args, anyTags)
+	}
+	if allTags = uniqueStrings(allTags); len(allTags) > 0 {
+		conds = append(conds, `a.id IN (
+			SELECT at.article_id FROM article_tags at
+			JOIN tags t ON at.tag_id = t.id
+			WHERE t.name IN (`+placeholders(len(allTags))+`)
+			GROUP BY at.article_id HAVING COUNT(*) = ?)`)
+		args = append(appendStrings(args, allTags), len(allTags))
+	}
+	if len(filter.ExcludeTags) > 0 {
+		conds = append(conds, `NOT EXISTS (
+			SELECT 1 FROM article_tags at
+			JOIN tags t ON at.tag_id = t.id
+			WHERE at.article_id = a.id AND t.name IN (`+placeholders(len(filter.ExcludeTags))+`))`)
+		args = appendStrings(args, filter.ExcludeTags)
+	}
+	if filter.HideMuted {
+		mute := `NOT EXISTS (
+			SELECT 1 FROM article_tags at
+			JOIN tags t ON at.tag_id = t.id
+			JOIN tag_preferences p ON p.tag = t.name
+			WHERE at.article_id = a.id AND p.muted = TRUE
+			AND (p.override_score = 0 OR COALESCE(a.quality_rank, 0) < p.override_score)`
+		if named := append(slices.Clone(filter.Tags), filter.RequireTags...); len(named) > 0 {
+			mute += ` AND t.name NOT IN (` + placeholders(len(named)) + `)`
+			args = appendStrings(args, named)
+		}
+		conds = append(conds, mute+")")
+	}
+	if filter.MinScore > 0 {
+		conds = append(conds, "a.quality_rank >= ?")
+		args = append(args, filter.MinScore)
+	}
+	if filter.MaxScore > 0 {
+		conds = append(conds, "a.quality_rank <= ?")
+		args = append(args, filter.MaxScore)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "a.published_at >= ?")
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "a.published_at < ?")
+		args = append(args, filter.Until)
+	}
+	if !filter.ScoredSince.IsZero() {
+		conds = append(conds, "a.scored_at >= ?")
+		args = append(args, filter.ScoredSince)
+	}
+	if filter.Read != nil {
+		conds = append(conds, "a.is_read = ?")
+		args = append(args, *filter.Read)
+	}
+	if filter.Saved != nil {
+		conds = append(conds, "a.read_later = ?")
+		args = append(args, *filter.Saved)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// placeholders is n comma-separated bind parameters for an IN list.
+func placeholders(n int) string {
+	return "?" + strings.Repeat(", ?", n-1)
+}
+
+func appendStrings(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+// uniqueStrings drops repeats so counting distinct matches works.
+func uniqueStrings(values []string) []string {
+	var unique []string
+	for _, v := range values {
+		if !slices.Contains(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// ListArticles returns one page of articles matching filter. cursor is the
+// NextCursor of the previous page, or empty for the first one. Paging is
+// keyset based, so rows changing between requests never shift a page.
+func (q *Queries) ListArticles(ctx context.Context, filter core.ArticleFilter, cursor string, limit int) (core.ArticlePage, error) {
+	var page core.ArticlePage
+	if filter.Sort == "" {
+		filter.Sort = core.SortPersonalized
+	}
+	keys, err := sortKeys(filter.Sort)
+	if err != nil {
+		return page, err
+	}
+
+	where, args := filterClause(filter)
+	if cursor != "" {
+		c, err := decodeCursor(cursor, filter.Sort)
+		if err != nil {
+			return page, err
+		}
+		seek, seekArgs := seekAfter(keys, c)
+		where += " AND " + seek
+		args = append(args, seekArgs...)
+	}
+
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		order[i] = k.expr + " DESC"
+		if k.asc {
+			order[i] = k.expr + " ASC"
+		}
+	}
+
+	// The cursor records the score the list is ranked by.
+	rank := scoreKey.expr
+	if filter.Sort == core.SortPersonalized {
+		rank = rankKey.expr
+	}
+
+	query := `
+		SELECT a.id, a.feed_id, a.title, a.url, a.published_at, '' || a.published_at,
+		       COALESCE(a.quality_rank, 0), ` + rank + `, COALESCE(a.summary, ''), COALESCE(a.justification, ''),
+		       f.name, a.is_read, a.read_later, a.image_url
+		FROM articles a
+		JOIN feeds f ON a.feed_id = f.id
+		LEFT JOIN categories c ON f.category_id = c.id
+		WHERE ` + where + `
+		ORDER BY ` + strings.Join(order, ", ") + `
+		LIMIT ?`
+	// One extra row tells whether another page follows.
+	rows, err := q.read.QueryContext(ctx, query, append(args, limit+1)...)
+	if err != nil {
+		return page, fmt.Errorf("querying articles: %w", err)
+	}
+	defer rows.Close()
+
+	var last listCursor
+	for rows.Next() {
+		var a core.Article
+		var publishedRaw string
+		var score int
+		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &a.PublishedAt, &publishedRaw,
+			&a.QualityRank, &score, &a.Summary, &a.Justification, &a.FeedName, &a.IsRead, &a.ReadLater, &a.ImageURL); err != nil {
+			return page, fmt.Errorf("scanning article: %w", err)
+		}
+		if len(page.Articles) == limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+		page.Articles = append(page.Articles, a)
+		last = listCursor{Sort: filter.Sort, IsRead: a.IsRead, Score: score, PublishedAt: publishedRaw, ID: a.ID}
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+	rows.Close()
+	return page, q.attachTags(ctx, page.Articles)
+}
@@ -0,0 +1,397 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestListArticles_CursorPagination(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	// Duplicate scores and timestamps make the id tie-breaker matter.
+	published := time.Now().Add(-time.Hour)
+	for i := 0; i < 7; i++ {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID:      feed.ID,
+			Title:       fmt.Sprintf("Article %d", i),
+			URL:         fmt.Sprintf("https://example.com/%d", i),
+			PublishedAt: published.Add(-time.Duration(i/2) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, 60+10*(i%3), "", "", "test", nil); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		if i == 0 {
+			if err := q.MarkArticleRead(ctx, id); err != nil {
+				t.Fatalf("MarkArticleRead() error = %v", err)
+			}
+		}
+	}
+
+	for _, sort := range []core.ArticleSort{core.SortPersonalized, core.SortScore, core.SortDate} {
+		filter := core.ArticleFilter{Sort: sort}
+		want, err := q.ListArticles(ctx, filter, "", 100)
+		if err != nil {
+			t.Fatalf("ListArticles(%s) error = %v", sort, err)
+		}
+		if len(want.Articles) != 7 || want.NextCursor != "" {
+			t.Fatalf("ListArticles(%s) = %d articles, cursor %q, want 7 and none", sort, len(want.Articles), want.NextCursor)
+		}
+
+		var got []core.Article
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 4 {
+				t.Fatalf("ListArticles(%s) did not stop paging", sort)
+			}
+			page, err := q.ListArticles(ctx, filter, cursor, 3)
+			if err != nil {
+				t.Fatalf("ListArticles(%s) error = %v", sort, err)
+			}
+			got = append(got, page.Articles...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		if len(got) != len(want.Articles) {
+			t.Fatalf("paging %s returned %d articles, want %d", sort, len(got), len(want.Articles))
+		}
+		for i := range got {
+			if got[i].ID != want.Articles[i].ID {
+				t.Errorf("paging %s [%d] = %s, want %s", sort, i, got[i].Title, want.Articles[i].Title)
+			}
+		}
+	}
+
+	if _, err := q.ListArticles(ctx, core.ArticleFilter{}, "not a cursor", 3); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("ListArticles() with a bad cursor error = %v, want ErrBadRequest", err)
+	}
+	page, _ := q.ListArticles(ctx, core.ArticleFilter{Sort: core.SortDate}, "", 3)
+	if _, err := q.ListArticles(ctx, core.ArticleFilter{Sort: core.SortScore}, page.NextCursor, 3); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("ListArticles() with another sort's cursor error = %v, want ErrBadRequest", err)
+	}
+}
+
+func TestListArticles_Filters(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	other, err := q.CreateFeed(ctx, "https://example.com/other", "Other Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	now := time.Now()
+	articles := []struct {
+		feedID int64
+		title  string
+		score  int
+		tags   []string
+		age    time.Duration
+		read   bool
+		saved  bool
+	}{
+		{feed.ID, "Go Databases", 90, []string{"Go", "Databases"}, time.Hour, false, true},
+		{feed.ID, "Go Only", 70, []string{"Go"}, 2 * time.Hour, true, false},
+		{other.ID, "Databases Only", 60, []string{"Databases"}, 50 * time.Hour, false, false},
+	}
+	for _, a := range articles {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID: a.feedID, Title: a.title, URL: "https://example.com/" + a.title, PublishedAt: now.Add(-a.age),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, a.score, "", "", "test", a.tags); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		if a.read {
+			if err := q.MarkArticleRead(ctx, id); err != nil {
+				t.Fatalf("MarkArticleRead() error = %v", err)
+			}
+		}
+		if a.saved {
+			if _, err := q.ToggleArticleSaved(ctx, id); err != nil {
+				t.Fatalf("ToggleArticleSaved() error = %v", err)
+			}
+		}
+	}
+
+	if _, err := q.db.ExecContext(ctx, `UPDATE articles SET scored_at = ? WHERE title = ?`, now.Add(-48*time.Hour), "Databases Only"); err != nil {
+		t.Fatalf("backdating scored_at: %v", err)
+	}
+
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		filter core.ArticleFilter
+		want   []string
+	}{
+		{"feed", core.ArticleFilter{FeedID: other.ID}, []string{"Databases Only"}},
+		{"any tag", core.ArticleFilter{Tags: []string{"Go", "Databases"}, Sort: core.SortScore}, []string{"Go Databases", "Go Only", "Databases Only"}},
+		{"all tags", core.ArticleFilter{Tags: []string{"Go", "Databases"}, MatchAllTags: true}, []string{"Go Databases"}},
+		{"required tags", core.ArticleFilter{RequireTags: []string{"Go", "Databases"}}, []string{"Go Databases"}},
+		{"repeated required tag", core.ArticleFilter{RequireTags: []string{"Go", "Go"}, Sort: core.SortScore}, []string{"Go Databases", "Go Only"}},
+		{"any and required tags", core.ArticleFilter{Tags: []string{"Databases"}, RequireTags: []string{"Go"}}, []string{"Go Databases"}},
+		{"excluded tag", core.ArticleFilter{ExcludeTags: []string{"Databases"}}, []string{"Go Only"}},
+		{"any tag but excluded", core.ArticleFilter{Tags: []string{"Go", "Databases"}, ExcludeTags: []string{"Go"}}, []string{"Databases Only"}},
+		{"score range", core.ArticleFilter{MinScore: 65, MaxScore: 80}, []string{"Go Only"}},
+		{"since", core.ArticleFilter{Since: now.Add(-24 * time.Hour), Sort: core.SortDate}, []string{"Go Databases", "Go Only"}},
+		{"until", core.ArticleFilter{Until: now.Add(-24 * time.Hour)}, []string{"Databases Only"}},
+		{"scored since", core.ArticleFilter{ScoredSince: now.Add(-24 * time.Hour), Sort: core.SortScore}, []string{"Go Databases", "Go Only"}},
+		{"unread", core.ArticleFilter{Read: &no, Sort: core.SortDate}, []string{"Go Databases", "Databases Only"}},
+		{"read", core.ArticleFilter{Read: &yes}, []string{"Go Only"}},
+		{"saved", core.ArticleFilter{Saved: &yes}, []string{"Go Databases"}},
+		{"personalized", core.ArticleFilter{}, []string{"Go Databases", "Databases Only", "Go Only"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := q.ListArticles(ctx, tt.filter, "", 10)
+			if err != nil {
+				t.Fatalf("ListArticles() error = %v", err)
+			}
+			var got []string
+			for _, a := range page.Articles {
+				got = append(got, a.Title)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ListArticles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRelatedTags(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	for i, tags := range [][]string{
+		{"Databases", "Postgres", "Performance"},
+		{"Databases", "Postgres", "Tutorial"},
+		{"Databases", "SQLite"},
+		{"Go", "Performance"},
+	} {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID: feed.ID, Title: fmt.Sprintf("Article %d", i), URL: fmt.Sprintf("https://example.com/%d", i), PublishedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, 80, "", "", "test", tags); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter core.ArticleFilter
+		limit  int
+		want   string
+	}{
+		{"one tag", core.ArticleFilter{RequireTags: []string{"Databases"}}, 10, "[{Postgres 2} {Performance 1} {SQLite 1} {Tutorial 1}]"},
+		{"drilled down", core.ArticleFilter{RequireTags: []string{"Databases", "Postgres"}}, 10, "[{Performance 1} {Tutorial 1}]"},
+		{"excluded tag", core.ArticleFilter{RequireTags: []string{"Databases"}, ExcludeTags: []string{"Tutorial"}}, 10, "[{Performance 1} {Postgres 1} {SQLite 1}]"},
+		{"top tags", core.ArticleFilter{}, 2, "[{Databases 3} {Performance 2}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := q.GetRelatedTags(ctx, tt.filter, tt.limit)
+			if err != nil {
+				t.Fatalf("GetRelatedTags() error = %v", err)
+			}
+			if got := fmt.Sprint(tags); got != tt.want {
+				t.Errorf("GetRelatedTags() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListArticles_Tags(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	want := map[string][]string{
+		"Tagged":   {"Databases", "Go"},
+		"Untagged": nil,
+	}
+	for title, tags := range want {
+		id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: title, URL: "https://example.com/" + title, PublishedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, 80, "", "", "test", []string{"Go", "Databases"}[:len(tags)]); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+	}
+
+	page, err := q.ListArticles(ctx, core.ArticleFilter{}, "", 10)
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	if len(page.Articles) != 2 {
+		t.Fatalf("ListArticles() = %d articles, want 2", len(page.Articles))
+	}
+	for _, a := range page.Articles {
+		if fmt.Sprint(a.Tags) != fmt.Sprint(want[a.Title]) {
+			t.Errorf("ListArticles() tags of %s = %v, want %v", a.Title, a.Tags, want[a.Title])
+		}
+	}
+}
+
+func TestListArticles_SavedUnscored(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	id, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: "Unscored", URL: "https://example.com/unscored", PublishedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	if _, err := q.ToggleArticleSaved(ctx, id); err != nil {
+		t.Fatalf("ToggleArticleSaved() error = %v", err)
+	}
+
+	saved := true
+	page, err := q.ListArticles(ctx, core.ArticleFilter{Saved: &saved, AllScores: true}, "", 10)
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	if len(page.Articles) != 1 || page.Articles[0].ID != id {
+		t.Fatalf("ListArticles() = %+v, want the unscored article", page.Articles)
+	}
+	if a := page.Articles[0]; a.Summary != "" || a.Justification != "" {
+		t.Errorf("ListArticles() summary = %q, justification = %q; want empty", a.Summary, a.Justification)
+	}
+}
+
+// BenchmarkListArticles_Tags loads the tags for a page of 50 articles out
+// of 50,000, in one query and the old way with a query per article. Page
+// times the whole ListArticles call for comparison.
+func BenchmarkListArticles_Tags(b *testing.B) {
+	q, _ := openPools(b)
+	ctx := context.Background()
+	seedArticles(b, q, 50_000)
+	page, err := q.ListArticles(ctx, core.ArticleFilter{}, "", 50)
+	if err != nil {
+		b.Fatalf("ListArticles() error = %v", err)
+	}
+
+	b.Run("Batched", func(b *testing.B) {
+		articles := make([]core.Article, len(page.Articles))
+		for b.Loop() {
+			for i, a := range page.Articles {
+				a.Tags = nil
+				articles[i] = a
+			}
+			if err := q.attachTags(ctx, articles); err != nil {
+				b.Fatalf("attachTags() error = %v", err)
+			}
+		}
+	})
+	b.Run("PerArticle", func(b *testing.B) {
+		for b.Loop() {
+			for _, a := range page.Articles {
+				if _, err := q.GetArticleTags(ctx, a.ID); err != nil {
+					b.Fatalf("GetArticleTags() error = %v", err)
+				}
+			}
+		}
+	})
+	b.Run("Page", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := q.ListArticles(ctx, core.ArticleFilter{}, "", 50); err != nil {
+				b.Fatalf("ListArticles() error = %v", err)
+			}
+		}
+	})
+}
+
+// seedArticles writes n scored articles with three of 50 tags each in one
+// transaction, which is far quicker than CreateArticle for large n.
+func seedArticles(tb testing.TB, q *Queries, n int) {
+	tb.Helper()
+	ctx := context.Background()
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Example")
+	if err != nil {
+		tb.Fatalf("CreateFeed() error = %v", err)
+	}
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		tb.Fatalf("BeginTx() error = %v", err)
+	}
+	defer tx.Rollback()
+
+	for i := range 50 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tags (name) VALUES (?)`, fmt.Sprintf("tag-%02d", i)); err != nil {
+			tb.Fatalf("inserting tag: %v", err)
+		}
+	}
+	insertArticle, err := tx.PrepareContext(ctx, `
+		INSERT INTO articles (feed_id, title, url, published_at, quality_rank, summary, justification, scored_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`)
+	if err != nil {
+		tb.Fatalf("preparing article insert: %v", err)
+	}
+	defer insertArticle.Close()
+	insertTag, err := tx.PrepareContext(ctx, `INSERT INTO article_tags (article_id, tag_id) VALUES (?, (SELECT id FROM tags WHERE name = ?))`)
+	if err != nil {
+		tb.Fatalf("preparing tag insert: %v", err)
+	}
+	defer insertTag.Close()
+
+	now := time.Now()
+	for i := range n {
+		var id int64
+		err := insertArticle.QueryRowContext(ctx, feed.ID, fmt.Sprintf("Article %d", i), fmt.Sprintf("https://example.com/%d", i),
+			now.Add(-time.Duration(i)*time.Minute), 50+i%50, "Summary", "Why", now).Scan(&id)
+		if err != nil {
+			tb.Fatalf("inserting article: %v", err)
+		}
+		for j := range 3 {
+			if _, err := insertTag.ExecContext(ctx, id, fmt.Sprintf("tag-%02d", (i+j*7)%50)); err != nil {
+				tb.Fatalf("tagging article: %v", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		tb.Fatalf("Commit() error = %v", err)
+	}
+}
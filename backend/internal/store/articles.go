@@ -3,75 +3,245 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"slices"
 	"time"
 
 	"dailysynapse/backend/internal/core"
 )
 
-func (q *Queries) CreateArticle(ctx context.Context, article core.Article) (int64, error) {
+// CreateArticle inserts a new article and returns its ID with created set.
+// Articles are deduplicated by URL and, within a feed, by GUID. A known
+// article of the same feed whose UpdatedAt moved forward is refreshed and
+// queued for re-scoring, returning its ID with created unset; any other
+// duplicate returns 0.
+func (q *Queries) CreateArticle(ctx context.Context, article core.Article) (id int64, created bool, err error) {
 	tx, err := q.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("beginning transaction: %w", err)
+		return 0, false, fmt.Errorf("beginning transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	categories, err := encodeCategories(article.Categories)
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Dismissed and rejected items stay gone while the feed still lists them.
+	if gone, err := isTombstoned(ctx, tx, article); err != nil || gone {
+		return 0, false, err
+	}
+
+	var existingID int64
+	var existingUpdated sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, updated_at FROM articles
+		WHERE feed_id = ? AND (url = ? OR (? != '' AND guid = ?))
+		LIMIT 1
+	`, article.FeedID, article.URL, article.GUID, article.GUID).Scan(&existingID, &existingUpdated)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("looking up existing article: %w", err)
+	}
+
+	if existingID != 0 {
+		if article.UpdatedAt.IsZero() || (existingUpdated.Valid && !article.UpdatedAt.After(existingUpdated.Time)) {
+			return 0, false, nil
+		}
+
+		// Rows stored before updated_at was tracked just adopt the timestamp
+		// rather than being re-scored wholesale.
+		if !existingUpdated.Valid {
+			if _, err := tx.ExecContext(ctx, `UPDATE articles SET updated_at = ? WHERE id = ?`, article.UpdatedAt, existingID); err != nil {
+				return 0, false, fmt.Errorf("recording updated_at: %w", err)
+			}
+			if err := tx.Commit(); err != nil {
+				return 0, false, fmt.Errorf("committing transaction: %w", err)
+			}
+			return 0, false, nil
+		}
+
+		query := `
+			UPDATE articles
+			SET title = ?, summary = ?, updated_at = ?, image_url = ?, categories = ?,
+			    quality_rank = NULL, justification = NULL, scored_at = NULL
+			WHERE id = ?
+		`
+		if _, err := tx.ExecContext(ctx, query,
+			article.Title, article.Summary, article.UpdatedAt, article.ImageURL, categories, existingID,
+		); err != nil {
+			return 0, false, fmt.Errorf("refreshing updated article: %w", err)
+		}
+
+		if err := saveArticleRelations(ctx, tx, existingID, article); err != nil {
+			return 0, false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, false, fmt.Errorf("committing transaction: %w", err)
+		}
+		return existingID, false, nil
+	}
+
 	queryMeta := `
-		INSERT INTO articles (feed_id, title, url, published_at, summary)
-		VALUES (?, ?, ?, ?, ?)
-		ON CONFLICT(url) DO NOTHING;
+		INSERT INTO articles (feed_id, title, url, published_at, summary, guid, updated_at, image_url, categories)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO NOTHING
+		RETURNING id
 	`
-	res, err := tx.ExecContext(ctx, queryMeta,
+	var updatedAt sql.NullTime
+	if !article.UpdatedAt.IsZero() {
+		updatedAt = sql.NullTime{Time: article.UpdatedAt, Valid: true}
+	}
+	err = tx.QueryRowContext(ctx, queryMeta,
 		article.FeedID,
 		article.Title,
 		article.URL,
 		article.PublishedAt,
 		article.Summary,
-	)
+		article.GUID,
+		updatedAt,
+		article.ImageURL,
+		categories,
+	).Scan(&id)
+	// No row comes back when the URL was taken concurrently.
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
 	if err != nil {
-		return 0, fmt.Errorf("executing create article meta: %w", err)
+		return 0, false, fmt.Errorf("executing create article meta: %w", err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("getting rows affected: %w", err)
+	if err := saveArticleRelations(ctx, tx, id, article); err != nil {
+		return 0, false, err
 	}
 
-	if rowsAffected == 0 {
-		return 0, nil
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("committing transaction: %w", err)
 	}
 
-	id, err := res.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("getting last insert id: %w", err)
+	return id, true, nil
+}
+
+// saveArticleRelations replaces the authors and enclosures of an article.
+func saveArticleRelations(ctx context.Context, tx *sql.Tx, id int64, article core.Article) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM article_authors WHERE article_id = ?`, id); err != nil {
+		return fmt.Errorf("clearing authors: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM enclosures WHERE article_id = ?`, id); err != nil {
+		return fmt.Errorf("clearing enclosures: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("committing transaction: %w", err)
+	for _, author := range article.Authors {
+		if author.Name == "" && author.Email == "" {
+			continue
+		}
+		var authorID int64
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO authors (name, email) VALUES (?, ?)
+			ON CONFLICT(name, email) DO UPDATE SET name = excluded.name RETURNING id
+		`, author.Name, author.Email).Scan(&authorID)
+		if err != nil {
+			return fmt.Errorf("saving author %s: %w", author.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO article_authors (article_id, author_id) VALUES (?, ?) ON CONFLICT DO NOTHING
+		`, id, authorID); err != nil {
+			return fmt.Errorf("linking author %s: %w", author.Name, err)
+		}
+	}
+
+	for _, enc := range article.Enclosures {
+		if enc.URL == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO enclosures (article_id, url, mime_type, length) VALUES (?, ?, ?, ?)
+		`, id, enc.URL, enc.MIMEType, enc.Length); err != nil {
+			return fmt.Errorf("saving enclosure: %w", err)
+		}
 	}
 
-	return id, nil
+	return nil
+}
+
+func encodeCategories(categories []string) (string, error) {
+	if len(categories) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(categories)
+	if err != nil {
+		return "", fmt.Errorf("encoding categories: %w", err)
+	}
+	return string(b), nil
+}
+
+func decodeCategories(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var categories []string
+	if err := json.Unmarshal([]byte(raw), &categories); err != nil {
+		return nil
+	}
+	return categories
 }
 
 func (q *Queries) DeleteOldArticles(ctx context.Context, horizon time.Time) (int64, error) {
-	query := `DELETE FROM articles WHERE published_at < ? AND read_later = 0`
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	where := `published_at < ? AND read_later = FALSE`
+	if err := deleteArticleRelations(ctx, tx, where, horizon); err != nil {
+		return 0, err
+	}
 
-	res, err := q.db.ExecContext(ctx, query, horizon)
+	res, err := tx.ExecContext(ctx, `DELETE FROM articles WHERE `+where, horizon)
 	if err != nil {
 		return 0, fmt.Errorf("executing delete old articles: %w", err)
 	}
 
-	return res.RowsAffected()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM rejected_articles WHERE published_at < ?`, horizon); err != nil {
+		return 0, fmt.Errorf("pruning rejected articles: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return count, tx.Commit()
 }
 
 func (q *Queries) DeleteArticlesByFeedID(ctx context.Context, feedID int64) error {
-	query := `DELETE FROM articles WHERE feed_id = ?`
-	_, err := q.db.ExecContext(ctx, query, feedID)
+	tx, err := q.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	where := `feed_id = ?`
+	if err := deleteArticleRelations(ctx, tx, where, feedID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM articles WHERE `+where, feedID); err != nil {
 		return fmt.Errorf("executing delete articles by feed id: %w", err)
 	}
+	return tx.Commit()
+}
+
+// deleteArticleRelations removes rows that reference the articles matching
+// where. SQLite foreign keys aren't enforced, so cascades are done by hand.
+func deleteArticleRelations(ctx context.Context, tx *sql.Tx, where string, args ...any) error {
+	for _, table := range []string{"article_tags", "article_authors", "enclosures", "article_content"} {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE article_id IN (SELECT id FROM articles WHERE %s)`, table, where)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("deleting %s: %w", table, err)
+		}
+	}
 	return nil
 }
 
@@ -80,12 +250,13 @@ func (q *Queries) GetUnscoredArticles(ctx context.Context, limit int) ([]core.Ar
 		SELECT a.id, a.feed_id, a.title, a.url, a.published_at, a.summary
 		FROM articles a
 		WHERE a.quality_rank IS NULL
+		  AND a.deleted_at IS NULL
 		  AND a.summary IS NOT NULL
 		  AND length(a.summary) > 50
 		ORDER BY a.published_at DESC
 		LIMIT ?
 	`
-	rows, err := q.db.QueryContext(ctx, query, limit)
+	rows, err := q.read.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("querying unscored articles: %w", err)
 	}
@@ -111,19 +282,47 @@ func (q *Queries) UpdateArticleScore(ctx context.Context, id int64, rank int, su
 
 	query := `
 		UPDATE articles
-		SET quality_rank = ?, summary = ?, justification = ?
+		SET quality_rank = ?, summary = ?, justification = ?, scored_at = ?
 		WHERE id = ?
 	`
-	if _, err := tx.ExecContext(ctx, query, rank, summary, justification, id); err != nil {
+	if _, err := tx.ExecContext(ctx, query, rank, summary, justification, time.Now(), id); err != nil {
 		return fmt.Errorf("updating article score: %w", err)
 	}
 
-	if _, err := tx.ExecContext(ctx, `DELETE FROM article_tags WHERE article_id = ?`, id); err != nil {
+	// Pinned tags come from ingest rules and survive re-scoring.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM article_tags WHERE article_id = ? AND pinned = FALSE`, id); err != nil {
 		return fmt.Errorf("clearing tags: %w", err)
 	}
 
-	insertTag := `INSERT INTO tags (name) VALUES (?) ON CONFLICT(name) DO UPDATE SET id=id RETURNING id`
-	linkTag := `INSERT INTO article_tags (article_id, tag_id) VALUES (?, ?)`
+	if err := linkTags(ctx, tx, id, tags, false); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddArticleTags pins tags to an article so later scoring keeps them.
+func (q *Queries) AddArticleTags(ctx context.Context, id int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := linkTags(ctx, tx, id, tags, true); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func linkTags(ctx context.Context, tx *sql.Tx, id int64, tags []string, pinned bool) error {
+	insertTag := `INSERT INTO tags (name) VALUES (?) ON CONFLICT(name) DO UPDATE SET name = excluded.name RETURNING id`
+	linkTag := `INSERT INTO article_tags (article_id, tag_id, pinned) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`
 
 	stmtTag, err := tx.PrepareContext(ctx, insertTag)
 	if err != nil {
@@ -142,49 +341,59 @@ func (q *Queries) UpdateArticleScore(ctx context.Context, id int64, rank int, su
 		if err := stmtTag.QueryRowContext(ctx, tag).Scan(&tagID); err != nil {
 			return fmt.Errorf("processing tag %s: %w", tag, err)
 		}
-		if _, err := stmtLink.ExecContext(ctx, id, tagID); err != nil {
+		if _, err := stmtLink.ExecContext(ctx, id, tagID, pinned); err != nil {
 			return fmt.Errorf("linking tag %s: %w", tag, err)
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
-func (q *Queries) GetTopArticles(ctx context.Context, limit, offset int) ([]core.Article, int, error) {
-	// Count total scored articles
+// GetArticlesByFeed lists a feed's articles newest first, including ones
+// still waiting for the judge.
+func (q *Queries) GetArticlesByFeed(ctx context.Context, feedID int64, limit, offset int) ([]core.Article, int, error) {
 	var total int
-	err := q.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM articles WHERE quality_rank IS NOT NULL`).Scan(&total)
+	err := q.read.QueryRowContext(ctx, `SELECT COUNT(*) FROM articles WHERE feed_id = ? AND deleted_at IS NULL`, feedID).Scan(&total)
 	if err != nil {
-		return nil, 0, fmt.Errorf("counting articles: %w", err)
+		return nil, 0, fmt.Errorf("counting feed articles: %w", err)
 	}
 
 	query := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.published_at, 
+		SELECT a.id, a.feed_id, a.title, a.url, a.published_at,
 		       a.quality_rank, a.summary, a.justification,
-		       f.name as feed_name, a.is_read, a.read_later
+		       f.name as feed_name, a.is_read, a.read_later, a.image_url
 		FROM articles a
 		JOIN feeds f ON a.feed_id = f.id
-		WHERE a.quality_rank IS NOT NULL
-		ORDER BY a.is_read ASC, a.quality_rank DESC, a.published_at DESC
+		WHERE a.feed_id = ? AND a.deleted_at IS NULL
+		ORDER BY a.published_at DESC
 		LIMIT ? OFFSET ?
 	`
-	rows, err := q.db.QueryContext(ctx, query, limit, offset)
+	rows, err := q.read.QueryContext(ctx, query, feedID, limit, offset)
 	if err != nil {
-		return nil, 0, fmt.Errorf("querying top articles: %w", err)
+		return nil, 0, fmt.Errorf("querying feed articles: %w", err)
 	}
 	defer rows.Close()
 
 	var articles []core.Article
 	for rows.Next() {
 		var a core.Article
-		var feedName string
+		var qualityRank sql.NullInt64
+		var justification sql.NullString
 		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &a.PublishedAt,
-			&a.QualityRank, &a.Summary, &a.Justification, &feedName, &a.IsRead, &a.ReadLater); err != nil {
+			&qualityRank, &a.Summary, &justification, &a.FeedName, &a.IsRead, &a.ReadLater, &a.ImageURL); err != nil {
 			return nil, 0, fmt.Errorf("scanning article: %w", err)
 		}
-		a.FeedName = feedName
+		a.QualityRank = int(qualityRank.Int64)
+		a.Justification = justification.String
 		articles = append(articles, a)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+	if err := q.attachTags(ctx, articles); err != nil {
+		return nil, 0, err
+	}
 	return articles, total, nil
 }
 
@@ -192,19 +401,26 @@ func (q *Queries) GetArticleByID(ctx context.Context, id int64) (*core.Article,
 	query := `
 		SELECT a.id, a.feed_id, a.title, a.url, a.published_at,
 		       a.quality_rank, a.summary, a.justification,
-		       f.name as feed_name, a.is_read, a.read_later
+		       f.name as feed_name, a.is_read, a.read_later,
+		       a.guid, a.updated_at, a.image_url, a.categories,
+		       COALESCE(c.content, '')
 		FROM articles a
 		JOIN feeds f ON a.feed_id = f.id
-		WHERE a.id = ?
+		LEFT JOIN article_content c ON c.article_id = a.id
+		WHERE a.id = ? AND a.deleted_at IS NULL
 	`
 	var a core.Article
 	var feedName string
 	var qualityRank sql.NullInt64
 	var justification sql.NullString
-	err := q.db.QueryRowContext(ctx, query, id).Scan(
+	var updatedAt sql.NullTime
+	var categories string
+	err := q.read.QueryRowContext(ctx, query, id).Scan(
 		&a.ID, &a.FeedID, &a.Title, &a.URL, &a.PublishedAt,
 		&qualityRank, &a.Summary, &justification,
 		&feedName, &a.IsRead, &a.ReadLater,
+		&a.GUID, &updatedAt, &a.ImageURL, &categories,
+		&a.Content,
 	)
 	if err == nil {
 		if qualityRank.Valid {
@@ -222,55 +438,63 @@ func (q *Queries) GetArticleByID(ctx context.Context, id int64) (*core.Article,
 		return nil, fmt.Errorf("querying article: %w", err)
 	}
 	a.FeedName = feedName
+	a.UpdatedAt = updatedAt.Time
+	a.Categories = decodeCategories(categories)
+
+	if a.Authors, err = q.getArticleAuthors(ctx, a.ID); err != nil {
+		return nil, err
+	}
+	if a.Enclosures, err = q.getArticleEnclosures(ctx, a.ID); err != nil {
+		return nil, err
+	}
+	if a.Tags, err = q.GetArticleTags(ctx, a.ID); err != nil {
+		return nil, err
+	}
 	return &a, nil
 }
 
-func (q *Queries) GetArticlesByTags(ctx context.Context, tags []string, limit int) ([]core.Article, error) {
-	if len(tags) == 0 {
-		articles, _, err := q.GetTopArticles(ctx, limit, 0)
-		return articles, err
+func (q *Queries) getArticleAuthors(ctx context.Context, articleID int64) ([]core.Author, error) {
+	rows, err := q.read.QueryContext(ctx, `
+		SELECT au.name, au.email
+		FROM authors au
+		JOIN article_authors aa ON aa.author_id = au.id
+		WHERE aa.article_id = ?
+		ORDER BY au.name
+	`, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("querying article authors: %w", err)
 	}
+	defer rows.Close()
 
-	placeholders := make([]string, len(tags))
-	args := make([]any, len(tags)+1)
-	for i, tag := range tags {
-		placeholders[i] = "?"
-		args[i] = tag
+	var authors []core.Author
+	for rows.Next() {
+		var au core.Author
+		if err := rows.Scan(&au.Name, &au.Email); err != nil {
+			return nil, fmt.Errorf("scanning author: %w", err)
+		}
+		authors = append(authors, au)
 	}
-	args[len(tags)] = limit
-
-	query := fmt.Sprintf(`
-		SELECT DISTINCT a.id, a.feed_id, a.title, a.url, a.published_at,
-		       a.quality_rank, a.summary, a.justification,
-		       f.name as feed_name
-		FROM articles a
-		JOIN feeds f ON a.feed_id = f.id
-		JOIN article_tags at ON a.id = at.article_id
-		JOIN tags t ON at.tag_id = t.id
-		WHERE a.quality_rank IS NOT NULL
-		  AND t.name IN (%s)
-		ORDER BY a.quality_rank DESC, a.published_at DESC
-		LIMIT ?
-	`, strings.Join(placeholders, ","))
+	return authors, rows.Err()
+}
 
-	rows, err := q.db.QueryContext(ctx, query, args...)
+func (q *Queries) getArticleEnclosures(ctx context.Context, articleID int64) ([]core.Enclosure, error) {
+	rows, err := q.read.QueryContext(ctx, `
+		SELECT url, mime_type, length FROM enclosures WHERE article_id = ? ORDER BY id
+	`, articleID)
 	if err != nil {
-		return nil, fmt.Errorf("querying articles by tags: %w", err)
+		return nil, fmt.Errorf("querying article enclosures: %w", err)
 	}
 	defer rows.Close()
 
-	var articles []core.Article
+	var enclosures []core.Enclosure
 	for rows.Next() {
-		var a core.Article
-		var feedName string
-		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &a.PublishedAt,
-			&a.QualityRank, &a.Summary, &a.Justification, &feedName); err != nil {
-			return nil, fmt.Errorf("scanning article: %w", err)
+		var e core.Enclosure
+		if err := rows.Scan(&e.URL, &e.MIMEType, &e.Length); err != nil {
+			return nil, fmt.Errorf("scanning enclosure: %w", err)
 		}
-		a.FeedName = feedName
-		articles = append(articles, a)
+		enclosures = append(enclosures, e)
 	}
-	return articles, nil
+	return enclosures, rows.Err()
 }
 
 func (q *Queries) GetAllTags(ctx context.Context) ([]core.TagCount, error) {
@@ -279,11 +503,11 @@ func (q *Queries) GetAllTags(ctx context.Context) ([]core.TagCount, error) {
 		FROM tags t
 		JOIN article_tags at ON t.id = at.tag_id
 		JOIN articles a ON at.article_id = a.id
-		WHERE a.quality_rank IS NOT NULL
+		WHERE a.quality_rank IS NOT NULL AND a.deleted_at IS NULL
 		GROUP BY t.name
 		ORDER BY count DESC, t.name ASC
 	`
-	rows, err := q.db.QueryContext(ctx, query)
+	rows, err := q.read.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("querying tags: %w", err)
 	}
@@ -300,6 +524,45 @@ func (q *Queries) GetAllTags(ctx context.Context) ([]core.TagCount, error) {
 	return tags, nil
 }
 
+// GetRelatedTags counts the tags on the articles filter matches, most
+// common first, so a tag selection can be narrowed one tag at a time. Tags
+// the filter already names are left out.
+func (q *Queries) GetRelatedTags(ctx context.Context, filter core.ArticleFilter, limit int) ([]core.TagCount, error) {
+	where, args := filterClause(filter)
+	query := `
+		SELECT t.name, COUNT(*) AS count
+		FROM article_tags at
+		JOIN tags t ON t.id = at.tag_id
+		WHERE at.article_id IN (
+			SELECT a.id FROM articles a
+			JOIN feeds f ON a.feed_id = f.id
+			LEFT JOIN categories c ON f.category_id = c.id
+			WHERE ` + where + `)`
+	if named := append(slices.Clone(filter.Tags), filter.RequireTags...); len(named) > 0 {
+		query += ` AND t.name NOT IN (` + placeholders(len(named)) + `)`
+		args = appendStrings(args, named)
+	}
+	query += `
+		GROUP BY t.name
+		ORDER BY count DESC, t.name ASC
+		LIMIT ?`
+	rows, err := q.read.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("querying related tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []core.TagCount
+	for rows.Next() {
+		var t core.TagCount
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
 func (q *Queries) GetArticleTags(ctx context.Context, articleID int64) ([]string, error) {
 	query := `
 		SELECT t.name
@@ -308,7 +571,7 @@ func (q *Queries) GetArticleTags(ctx context.Context, articleID int64) ([]string
 		WHERE at.article_id = ?
 		ORDER BY t.name
 	`
-	rows, err := q.db.QueryContext(ctx, query, articleID)
+	rows, err := q.read.QueryContext(ctx, query, articleID)
 	if err != nil {
 		return nil, fmt.Errorf("querying article tags: %w", err)
 	}
@@ -325,25 +588,85 @@ func (q *Queries) GetArticleTags(ctx context.Context, articleID int64) ([]string
 	return tags, nil
 }
 
+// attachTags fills in Tags for a list of articles with one query rather
+// than a GetArticleTags call per article.
+func (q *Queries) attachTags(ctx context.Context, articles []core.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(articles))
+	args := make([]any, len(articles))
+	for i, a := range articles {
+		index[a.ID] = i
+		args[i] = a.ID
+	}
+
+	query := `
+		SELECT at.article_id, t.name
+		FROM article_tags at
+		JOIN tags t ON t.id = at.tag_id
+		WHERE at.article_id IN (` + placeholders(len(articles)) + `)
+		ORDER BY at.article_id, t.name
+	`
+	rows, err := q.read.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("querying article tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return fmt.Errorf("scanning tag: %w", err)
+		}
+		a := &articles[index[id]]
+		a.Tags = append(a.Tags, name)
+	}
+	return rows.Err()
+}
+
+// MarkArticleRead returns core.ErrNotFound if the article doesn't exist.
 func (q *Queries) MarkArticleRead(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, `UPDATE articles SET is_read = 1 WHERE id = ?`, id)
+	res, err := q.db.ExecContext(ctx, `UPDATE articles SET is_read = TRUE WHERE id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("marking article read: %w", err)
 	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
 	return nil
 }
 
+// MarkArticleUnread returns core.ErrNotFound if the article doesn't exist.
 func (q *Queries) MarkArticleUnread(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, `UPDATE articles SET is_read = 0 WHERE id = ?`, id)
+	res, err := q.db.ExecContext(ctx, `UPDATE articles SET is_read = FALSE WHERE id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("marking article unread: %w", err)
 	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
 	return nil
 }
 
+// ToggleArticleSaved returns core.ErrNotFound if the article doesn't exist.
 func (q *Queries) ToggleArticleSaved(ctx context.Context, id int64) (bool, error) {
 	var currentState bool
 	err := q.db.QueryRowContext(ctx, `SELECT read_later FROM articles WHERE id = ?`, id).Scan(&currentState)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, core.ErrNotFound
+	}
 	if err != nil {
 		return false, fmt.Errorf("getting current state: %w", err)
 	}
@@ -356,36 +679,51 @@ func (q *Queries) ToggleArticleSaved(ctx context.Context, id int64) (bool, error
 	return newState, nil
 }
 
-func (q *Queries) GetSavedArticles(ctx context.Context) ([]core.Article, error) {
-	query := `
-		SELECT a.id, a.feed_id, a.title, a.url, a.published_at,
-		       a.quality_rank, a.summary, a.justification,
-		       f.name as feed_name
-		FROM articles a
-		JOIN feeds f ON a.feed_id = f.id
-		WHERE a.read_later = 1
-		ORDER BY a.published_at DESC
-	`
-	rows, err := q.db.QueryContext(ctx, query)
+// RejectArticle deletes an article the judge scored too low, keeping its
+// score so feed statistics can still count it.
+func (q *Queries) RejectArticle(ctx context.Context, id int64, score int) error {
+	tx, err := q.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("querying saved articles: %w", err)
+		return fmt.Errorf("begin tx: %w", err)
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var articles []core.Article
-	for rows.Next() {
-		var a core.Article
-		var feedName string
-		if err := rows.Scan(&a.ID, &a.FeedID, &a.Title, &a.URL, &a.PublishedAt,
-			&a.QualityRank, &a.Summary, &a.Justification, &feedName); err != nil {
-			return nil, fmt.Errorf("scanning article: %w", err)
-		}
-		a.FeedName = feedName
-		articles = append(articles, a)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rejected_articles (feed_id, score, published_at, rejected_at)
+		SELECT feed_id, ?, published_at, ? FROM articles WHERE id = ?
+	`, score, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("recording rejection: %w", err)
 	}
-	return articles, nil
+	if err := tombstoneArticles(ctx, tx, tombstoneRejected, `id = ?`, id); err != nil {
+		return err
+	}
+
+	if err := deleteArticleRelations(ctx, tx, `id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM articles WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("deleting article: %w", err)
+	}
+
+	return tx.Commit()
 }
 
+// SaveArticleContent keeps the full body of an article whose origin can't be
+// fetched again, such as a newsletter.
+func (q *Queries) SaveArticleContent(ctx context.Context, id int64, content string) error {
+	query := `
+		INSERT INTO article_content (article_id, content) VALUES (?, ?)
+		ON CONFLICT(article_id) DO UPDATE SET content = excluded.content
+	`
+	if _, err := q.db.ExecContext(ctx, query, id, content); err != nil {
+		return fmt.Errorf("saving article content: %w", err)
+	}
+	return nil
+}
+
+// DeleteArticle removes an article right away, skipping the undo window, and
+// keeps it from being re-ingested.
 func (q *Queries) DeleteArticle(ctx context.Context, id int64) error {
 	tx, err := q.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -393,8 +731,11 @@ func (q *Queries) DeleteArticle(ctx context.Context, id int64) error {
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.ExecContext(ctx, `DELETE FROM article_tags WHERE article_id = ?`, id); err != nil {
-		return fmt.Errorf("deleting article tags: %w", err)
+	if err := tombstoneArticles(ctx, tx, tombstoneDismissed, `id = ?`, id); err != nil {
+		return err
+	}
+	if err := deleteArticleRelations(ctx, tx, `id = ?`, id); err != nil {
+		return err
 	}
 	if _, err := tx.ExecContext(ctx, `DELETE FROM articles WHERE id = ?`, id); err != nil {
 		return fmt.Errorf("deleting article: %w", err)
@@ -402,3 +743,69 @@ func (q *Queries) DeleteArticle(ctx context.Context, id int64) error {
 
 	return tx.Commit()
 }
+
+// DismissArticle hides an article until PurgeDismissedArticles removes it.
+// The row is kept so RestoreArticle can bring it back and the syncer won't
+// re-ingest it in the meantime.
+func (q *Queries) DismissArticle(ctx context.Context, id int64) error {
+	res, err := q.db.ExecContext(ctx, `UPDATE articles SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("dismissing article: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// RestoreArticle undoes DismissArticle. It returns core.ErrNotFound if the
+// article isn't dismissed or has already been purged.
+func (q *Queries) RestoreArticle(ctx context.Context, id int64) error {
+	res, err := q.db.ExecContext(ctx, `UPDATE articles SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("restoring article: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDismissedArticles deletes articles dismissed before cutoff, leaving
+// tombstones behind so the syncer doesn't re-add them.
+func (q *Queries) PurgeDismissedArticles(ctx context.Context, cutoff time.Time) (int64, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	where := `deleted_at IS NOT NULL AND deleted_at < ?`
+	if err := tombstoneArticles(ctx, tx, tombstoneDismissed, where, cutoff); err != nil {
+		return 0, err
+	}
+	if err := deleteArticleRelations(ctx, tx, where, cutoff); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM articles WHERE `+where, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purging dismissed articles: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return count, tx.Commit()
+}
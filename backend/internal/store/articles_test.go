@@ -28,7 +28,7 @@ func TestCreateArticle(t *testing.T) {
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
 
-	id, err := q.CreateArticle(ctx, article)
+	id, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -68,13 +68,13 @@ func TestCreateArticle_DuplicateURL(t *testing.T) {
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
 
-	id1, err := q.CreateArticle(ctx, article)
+	id1, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
 
 	// Try to create same article again
-	id2, err := q.CreateArticle(ctx, article)
+	id2, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -86,6 +86,211 @@ func TestCreateArticle_DuplicateURL(t *testing.T) {
 	}
 }
 
+func TestCreateArticle_Metadata(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	article := core.Article{
+		FeedID:      feed.ID,
+		Title:       "Test Article",
+		URL:         "https://example.com/article1",
+		PublishedAt: time.Now(),
+		Summary:     "This is a test article summary that is long enough to pass validation",
+		GUID:        "urn:uuid:1",
+		UpdatedAt:   time.Now(),
+		ImageURL:    "https://example.com/lead.png",
+		Categories:  []string{"Databases", "Postgres"},
+		Authors:     []core.Author{{Name: "Alice", Email: "alice@example.com"}},
+		Enclosures:  []core.Enclosure{{URL: "https://example.com/ep1.mp3", MIMEType: "audio/mpeg", Length: 1024}},
+	}
+
+	id, _, err := q.CreateArticle(ctx, article)
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	got, err := q.GetArticleByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetArticleByID() error = %v", err)
+	}
+	if got.GUID != "urn:uuid:1" {
+		t.Errorf("GUID = %v, want urn:uuid:1", got.GUID)
+	}
+	if got.ImageURL != article.ImageURL {
+		t.Errorf("ImageURL = %v, want %v", got.ImageURL, article.ImageURL)
+	}
+	if len(got.Categories) != 2 || got.Categories[1] != "Postgres" {
+		t.Errorf("Categories = %v, want [Databases Postgres]", got.Categories)
+	}
+	if len(got.Authors) != 1 || got.Authors[0].Email != "alice@example.com" {
+		t.Errorf("Authors = %v, want [Alice]", got.Authors)
+	}
+	if len(got.Enclosures) != 1 || got.Enclosures[0].Length != 1024 {
+		t.Errorf("Enclosures = %v, want one 1024 byte enclosure", got.Enclosures)
+	}
+}
+
+func TestSaveArticleContent(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeedWithType(ctx, "mailto:news@example.com", "Example Weekly", "newsletter")
+	if err != nil {
+		t.Fatalf("CreateFeedWithType() error = %v", err)
+	}
+
+	id, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID:      feed.ID,
+		Title:       "Issue 42",
+		URL:         "mid:issue42@example.com",
+		PublishedAt: time.Now(),
+		Summary:     "This is a newsletter issue with enough text to pass validation",
+	})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	if err := q.SaveArticleContent(ctx, id, "<p>first</p>"); err != nil {
+		t.Fatalf("SaveArticleContent() error = %v", err)
+	}
+	if err := q.SaveArticleContent(ctx, id, "<p>second</p>"); err != nil {
+		t.Fatalf("SaveArticleContent() error = %v", err)
+	}
+
+	got, err := q.GetArticleByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetArticleByID() error = %v", err)
+	}
+	if got.Content != "<p>second</p>" {
+		t.Errorf("Content = %q, want <p>second</p>", got.Content)
+	}
+
+	if err := q.DeleteArticle(ctx, id); err != nil {
+		t.Fatalf("DeleteArticle() error = %v", err)
+	}
+	var count int
+	q.db.QueryRow("SELECT COUNT(*) FROM article_content").Scan(&count)
+	if count != 0 {
+		t.Errorf("article_content rows = %d, want 0 after delete", count)
+	}
+}
+
+func TestCreateArticle_DuplicateGUID(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	article := core.Article{
+		FeedID:      feed.ID,
+		Title:       "Test Article",
+		URL:         "https://example.com/article1?utm_source=rss",
+		PublishedAt: time.Now(),
+		Summary:     "This is a test article summary that is long enough to pass validation",
+		GUID:        "tag:example.com,2024:1",
+	}
+	if _, _, err := q.CreateArticle(ctx, article); err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	// Same GUID under a different URL is still a duplicate
+	article.URL = "https://example.com/article1"
+	id, _, err := q.CreateArticle(ctx, article)
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	if id != 0 {
+		t.Errorf("CreateArticle() returned %v, want 0 (duplicate GUID)", id)
+	}
+}
+
+func TestCreateArticle_UpdatedReingested(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	updated := time.Now().Add(-time.Hour)
+	article := core.Article{
+		FeedID:      feed.ID,
+		Title:       "Original Title",
+		URL:         "https://example.com/article1",
+		PublishedAt: time.Now().Add(-2 * time.Hour),
+		Summary:     "This is a test article summary that is long enough to pass validation",
+		GUID:        "guid-1",
+		UpdatedAt:   updated,
+	}
+	id, created, err := q.CreateArticle(ctx, article)
+	if err != nil || !created {
+		t.Fatalf("CreateArticle() = %v, %v, %v; want a new article", id, created, err)
+	}
+	if err := q.UpdateArticleScore(ctx, id, 80, "summary", "why", "model", nil); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+
+	// Same updated timestamp is a plain duplicate
+	again, _, err := q.CreateArticle(ctx, article)
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	if again != 0 {
+		t.Errorf("CreateArticle() unchanged = %v, want 0", again)
+	}
+
+	article.Title = "Revised Title"
+	article.UpdatedAt = updated.Add(30 * time.Minute)
+	refreshed, created, err := q.CreateArticle(ctx, article)
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	if refreshed != id || created {
+		t.Fatalf("CreateArticle() updated = %v, %v; want existing id %v, not created", refreshed, created, id)
+	}
+
+	// Another feed listing the same URL neither refreshes nor duplicates it.
+	other, err := q.CreateFeed(ctx, "https://example.com/other", "Other Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	elsewhere := article
+	elsewhere.FeedID = other.ID
+	elsewhere.Title = "Other Title"
+	elsewhere.UpdatedAt = updated.Add(time.Hour)
+	if got, _, err := q.CreateArticle(ctx, elsewhere); err != nil || got != 0 {
+		t.Errorf("CreateArticle() from another feed = %v, %v; want 0", got, err)
+	}
+
+	got, err := q.GetArticleByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetArticleByID() error = %v", err)
+	}
+	if got.Title != "Revised Title" {
+		t.Errorf("Title = %v, want Revised Title", got.Title)
+	}
+	if got.QualityRank != 0 {
+		t.Errorf("QualityRank = %v, want reset for re-scoring", got.QualityRank)
+	}
+}
+
 func TestGetUnscoredArticles(t *testing.T) {
 	q, cleanup := setupTestQueries(t)
 	defer cleanup()
@@ -105,7 +310,7 @@ func TestGetUnscoredArticles(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	_, err = q.CreateArticle(ctx, article)
+	_, _, err = q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -118,7 +323,7 @@ func TestGetUnscoredArticles(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	scoredID, err := q.CreateArticle(ctx, scoredArticle)
+	scoredID, _, err := q.CreateArticle(ctx, scoredArticle)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -174,7 +379,7 @@ func TestUpdateArticleScore(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	id, err := q.CreateArticle(ctx, article)
+	id, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -228,7 +433,7 @@ func TestMarkArticleRead(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	id, err := q.CreateArticle(ctx, article)
+	id, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -266,7 +471,7 @@ func TestMarkArticleUnread(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	id, err := q.CreateArticle(ctx, article)
+	id, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -311,7 +516,7 @@ func TestToggleArticleSaved(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	id, err := q.CreateArticle(ctx, article)
+	id, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -371,7 +576,7 @@ func TestDeleteArticle(t *testing.T) {
 		PublishedAt: time.Now(),
 		Summary:     "This is a test article summary that is long enough to pass validation",
 	}
-	id, err := q.CreateArticle(ctx, article)
+	id, _, err := q.CreateArticle(ctx, article)
 	if err != nil {
 		t.Fatalf("CreateArticle() error = %v", err)
 	}
@@ -395,7 +600,69 @@ func TestDeleteArticle(t *testing.T) {
 	}
 }
 
-func TestGetTopArticles_Ordering(t *testing.T) {
+func TestDismissAndRestoreArticle(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	id, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID:      feed.ID,
+		Title:       "Test Article",
+		URL:         "https://example.com/article1",
+		PublishedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	if err := q.UpdateArticleScore(ctx, id, 80, "Summary", "Justification", "model", []string{"test"}); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+
+	if err := q.DismissArticle(ctx, id); err != nil {
+		t.Fatalf("DismissArticle() error = %v", err)
+	}
+	if _, err := q.GetArticleByID(ctx, id); err != core.ErrNotFound {
+		t.Errorf("GetArticleByID() after dismiss error = %v, want ErrNotFound", err)
+	}
+	if page, _ := q.ListArticles(ctx, core.ArticleFilter{}, "", 10); len(page.Articles) != 0 {
+		t.Errorf("ListArticles() returned %d after dismiss, want 0", len(page.Articles))
+	}
+
+	// A dismissed article still blocks re-ingestion.
+	if newID, _, err := q.CreateArticle(ctx, core.Article{FeedID: feed.ID, Title: "Again", URL: "https://example.com/article1"}); err != nil || newID != 0 {
+		t.Errorf("CreateArticle() of dismissed URL = %d, %v, want 0, nil", newID, err)
+	}
+
+	if err := q.RestoreArticle(ctx, id); err != nil {
+		t.Fatalf("RestoreArticle() error = %v", err)
+	}
+	if _, err := q.GetArticleByID(ctx, id); err != nil {
+		t.Errorf("GetArticleByID() after restore error = %v", err)
+	}
+	if err := q.RestoreArticle(ctx, id); err != core.ErrNotFound {
+		t.Errorf("RestoreArticle() on live article error = %v, want ErrNotFound", err)
+	}
+
+	if err := q.DismissArticle(ctx, id); err != nil {
+		t.Fatalf("DismissArticle() error = %v", err)
+	}
+	if n, err := q.PurgeDismissedArticles(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("PurgeDismissedArticles() inside grace period = %d, %v, want 0, nil", n, err)
+	}
+	if n, err := q.PurgeDismissedArticles(ctx, time.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Errorf("PurgeDismissedArticles() = %d, %v, want 1, nil", n, err)
+	}
+	if err := q.RestoreArticle(ctx, id); err != core.ErrNotFound {
+		t.Errorf("RestoreArticle() after purge error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListArticles_PersonalizedOrdering(t *testing.T) {
 	q, cleanup := setupTestQueries(t)
 	defer cleanup()
 
@@ -428,7 +695,7 @@ func TestGetTopArticles_Ordering(t *testing.T) {
 			PublishedAt: a.published,
 			Summary:     "This is a test article summary that is long enough to pass validation",
 		}
-		id, err := q.CreateArticle(ctx, article)
+		id, _, err := q.CreateArticle(ctx, article)
 		if err != nil {
 			t.Fatalf("CreateArticle() error = %v", err)
 		}
@@ -448,27 +715,28 @@ func TestGetTopArticles_Ordering(t *testing.T) {
 	}
 
 	// Get top articles
-	topArticles, _, err := q.GetTopArticles(ctx, 10, 0)
+	page, err := q.ListArticles(ctx, core.ArticleFilter{}, "", 10)
+	topArticles := page.Articles
 	if err != nil {
-		t.Fatalf("GetTopArticles() error = %v", err)
+		t.Fatalf("ListArticles() error = %v", err)
 	}
 
 	if len(topArticles) != 4 {
-		t.Fatalf("GetTopArticles() returned %d articles, want 4", len(topArticles))
+		t.Fatalf("ListArticles() returned %d articles, want 4", len(topArticles))
 	}
 
 	// Verify ordering: unread articles first, then by score
 	// First should be "High Score Unread" (unread, score 90)
 	if topArticles[0].Title != "High Score Unread" {
-		t.Errorf("GetTopArticles()[0].Title = %v, want 'High Score Unread'", topArticles[0].Title)
+		t.Errorf("ListArticles()[0].Title = %v, want 'High Score Unread'", topArticles[0].Title)
 	}
 	// Second should be "Low Score Unread" (unread, score 60)
 	if topArticles[1].Title != "Low Score Unread" {
-		t.Errorf("GetTopArticles()[1].Title = %v, want 'Low Score Unread'", topArticles[1].Title)
+		t.Errorf("ListArticles()[1].Title = %v, want 'Low Score Unread'", topArticles[1].Title)
 	}
 	// Then read articles
 	if topArticles[2].Title != "High Score Read" {
-		t.Errorf("GetTopArticles()[2].Title = %v, want 'High Score Read'", topArticles[2].Title)
+		t.Errorf("ListArticles()[2].Title = %v, want 'High Score Read'", topArticles[2].Title)
 	}
 }
 
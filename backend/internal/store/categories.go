@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const categoryColumns = `c.id, c.name, c.min_score, c.created_at,
+	(SELECT COUNT(*) FROM feeds f WHERE f.category_id = c.id AND f.status != 'pending_deletion')`
+
+// CreateCategory returns core.ErrConflict when the name is already taken.
+func (q *Queries) CreateCategory(ctx context.Context, name string, minScore int) (core.Category, error) {
+	var taken int
+	if err := q.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories WHERE name = ?`, name).Scan(&taken); err != nil {
+		return core.Category{}, fmt.Errorf("checking category name: %w", err)
+	}
+	if taken > 0 {
+		return core.Category{}, core.ErrConflict
+	}
+
+	category := core.Category{Name: name, MinScore: minScore, CreatedAt: time.Now()}
+	err := q.db.QueryRowContext(ctx,
+		`INSERT INTO categories (name, min_score, created_at) VALUES (?, ?, ?) RETURNING id`,
+		category.Name, category.MinScore, category.CreatedAt,
+	).Scan(&category.ID)
+	if err != nil {
+		return core.Category{}, fmt.Errorf("inserting category: %w", err)
+	}
+	return category, nil
+}
+
+// GetOrCreateCategory looks a category up by name, creating it with no
+// score threshold if it doesn't exist yet.
+func (q *Queries) GetOrCreateCategory(ctx context.Context, name string) (core.Category, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+categoryColumns+` FROM categories c WHERE c.name = ?`, name)
+	if err != nil {
+		return core.Category{}, fmt.Errorf("querying category: %w", err)
+	}
+	defer rows.Close()
+
+	categories, err := scanCategories(rows)
+	if err != nil {
+		return core.Category{}, err
+	}
+	if len(categories) > 0 {
+		return categories[0], nil
+	}
+	return q.CreateCategory(ctx, name, 0)
+}
+
+func (q *Queries) GetCategory(ctx context.Context, id int64) (core.Category, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+categoryColumns+` FROM categories c WHERE c.id = ?`, id)
+	if err != nil {
+		return core.Category{}, fmt.Errorf("querying category: %w", err)
+	}
+	defer rows.Close()
+
+	categories, err := scanCategories(rows)
+	if err != nil {
+		return core.Category{}, err
+	}
+	if len(categories) == 0 {
+		return core.Category{}, core.ErrNotFound
+	}
+	return categories[0], nil
+}
+
+func (q *Queries) GetCategories(ctx context.Context) ([]core.Category, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+categoryColumns+` FROM categories c ORDER BY c.name`)
+	if err != nil {
+		return nil, fmt.Errorf("querying categories: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCategories(rows)
+}
+
+// UpdateCategory renames a category and sets its score threshold. Renaming
+// onto another category's name returns core.ErrConflict.
+func (q *Queries) UpdateCategory(ctx context.Context, category core.Category) error {
+	var taken int
+	err := q.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM categories WHERE name = ? AND id != ?`, category.Name, category.ID,
+	).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("checking category name: %w", err)
+	}
+	if taken > 0 {
+		return core.ErrConflict
+	}
+
+	res, err := q.db.ExecContext(ctx,
+		`UPDATE categories SET name = ?, min_score = ? WHERE id = ?`,
+		category.Name, category.MinScore, category.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating category: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCategory removes a category; its feeds become uncategorized.
+func (q *Queries) DeleteCategory(ctx context.Context, id int64) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE feeds SET category_id = NULL WHERE category_id = ?`, id); err != nil {
+		return fmt.Errorf("unassigning feeds: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting category: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+func scanCategories(rows *sql.Rows) ([]core.Category, error) {
+	var categories []core.Category
+	for rows.Next() {
+		var c core.Category
+		var minScore sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.Name, &minScore, &c.CreatedAt, &c.FeedCount); err != nil {
+			return nil, fmt.Errorf("scanning category: %w", err)
+		}
+		c.MinScore = int(minScore.Int64)
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestCategories_CRUD(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	tech, err := q.CreateCategory(ctx, "Tech", 70)
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	if _, err := q.CreateCategory(ctx, "Tech", 0); !errors.Is(err, core.ErrConflict) {
+		t.Errorf("CreateCategory() duplicate error = %v, want ErrConflict", err)
+	}
+
+	again, err := q.GetOrCreateCategory(ctx, "Tech")
+	if err != nil {
+		t.Fatalf("GetOrCreateCategory() error = %v", err)
+	}
+	if again.ID != tech.ID {
+		t.Errorf("GetOrCreateCategory() ID = %d, want %d", again.ID, tech.ID)
+	}
+	news, err := q.GetOrCreateCategory(ctx, "News")
+	if err != nil {
+		t.Fatalf("GetOrCreateCategory() error = %v", err)
+	}
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	updated, err := q.UpdateFeed(ctx, feed.ID, core.FeedPatch{CategoryID: &tech.ID})
+	if err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+	if updated.CategoryID != tech.ID {
+		t.Errorf("UpdateFeed() CategoryID = %d, want %d", updated.CategoryID, tech.ID)
+	}
+	missing := int64(999)
+	if _, err := q.UpdateFeed(ctx, feed.ID, core.FeedPatch{CategoryID: &missing}); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("UpdateFeed() unknown category error = %v, want ErrBadRequest", err)
+	}
+
+	categories, err := q.GetCategories(ctx)
+	if err != nil {
+		t.Fatalf("GetCategories() error = %v", err)
+	}
+	if len(categories) != 2 || categories[0].Name != "News" || categories[1].FeedCount != 1 {
+		t.Errorf("GetCategories() = %+v, want News then Tech with one feed", categories)
+	}
+
+	news.Name = "Tech"
+	if err := q.UpdateCategory(ctx, news); !errors.Is(err, core.ErrConflict) {
+		t.Errorf("UpdateCategory() rename onto existing error = %v, want ErrConflict", err)
+	}
+	tech.MinScore = 80
+	if err := q.UpdateCategory(ctx, tech); err != nil {
+		t.Fatalf("UpdateCategory() error = %v", err)
+	}
+	got, err := q.GetCategory(ctx, tech.ID)
+	if err != nil {
+		t.Fatalf("GetCategory() error = %v", err)
+	}
+	if got.MinScore != 80 {
+		t.Errorf("GetCategory() MinScore = %d, want 80", got.MinScore)
+	}
+
+	if err := q.DeleteCategory(ctx, tech.ID); err != nil {
+		t.Fatalf("DeleteCategory() error = %v", err)
+	}
+	if err := q.DeleteCategory(ctx, tech.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("DeleteCategory() second call error = %v, want ErrNotFound", err)
+	}
+	feed, err = q.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() error = %v", err)
+	}
+	if feed.CategoryID != 0 {
+		t.Errorf("GetFeed() CategoryID = %d after category deletion, want 0", feed.CategoryID)
+	}
+}
+
+func TestListArticles_CategoryFilterAndThreshold(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	strict, err := q.CreateCategory(ctx, "Strict", 70)
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	strictFeed, err := q.CreateFeed(ctx, "https://example.com/strict", "Strict Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if _, err := q.UpdateFeed(ctx, strictFeed.ID, core.FeedPatch{CategoryID: &strict.ID}); err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+	plainFeed, err := q.CreateFeed(ctx, "https://example.com/plain", "Plain Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	articles := []struct {
+		feedID int64
+		title  string
+		score  int
+	}{
+		{strictFeed.ID, "Strict Pass", 85},
+		{strictFeed.ID, "Strict Fail", 60},
+		{plainFeed.ID, "Plain", 55},
+	}
+	for i, a := range articles {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID:      a.feedID,
+			Title:       a.title,
+			URL:         "https://example.com/" + a.title,
+			PublishedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, a.score, "", "", "test", nil); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+	}
+
+	page, err := q.ListArticles(ctx, core.ArticleFilter{}, "", 10)
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	all := page.Articles
+	if len(all) != 2 {
+		t.Fatalf("ListArticles() returned %d, want 2", len(all))
+	}
+	if all[0].Title != "Strict Pass" || all[1].Title != "Plain" {
+		t.Errorf("ListArticles() = [%s %s], want [Strict Pass Plain]", all[0].Title, all[1].Title)
+	}
+
+	page, err = q.ListArticles(ctx, core.ArticleFilter{CategoryID: strict.ID}, "", 10)
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	if len(page.Articles) != 1 || page.Articles[0].Title != "Strict Pass" {
+		t.Errorf("ListArticles(category) returned %d, want only Strict Pass", len(page.Articles))
+	}
+
+	page, err = q.ListArticles(ctx, core.ArticleFilter{AllScores: true}, "", 10)
+	if err != nil {
+		t.Fatalf("ListArticles() error = %v", err)
+	}
+	if len(page.Articles) != 3 {
+		t.Errorf("ListArticles(AllScores) returned %d, want 3 including the one below threshold", len(page.Articles))
+	}
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const channelColumns = `id, name, type, url, room_id, token, min_score, tags, feed_ids, rate_limit, enabled, created_at`
+
+// CreateNotificationChannel stores a channel with its URL and token
+// sealed, since a Slack or Discord webhook URL is itself a credential.
+func (q *Queries) CreateNotificationChannel(ctx context.Context, ch core.NotificationChannel) (core.NotificationChannel, error) {
+	url, token, tags, feedIDs, err := q.encodeChannel(ch)
+	if err != nil {
+		return core.NotificationChannel{}, err
+	}
+
+	ch.CreatedAt = time.Now()
+	query := `
+		INSERT INTO notification_channels
+			(name, type, url, room_id, token, min_score, tags, feed_ids, rate_limit, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = q.db.QueryRowContext(ctx, query,
+		ch.Name, ch.Type, url, ch.RoomID, token, ch.MinScore, tags, feedIDs, ch.RateLimit, ch.Enabled, ch.CreatedAt,
+	).Scan(&ch.ID)
+	if err != nil {
+		return core.NotificationChannel{}, fmt.Errorf("inserting notification channel: %w", err)
+	}
+	return ch, nil
+}
+
+func (q *Queries) GetNotificationChannel(ctx context.Context, id int64) (core.NotificationChannel, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+channelColumns+` FROM notification_channels WHERE id = ?`, id)
+	if err != nil {
+		return core.NotificationChannel{}, fmt.Errorf("querying notification channel: %w", err)
+	}
+	defer rows.Close()
+
+	channels, err := q.scanChannels(rows)
+	if err != nil {
+		return core.NotificationChannel{}, err
+	}
+	if len(channels) == 0 {
+		return core.NotificationChannel{}, core.ErrNotFound
+	}
+	return channels[0], nil
+}
+
+func (q *Queries) GetNotificationChannels(ctx context.Context) ([]core.NotificationChannel, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+channelColumns+` FROM notification_channels ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying notification channels: %w", err)
+	}
+	defer rows.Close()
+
+	return q.scanChannels(rows)
+}
+
+func (q *Queries) UpdateNotificationChannel(ctx context.Context, ch core.NotificationChannel) error {
+	url, token, tags, feedIDs, err := q.encodeChannel(ch)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE notification_channels
+		SET name = ?, type = ?, url = ?, room_id = ?, token = ?, min_score = ?, tags = ?,
+		    feed_ids = ?, rate_limit = ?, enabled = ?
+		WHERE id = ?
+	`
+	res, err := q.db.ExecContext(ctx, query,
+		ch.Name, ch.Type, url, ch.RoomID, token, ch.MinScore, tags, feedIDs, ch.RateLimit, ch.Enabled, ch.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating notification channel: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func (q *Queries) DeleteNotificationChannel(ctx context.Context, id int64) error {
+	res, err := q.db.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting notification channel: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func (q *Queries) scanChannels(rows *sql.Rows) ([]core.NotificationChannel, error) {
+	var channels []core.NotificationChannel
+	for rows.Next() {
+		var ch core.NotificationChannel
+		var url, token, tags, feedIDs string
+		if err := rows.Scan(&ch.ID, &ch.Name, &ch.Type, &url, &ch.RoomID, &token, &ch.MinScore,
+			&tags, &feedIDs, &ch.RateLimit, &ch.Enabled, &ch.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning notification channel: %w", err)
+		}
+
+		var err error
+		if ch.URL, err = q.box.Open(url); err != nil {
+			return nil, fmt.Errorf("opening notification channel url: %w", err)
+		}
+		if ch.Token, err = q.box.Open(token); err != nil {
+			return nil, fmt.Errorf("opening notification channel token: %w", err)
+		}
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &ch.Tags); err != nil {
+				return nil, fmt.Errorf("decoding notification channel tags: %w", err)
+			}
+		}
+		if feedIDs != "" {
+			if err := json.Unmarshal([]byte(feedIDs), &ch.FeedIDs); err != nil {
+				return nil, fmt.Errorf("decoding notification channel feeds: %w", err)
+			}
+		}
+		channels = append(channels, ch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return channels, nil
+}
+
+func (q *Queries) encodeChannel(ch core.NotificationChannel) (url, token, tags, feedIDs string, err error) {
+	if url, err = q.seal(ch.URL); err != nil {
+		return "", "", "", "", err
+	}
+	if token, err = q.seal(ch.Token); err != nil {
+		return "", "", "", "", err
+	}
+	if tags, err = encodeRuleTags(ch.Tags); err != nil {
+		return "", "", "", "", err
+	}
+	if len(ch.FeedIDs) > 0 {
+		b, err := json.Marshal(ch.FeedIDs)
+		if err != nil {
+			return "", "", "", "", fmt.Errorf("encoding notification channel feeds: %w", err)
+		}
+		feedIDs = string(b)
+	}
+	return url, token, tags, feedIDs, nil
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/secret"
+)
+
+func TestNotificationChannels_CRUD(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	ch := core.NotificationChannel{
+		Name:      "Team room",
+		Type:      core.ChannelMatrix,
+		URL:       "https://matrix.example.com",
+		RoomID:    "!room:example.com",
+		Token:     "syt_token",
+		MinScore:  90,
+		RateLimit: 10,
+		Enabled:   true,
+	}
+	if _, err := q.CreateNotificationChannel(ctx, ch); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("CreateNotificationChannel() without SECRET_KEY error = %v, want ErrBadRequest", err)
+	}
+
+	box, err := secret.NewBox("test-key")
+	if err != nil {
+		t.Fatalf("NewBox() error = %v", err)
+	}
+	q = q.WithSecretBox(box)
+
+	ch.Tags = []string{"Go"}
+	ch.FeedIDs = []int64{3, 5}
+	created, err := q.CreateNotificationChannel(ctx, ch)
+	if err != nil {
+		t.Fatalf("CreateNotificationChannel() error = %v", err)
+	}
+
+	var url, token string
+	if err := q.db.QueryRowContext(ctx, `SELECT url, token FROM notification_channels WHERE id = ?`, created.ID).Scan(&url, &token); err != nil {
+		t.Fatalf("querying channel: %v", err)
+	}
+	if url == ch.URL || token == ch.Token {
+		t.Error("channel url or token stored in plaintext")
+	}
+
+	got, err := q.GetNotificationChannel(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetNotificationChannel() error = %v", err)
+	}
+	if got.URL != ch.URL || got.Token != ch.Token || got.RoomID != ch.RoomID || !slices.Equal(got.FeedIDs, ch.FeedIDs) ||
+		!slices.Equal(got.Tags, ch.Tags) || got.MinScore != 90 || got.RateLimit != 10 || !got.Enabled {
+		t.Errorf("GetNotificationChannel() = %+v, want the created channel", got)
+	}
+
+	got.Type = core.ChannelSlack
+	got.URL = "https://hooks.slack.com/services/T/B/X"
+	got.RoomID, got.Token = "", ""
+	got.FeedIDs = nil
+	got.Enabled = false
+	if err := q.UpdateNotificationChannel(ctx, got); err != nil {
+		t.Fatalf("UpdateNotificationChannel() error = %v", err)
+	}
+	channels, err := q.GetNotificationChannels(ctx)
+	if err != nil {
+		t.Fatalf("GetNotificationChannels() error = %v", err)
+	}
+	if len(channels) != 1 || channels[0].Type != core.ChannelSlack || channels[0].Token != "" ||
+		channels[0].FeedIDs != nil || channels[0].Enabled {
+		t.Errorf("GetNotificationChannels() = %+v, want the updated channel", channels)
+	}
+
+	if err := q.DeleteNotificationChannel(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteNotificationChannel() error = %v", err)
+	}
+	if _, err := q.GetNotificationChannel(ctx, created.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("GetNotificationChannel() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := q.UpdateNotificationChannel(ctx, got); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("UpdateNotificationChannel() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := q.DeleteNotificationChannel(ctx, created.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("DeleteNotificationChannel() twice error = %v, want ErrNotFound", err)
+	}
+}
@@ -1,10 +1,13 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,7 +16,36 @@ import (
 	_ "modernc.org/sqlite"
 )
 
+// dialect is what the migration runner needs to know about a database.
+type dialect struct {
+	// migrations is the directory of numbered scripts, relative to the
+	// repository root.
+	migrations string
+	// lock and unlock, when set, hold a session lock while migrating so
+	// replicas starting together apply each script once.
+	lock, unlock string
+}
+
+var (
+	sqliteDialect   = dialect{migrations: filepath.Join("backend", "scripts")}
+	postgresDialect = dialect{
+		migrations: filepath.Join("backend", "scripts", "postgres"),
+		lock:       "SELECT pg_advisory_lock(7317)",
+		unlock:     "SELECT pg_advisory_unlock(7317)",
+	}
+)
+
+// Open connects to DATABASE_URL and brings its schema up to date. A
+// postgres:// or postgresql:// URL opens a Postgres server; anything else
+// is the path of a SQLite file.
 func Open(dsn string) (*sql.DB, error) {
+	if IsPostgresURL(dsn) {
+		return openPostgres(dsn)
+	}
+	return openSQLite(dsn)
+}
+
+func openSQLite(dsn string) (*sql.DB, error) {
 	if err := os.MkdirAll(filepath.Dir(dsn), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
@@ -39,18 +71,58 @@ func Open(dsn string) (*sql.DB, error) {
 
 	db.SetMaxOpenConns(1)
 
-	if err := runMigrations(db); err != nil {
+	if err := runMigrations(db, sqliteDialect); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return db, nil
 }
 
-func runMigrations(db *sql.DB) error {
-	_, err := db.Exec(`
+// OpenReadPool opens more connections to the SQLite file Open prepared, for
+// reads alone. WAL lets them run alongside the single writer; query_only
+// makes any write through them an error rather than a second writer.
+// Postgres pools are concurrent already and don't need one.
+func OpenReadPool(dsn string) (*sql.DB, error) {
+	path, err := filepath.Abs(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve database path: %w", err)
+	}
+	// Pragmas go in the DSN so every pooled connection gets them.
+	uri := url.URL{Scheme: "file", Path: path, RawQuery: "_pragma=busy_timeout(5000)&_pragma=query_only(1)"}
+	db, err := sql.Open("sqlite", uri.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read pool: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect read pool: %w", err)
+	}
+
+	conns := max(4, runtime.NumCPU())
+	db.SetMaxOpenConns(conns)
+	db.SetMaxIdleConns(conns)
+	return db, nil
+}
+
+func runMigrations(db *sql.DB, d dialect) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if d.lock != "" {
+		if _, err := conn.ExecContext(ctx, d.lock); err != nil {
+			return fmt.Errorf("could not lock migrations: %w", err)
+		}
+		defer conn.ExecContext(ctx, d.unlock)
+	}
+
+	_, err = conn.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version INTEGER NOT NULL PRIMARY KEY,
-			applied_at DATETIME NOT NULL
+			applied_at TIMESTAMP NOT NULL
 		);
 	`)
 	if err != nil {
@@ -58,12 +130,12 @@ func runMigrations(db *sql.DB) error {
 	}
 
 	var currentVersion int
-	err = db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&currentVersion)
+	err = conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&currentVersion)
 	if err != nil {
 		return fmt.Errorf("could not get current schema version: %w", err)
 	}
 
-	files, err := filepath.Glob(filepath.Join("backend", "scripts", "*.sql"))
+	files, err := filepath.Glob(filepath.Join(d.migrations, "*.sql"))
 	if err != nil {
 		return fmt.Errorf("could not list migration files: %w", err)
 	}
@@ -101,7 +173,7 @@ func runMigrations(db *sql.DB) error {
 				return fmt.Errorf("could not read migration file %s: %w", m.file, err)
 			}
 
-			tx, err := db.Begin()
+			tx, err := conn.BeginTx(ctx, nil)
 			if err != nil {
 				return fmt.Errorf("could not begin transaction: %w", err)
 			}
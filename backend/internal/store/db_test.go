@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// openPools opens a migrated SQLite file and a read pool on it.
+func openPools(tb testing.TB) (*Queries, *Queries) {
+	tb.Helper()
+	// Migrations are found relative to the repository root.
+	tb.Chdir("../../..")
+	path := filepath.Join(tb.TempDir(), "synapse.db")
+	db, err := Open(path)
+	if err != nil {
+		tb.Fatalf("Open() error = %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	read, err := OpenReadPool(path)
+	if err != nil {
+		tb.Fatalf("OpenReadPool() error = %v", err)
+	}
+	tb.Cleanup(func() { read.Close() })
+
+	q := NewQueries(db)
+	return q, q.WithReadPool(read)
+}
+
+func TestOpenReadPool(t *testing.T) {
+	_, q := openPools(t)
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if got, err := q.GetFeed(ctx, feed.ID); err != nil || got.URL != feed.URL {
+		t.Errorf("GetFeed() right after CreateFeed() = %+v, %v; want the new feed", got, err)
+	}
+	if _, err := q.read.ExecContext(ctx, `DELETE FROM feeds`); err == nil {
+		t.Error("writing through the read pool succeeded, want it refused")
+	}
+
+	// Readers must not wait for a writer holding its transaction open.
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx() error = %v", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `UPDATE feeds SET name = 'Renamed'`); err != nil {
+		t.Fatalf("updating feed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if got, err := q.GetFeed(ctx, feed.ID); err != nil || got.Name != "Example" {
+		t.Errorf("GetFeed() during a write = %+v, %v; want the committed name", got, err)
+	}
+}
+
+// BenchmarkPageUnderSyncLoad runs the queries behind the daily page, a page
+// of articles and then each article's tags, while a sync inserts and scores
+// articles as fast as it can.
+func BenchmarkPageUnderSyncLoad(b *testing.B) {
+	for _, pooled := range []bool{false, true} {
+		name := "SingleConn"
+		if pooled {
+			name = "ReadPool"
+		}
+		b.Run(name, func(b *testing.B) {
+			writer, reader := openPools(b)
+			q := writer
+			if pooled {
+				q = reader
+			}
+			ctx := context.Background()
+			feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Example")
+			if err != nil {
+				b.Fatalf("CreateFeed() error = %v", err)
+			}
+			ingest := func(i int) {
+				id, _, err := q.CreateArticle(ctx, core.Article{
+					FeedID: feed.ID, Title: fmt.Sprintf("Article %d", i), URL: fmt.Sprintf("https://example.com/%d", i),
+					PublishedAt: time.Now(), Summary: strings.Repeat("summary ", 20),
+				})
+				if err != nil {
+					b.Errorf("CreateArticle() error = %v", err)
+					return
+				}
+				if err := q.UpdateArticleScore(ctx, id, 50+i%50, "Summary", "Why", "model", []string{"go", "sql", "systems"}); err != nil {
+					b.Errorf("UpdateArticleScore() error = %v", err)
+				}
+			}
+			for i := range 500 {
+				ingest(i)
+			}
+
+			stop := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 500; ; i++ {
+					select {
+					case <-stop:
+						return
+					default:
+						ingest(i)
+					}
+				}
+			}()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					page, err := q.ListArticles(ctx, core.ArticleFilter{}, "", 30)
+					if err != nil {
+						b.Errorf("ListArticles() error = %v", err)
+						return
+					}
+					for _, a := range page.Articles {
+						if _, err := q.GetArticleTags(ctx, a.ID); err != nil {
+							b.Errorf("GetArticleTags() error = %v", err)
+							return
+						}
+					}
+				}
+			})
+			b.StopTimer()
+			close(stop)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/secret"
+)
+
+// GetFeedSettings returns the fetch settings for a feed, or zero settings if
+// none have been saved. It returns core.ErrNotFound if the feed doesn't
+// exist.
+func (q *Queries) GetFeedSettings(ctx context.Context, feedID int64) (core.FeedSettings, error) {
+	query := `
+		SELECT headers, username, password, user_agent, proxy_url,
+		       tls_insecure_skip_verify, tls_server_name
+		FROM feed_settings
+		WHERE feed_id = ?
+	`
+	settings := core.FeedSettings{FeedID: feedID}
+	var headers, password, proxyURL string
+	err := q.read.QueryRowContext(ctx, query, feedID).Scan(
+		&headers, &settings.Username, &password, &settings.UserAgent, &proxyURL,
+		&settings.TLSInsecureSkipVerify, &settings.TLSServerName,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			var exists bool
+			if err := q.read.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM feeds WHERE id = ?)`, feedID).Scan(&exists); err != nil {
+				return core.FeedSettings{}, fmt.Errorf("checking feed: %w", err)
+			}
+			if !exists {
+				return core.FeedSettings{}, core.ErrNotFound
+			}
+			return settings, nil
+		}
+		return core.FeedSettings{}, fmt.Errorf("querying feed settings: %w", err)
+	}
+
+	if settings.Password, err = q.box.Open(password); err != nil {
+		return core.FeedSettings{}, fmt.Errorf("opening password: %w", err)
+	}
+	if settings.ProxyURL, err = q.box.Open(proxyURL); err != nil {
+		return core.FeedSettings{}, fmt.Errorf("opening proxy url: %w", err)
+	}
+
+	headersJSON, err := q.box.Open(headers)
+	if err != nil {
+		return core.FeedSettings{}, fmt.Errorf("opening headers: %w", err)
+	}
+	if headersJSON != "" {
+		if err := json.Unmarshal([]byte(headersJSON), &settings.Headers); err != nil {
+			return core.FeedSettings{}, fmt.Errorf("decoding headers: %w", err)
+		}
+	}
+
+	return settings, nil
+}
+
+func (q *Queries) SaveFeedSettings(ctx context.Context, settings core.FeedSettings) error {
+	var headersJSON string
+	if len(settings.Headers) > 0 {
+		b, err := json.Marshal(settings.Headers)
+		if err != nil {
+			return fmt.Errorf("encoding headers: %w", err)
+		}
+		headersJSON = string(b)
+	}
+
+	headers, err := q.seal(headersJSON)
+	if err != nil {
+		return err
+	}
+	password, err := q.seal(settings.Password)
+	if err != nil {
+		return err
+	}
+	proxyURL, err := q.seal(settings.ProxyURL)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err := q.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM feeds WHERE id = ?)`, settings.FeedID).Scan(&exists); err != nil {
+		return fmt.Errorf("checking feed: %w", err)
+	}
+	if !exists {
+		return core.ErrNotFound
+	}
+
+	query := `
+		INSERT INTO feed_settings (feed_id, headers, username, password, user_agent, proxy_url,
+		                           tls_insecure_skip_verify, tls_server_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(feed_id) DO UPDATE SET
+			headers = excluded.headers,
+			username = excluded.username,
+			password = excluded.password,
+			user_agent = excluded.user_agent,
+			proxy_url = excluded.proxy_url,
+			tls_insecure_skip_verify = excluded.tls_insecure_skip_verify,
+			tls_server_name = excluded.tls_server_name
+	`
+	_, err = q.db.ExecContext(ctx, query,
+		settings.FeedID, headers, settings.Username, password, settings.UserAgent, proxyURL,
+		settings.TLSInsecureSkipVerify, settings.TLSServerName,
+	)
+	if err != nil {
+		return fmt.Errorf("saving feed settings: %w", err)
+	}
+	return nil
+}
+
+func (q *Queries) seal(plaintext string) (string, error) {
+	sealed, err := q.box.Seal(plaintext)
+	if err != nil {
+		if errors.Is(err, secret.ErrNoKey) {
+			return "", fmt.Errorf("%w: SECRET_KEY is required to store secrets", core.ErrBadRequest)
+		}
+		return "", fmt.Errorf("sealing secret: %w", err)
+	}
+	return sealed, nil
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const (
+	scoreBuckets     = 10
+	frequencyWindow  = 30 * 24 * time.Hour
+	feedStatsTopTags = 10
+)
+
+// GetFeedStats combines the feed's stored articles with the rejection log.
+// Average score and distribution cover everything the judge has seen.
+func (q *Queries) GetFeedStats(ctx context.Context, feedID int64) (core.FeedStats, error) {
+	stats := core.FeedStats{FeedID: feedID}
+
+	var keptSum, rejectedSum sql.NullInt64
+	err := q.read.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(quality_rank), SUM(quality_rank)
+		FROM articles WHERE feed_id = ? AND deleted_at IS NULL
+	`, feedID).Scan(&stats.ArticleCount, &stats.ScoredCount, &keptSum)
+	if err != nil {
+		return stats, fmt.Errorf("counting feed articles: %w", err)
+	}
+	stats.UnscoredCount = stats.ArticleCount - stats.ScoredCount
+
+	err = q.read.QueryRowContext(ctx, `
+		SELECT COUNT(*), SUM(score) FROM rejected_articles WHERE feed_id = ?
+	`, feedID).Scan(&stats.RejectedCount, &rejectedSum)
+	if err != nil {
+		return stats, fmt.Errorf("counting rejected articles: %w", err)
+	}
+
+	if judged := stats.ScoredCount + stats.RejectedCount; judged > 0 {
+		stats.AverageScore = float64(keptSum.Int64+rejectedSum.Int64) / float64(judged)
+		stats.AcceptanceRate = float64(stats.ScoredCount) / float64(judged)
+	}
+
+	if stats.Distribution, err = q.scoreDistribution(ctx, feedID); err != nil {
+		return stats, err
+	}
+
+	since := time.Now().Add(-frequencyWindow)
+	var recent int
+	err = q.read.QueryRowContext(ctx, `
+		SELECT (SELECT COUNT(*) FROM articles WHERE feed_id = ? AND deleted_at IS NULL AND published_at >= ?)
+		     + (SELECT COUNT(*) FROM rejected_articles WHERE feed_id = ? AND published_at >= ?)
+	`, feedID, since, feedID, since).Scan(&recent)
+	if err != nil {
+		return stats, fmt.Errorf("counting recent articles: %w", err)
+	}
+	stats.ArticlesPerWeek = float64(recent) / (frequencyWindow.Hours() / (24 * 7))
+
+	err = q.read.QueryRowContext(ctx, `
+		SELECT published_at FROM articles WHERE feed_id = ? AND deleted_at IS NULL ORDER BY published_at DESC LIMIT 1
+	`, feedID).Scan(&stats.LastPublishedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return stats, fmt.Errorf("querying last published: %w", err)
+	}
+
+	if stats.TopTags, err = q.feedTopTags(ctx, feedID); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+func (q *Queries) scoreDistribution(ctx context.Context, feedID int64) ([]core.ScoreBucket, error) {
+	buckets := make([]core.ScoreBucket, scoreBuckets)
+	for i := range buckets {
+		buckets[i] = core.ScoreBucket{Min: i * 10, Max: i*10 + 9}
+	}
+	buckets[scoreBuckets-1].Max = 100
+
+	rows, err := q.read.QueryContext(ctx, `
+		SELECT CASE WHEN score >= 100 THEN ? ELSE score / 10 END AS bucket, COUNT(*)
+		FROM (
+			SELECT quality_rank AS score FROM articles WHERE feed_id = ? AND quality_rank IS NOT NULL AND deleted_at IS NULL
+			UNION ALL
+			SELECT score FROM rejected_articles WHERE feed_id = ?
+		) scores
+		GROUP BY bucket
+	`, scoreBuckets-1, feedID, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("querying score distribution: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, fmt.Errorf("scanning score bucket: %w", err)
+		}
+		if bucket >= 0 && bucket < scoreBuckets {
+			buckets[bucket].Count = count
+		}
+	}
+	return buckets, rows.Err()
+}
+
+func (q *Queries) feedTopTags(ctx context.Context, feedID int64) ([]core.TagCount, error) {
+	rows, err := q.read.QueryContext(ctx, `
+		SELECT t.name, COUNT(*) AS count
+		FROM article_tags at
+		JOIN tags t ON t.id = at.tag_id
+		JOIN articles a ON a.id = at.article_id
+		WHERE a.feed_id = ? AND a.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY count DESC, t.name ASC
+		LIMIT ?
+	`, feedID, feedStatsTopTags)
+	if err != nil {
+		return nil, fmt.Errorf("querying feed tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []core.TagCount
+	for rows.Next() {
+		var tag core.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("scanning tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestGetFeedStats(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	other, err := q.CreateFeed(ctx, "https://other.com/feed", "Other Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	create := func(feedID int64, n int, age time.Duration) int64 {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID:      feedID,
+			Title:       fmt.Sprintf("Article %d-%d", feedID, n),
+			URL:         fmt.Sprintf("https://example.com/%d/%d", feedID, n),
+			PublishedAt: time.Now().Add(-age),
+			Summary:     "This is a test article summary that is long enough to pass validation",
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		return id
+	}
+
+	// Two kept (80, 95), one rejected (30), one unscored, one old kept (60).
+	a1 := create(feed.ID, 1, time.Hour)
+	a2 := create(feed.ID, 2, 2*time.Hour)
+	a3 := create(feed.ID, 3, 3*time.Hour)
+	create(feed.ID, 4, 4*time.Hour)
+	a5 := create(feed.ID, 5, 60*24*time.Hour)
+	create(other.ID, 1, time.Hour)
+
+	if err := q.UpdateArticleScore(ctx, a1, 80, "s", "j", "m", []string{"Go", "Databases"}); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+	if err := q.UpdateArticleScore(ctx, a2, 95, "s", "j", "m", []string{"Go"}); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+	if err := q.UpdateArticleScore(ctx, a5, 60, "s", "j", "m", nil); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+	if err := q.RejectArticle(ctx, a3, 30); err != nil {
+		t.Fatalf("RejectArticle() error = %v", err)
+	}
+
+	stats, err := q.GetFeedStats(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeedStats() error = %v", err)
+	}
+
+	if stats.ArticleCount != 4 || stats.ScoredCount != 3 || stats.UnscoredCount != 1 || stats.RejectedCount != 1 {
+		t.Errorf("counts = %d articles, %d scored, %d unscored, %d rejected; want 4, 3, 1, 1",
+			stats.ArticleCount, stats.ScoredCount, stats.UnscoredCount, stats.RejectedCount)
+	}
+	if want := (80.0 + 95 + 60 + 30) / 4; stats.AverageScore != want {
+		t.Errorf("AverageScore = %v, want %v", stats.AverageScore, want)
+	}
+	if stats.AcceptanceRate != 0.75 {
+		t.Errorf("AcceptanceRate = %v, want 0.75", stats.AcceptanceRate)
+	}
+	// Four articles in the last 30 days, including the rejected one.
+	if want := 4 / (30.0 / 7); stats.ArticlesPerWeek != want {
+		t.Errorf("ArticlesPerWeek = %v, want %v", stats.ArticlesPerWeek, want)
+	}
+
+	if len(stats.Distribution) != 10 {
+		t.Fatalf("Distribution has %d buckets, want 10", len(stats.Distribution))
+	}
+	for i, want := range map[int]int{3: 1, 6: 1, 8: 1, 9: 1, 0: 0} {
+		if stats.Distribution[i].Count != want {
+			t.Errorf("Distribution[%d] = %d, want %d", i, stats.Distribution[i].Count, want)
+		}
+	}
+	if last := stats.Distribution[9]; last.Min != 90 || last.Max != 100 {
+		t.Errorf("last bucket = %d-%d, want 90-100", last.Min, last.Max)
+	}
+
+	if len(stats.TopTags) != 2 || stats.TopTags[0].Name != "Go" || stats.TopTags[0].Count != 2 {
+		t.Errorf("TopTags = %v, want [Go:2 Databases:1]", stats.TopTags)
+	}
+
+	if _, err := q.GetArticleByID(ctx, a3); err != core.ErrNotFound {
+		t.Errorf("rejected article still present, error = %v", err)
+	}
+}
+
+func TestGetArticlesByFeed(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID:      feed.ID,
+			Title:       fmt.Sprintf("Article %d", i),
+			URL:         fmt.Sprintf("https://example.com/%d", i),
+			PublishedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+			Summary:     "This is a test article summary that is long enough to pass validation",
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+	}
+
+	articles, total, err := q.GetArticlesByFeed(ctx, feed.ID, 2, 0)
+	if err != nil {
+		t.Fatalf("GetArticlesByFeed() error = %v", err)
+	}
+	if total != 3 || len(articles) != 2 {
+		t.Fatalf("GetArticlesByFeed() = %d articles of %d, want 2 of 3", len(articles), total)
+	}
+	if articles[0].Title != "Article 0" || articles[0].QualityRank != 0 {
+		t.Errorf("first article = %q rank %d, want newest unscored", articles[0].Title, articles[0].QualityRank)
+	}
+}
+
+func TestRecordFeedSyncError(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	if err := q.RecordFeedSyncError(ctx, feed.ID, "server returned error: 503"); err != nil {
+		t.Fatalf("RecordFeedSyncError() error = %v", err)
+	}
+	got, err := q.GetFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeed() error = %v", err)
+	}
+	if got.LastSyncError != "server returned error: 503" || got.LastAttemptedAt.IsZero() {
+		t.Errorf("after failure: error %q, attempted %v", got.LastSyncError, got.LastAttemptedAt)
+	}
+	if !got.LastSyncedAt.IsZero() {
+		t.Errorf("LastSyncedAt = %v, want unchanged by a failure", got.LastSyncedAt)
+	}
+
+	if err := q.UpdateFeedHeaders(ctx, feed.ID, "", "", time.Now()); err != nil {
+		t.Fatalf("UpdateFeedHeaders() error = %v", err)
+	}
+	got, _ = q.GetFeed(ctx, feed.ID)
+	if got.LastSyncError != "" {
+		t.Errorf("LastSyncError = %q, want cleared after success", got.LastSyncError)
+	}
+
+	if _, err := q.GetFeed(ctx, 999); err != core.ErrNotFound {
+		t.Errorf("GetFeed() error = %v, want ErrNotFound", err)
+	}
+}
@@ -3,40 +3,75 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/secret"
 )
 
+// Queries writes through db and runs plain reads on read, which is db
+// itself unless WithReadPool gives it a separate pool.
 type Queries struct {
-	db *sql.DB
+	db   *sql.DB
+	read *sql.DB
+	box  *secret.Box
 }
 
 func NewQueries(db *sql.DB) *Queries {
-	return &Queries{db: db}
+	return &Queries{db: db, read: db}
 }
 
+// WithReadPool returns a copy of q that runs reads outside a transaction on
+// read, from OpenReadPool, so they don't queue behind the single writer.
+func (q *Queries) WithReadPool(read *sql.DB) *Queries {
+	c := *q
+	c.read = read
+	return &c
+}
+
+// WithSecretBox returns a copy of q that encrypts feed credentials with box.
+func (q *Queries) WithSecretBox(box *secret.Box) *Queries {
+	c := *q
+	c.box = box
+	return &c
+}
+
+const feedColumns = `id, url, name, source_type, status, etag, last_modified, last_synced_at, last_sync_error, last_attempted_at, category_id`
+
 func (q *Queries) CreateFeed(ctx context.Context, url string, name string) (core.Feed, error) {
+	return q.CreateFeedWithType(ctx, url, name, "rss")
+}
+
+// CreateFeedWithType creates a subscription backed by a non-RSS source
+// such as "hackernews" or "github-releases". It returns core.ErrConflict
+// when a feed already uses url, including one pending deletion.
+func (q *Queries) CreateFeedWithType(ctx context.Context, url, name, sourceType string) (core.Feed, error) {
+	var taken int
+	if err := q.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM feeds WHERE url = ?", url).Scan(&taken); err != nil {
+		return core.Feed{}, fmt.Errorf("checking feed url: %w", err)
+	}
+	if taken > 0 {
+		return core.Feed{}, core.ErrConflict
+	}
+
 	query := `
-		INSERT INTO feeds (url, name, status, last_synced_at)
-		VALUES (?, ?, 'active', ?);
+		INSERT INTO feeds (url, name, source_type, status, last_synced_at)
+		VALUES (?, ?, ?, 'active', ?)
+		RETURNING id
 	`
 	initialSyncTime := time.Time{}
-	res, err := q.db.ExecContext(ctx, query, url, name, initialSyncTime)
-	if err != nil {
+	var id int64
+	if err := q.db.QueryRowContext(ctx, query, url, name, sourceType, initialSyncTime).Scan(&id); err != nil {
 		return core.Feed{}, fmt.Errorf("executing statement: %w", err)
 	}
 
-	id, err := res.LastInsertId()
-	if err != nil {
-		return core.Feed{}, fmt.Errorf("getting last insert ID: %w", err)
-	}
-
 	feed := core.Feed{
 		ID:           id,
 		URL:          url,
 		Name:         name,
+		Type:         sourceType,
 		Status:       "active",
 		LastSyncedAt: initialSyncTime,
 	}
@@ -45,7 +80,23 @@ func (q *Queries) CreateFeed(ctx context.Context, url string, name string) (core
 }
 
 func (q *Queries) DeleteFeed(ctx context.Context, id int64) error {
-	res, err := q.db.ExecContext(ctx, "DELETE FROM feeds WHERE id = ?", id)
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM feed_settings WHERE feed_id = ?", id); err != nil {
+		return fmt.Errorf("deleting feed settings: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM ingest_rules WHERE feed_id = ?", id); err != nil {
+		return fmt.Errorf("deleting ingest rules: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rejected_articles WHERE feed_id = ?", id); err != nil {
+		return fmt.Errorf("deleting rejected articles: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM feeds WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("executing statement: %w", err)
 	}
@@ -58,11 +109,18 @@ func (q *Queries) DeleteFeed(ctx context.Context, id int64) error {
 		return core.ErrNotFound
 	}
 
-	return nil
+	return tx.Commit()
 }
 
+// MarkFeedForDeletion hides a feed and its articles. The syncer purges it
+// once the grace period has passed; until then RestoreFeed brings it back.
 func (q *Queries) MarkFeedForDeletion(ctx context.Context, id int64) error {
-	res, err := q.db.ExecContext(ctx, "UPDATE feeds SET status = 'pending_deletion' WHERE id = ?", id)
+	query := `
+		UPDATE feeds
+		SET status_before_delete = status, status = 'pending_deletion', deleted_at = ?
+		WHERE id = ? AND status != 'pending_deletion'
+	`
+	res, err := q.db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("executing statement: %w", err)
 	}
@@ -78,8 +136,35 @@ func (q *Queries) MarkFeedForDeletion(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (q *Queries) GetFeedsPendingDeletion(ctx context.Context) ([]core.Feed, error) {
-	rows, err := q.db.QueryContext(ctx, "SELECT id, url, name, status, etag, last_modified, last_synced_at FROM feeds WHERE status = 'pending_deletion'")
+// RestoreFeed undoes MarkFeedForDeletion, putting the feed back in the state
+// it was in (active or paused). It returns core.ErrNotFound if the feed isn't
+// pending deletion or has already been purged.
+func (q *Queries) RestoreFeed(ctx context.Context, id int64) (core.Feed, error) {
+	query := `
+		UPDATE feeds
+		SET status = COALESCE(NULLIF(status_before_delete, ''), 'active'), status_before_delete = '', deleted_at = NULL
+		WHERE id = ? AND status = 'pending_deletion'
+	`
+	res, err := q.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("restoring feed: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.Feed{}, core.ErrNotFound
+	}
+	return q.GetFeed(ctx, id)
+}
+
+// GetFeedsPendingDeletion returns feeds deleted before cutoff. Feeds marked
+// before deletion times were recorded count as expired.
+func (q *Queries) GetFeedsPendingDeletion(ctx context.Context, cutoff time.Time) ([]core.Feed, error) {
+	query := "SELECT " + feedColumns + " FROM feeds WHERE status = 'pending_deletion' AND (deleted_at IS NULL OR deleted_at < ?)"
+	rows, err := q.read.QueryContext(ctx, query, cutoff)
 	if err != nil {
 		return nil, fmt.Errorf("querying feeds pending deletion: %w", err)
 	}
@@ -89,7 +174,7 @@ func (q *Queries) GetFeedsPendingDeletion(ctx context.Context) ([]core.Feed, err
 }
 
 func (q *Queries) GetAllFeeds(ctx context.Context) ([]core.Feed, error) {
-	rows, err := q.db.QueryContext(ctx, "SELECT id, url, name, status, etag, last_modified, last_synced_at FROM feeds WHERE status != 'pending_deletion' ORDER BY name")
+	rows, err := q.read.QueryContext(ctx, "SELECT "+feedColumns+" FROM feeds WHERE status != 'pending_deletion' ORDER BY name")
 	if err != nil {
 		return nil, fmt.Errorf("querying feeds: %w", err)
 	}
@@ -98,8 +183,43 @@ func (q *Queries) GetAllFeeds(ctx context.Context) ([]core.Feed, error) {
 	return q.scanFeeds(rows)
 }
 
+func (q *Queries) GetFeed(ctx context.Context, id int64) (core.Feed, error) {
+	rows, err := q.read.QueryContext(ctx, "SELECT "+feedColumns+" FROM feeds WHERE id = ? AND status != 'pending_deletion'", id)
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("querying feed: %w", err)
+	}
+	defer rows.Close()
+
+	feeds, err := q.scanFeeds(rows)
+	if err != nil {
+		return core.Feed{}, err
+	}
+	if len(feeds) == 0 {
+		return core.Feed{}, core.ErrNotFound
+	}
+	return feeds[0], nil
+}
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (core.Feed, error) {
+	rows, err := q.read.QueryContext(ctx, "SELECT "+feedColumns+" FROM feeds WHERE url = ? AND status != 'pending_deletion'", url)
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("querying feed: %w", err)
+	}
+	defer rows.Close()
+
+	feeds, err := q.scanFeeds(rows)
+	if err != nil {
+		return core.Feed{}, err
+	}
+	if len(feeds) == 0 {
+		return core.Feed{}, core.ErrNotFound
+	}
+	return feeds[0], nil
+}
+
+// GetFeedsToSync skips newsletters: they are delivered by mail, not polled.
 func (q *Queries) GetFeedsToSync(ctx context.Context, limit int) ([]core.Feed, error) {
-	rows, err := q.db.QueryContext(ctx, "SELECT id, url, name, status, etag, last_modified, last_synced_at FROM feeds WHERE status = 'active' ORDER BY last_synced_at ASC LIMIT ?", limit)
+	rows, err := q.read.QueryContext(ctx, "SELECT "+feedColumns+" FROM feeds WHERE status = 'active' AND source_type != 'newsletter' ORDER BY last_synced_at ASC NULLS FIRST LIMIT ?", limit)
 	if err != nil {
 		return nil, fmt.Errorf("querying feeds to sync: %w", err)
 	}
@@ -112,14 +232,23 @@ func (q *Queries) scanFeeds(rows *sql.Rows) ([]core.Feed, error) {
 	var feeds []core.Feed
 	for rows.Next() {
 		var feed core.Feed
-		var etag, lastMod sql.NullString
+		var etag, lastMod, sourceType, syncError sql.NullString
+		var attemptedAt sql.NullTime
+		var categoryID sql.NullInt64
 
-		if err := rows.Scan(&feed.ID, &feed.URL, &feed.Name, &feed.Status, &etag, &lastMod, &feed.LastSyncedAt); err != nil {
+		if err := rows.Scan(&feed.ID, &feed.URL, &feed.Name, &sourceType, &feed.Status, &etag, &lastMod, &feed.LastSyncedAt, &syncError, &attemptedAt, &categoryID); err != nil {
 			return nil, fmt.Errorf("could not scan feed row: %w", err)
 		}
 
+		feed.Type = sourceType.String
+		if feed.Type == "" {
+			feed.Type = "rss"
+		}
 		feed.Etag = etag.String
 		feed.LastModified = lastMod.String
+		feed.LastSyncError = syncError.String
+		feed.LastAttemptedAt = attemptedAt.Time
+		feed.CategoryID = categoryID.Int64
 		feeds = append(feeds, feed)
 	}
 
@@ -131,14 +260,107 @@ func (q *Queries) scanFeeds(rows *sql.Rows) ([]core.Feed, error) {
 }
 
 func (q *Queries) UpdateFeedHeaders(ctx context.Context, id int64, etag, lastModified string, lastSyncedAt time.Time) error {
-	query := `UPDATE feeds SET etag = ?, last_modified = ?, last_synced_at = ? WHERE id = ?`
-	_, err := q.db.ExecContext(ctx, query, etag, lastModified, lastSyncedAt, id)
+	query := `
+		UPDATE feeds
+		SET etag = ?, last_modified = ?, last_synced_at = ?, last_attempted_at = ?, last_sync_error = ''
+		WHERE id = ?
+	`
+	_, err := q.db.ExecContext(ctx, query, etag, lastModified, lastSyncedAt, lastSyncedAt, id)
 	if err != nil {
 		return fmt.Errorf("updating feed headers: %w", err)
 	}
 	return nil
 }
 
+// UpdateFeed applies a patch in one transaction. A new URL drops the cached
+// ETag and Last-Modified and puts the feed at the front of the sync queue.
+// Feeds pending deletion can't be edited.
+func (q *Queries) UpdateFeed(ctx context.Context, id int64, patch core.FeedPatch) (core.Feed, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentURL string
+	err = tx.QueryRowContext(ctx, "SELECT url FROM feeds WHERE id = ? AND status != 'pending_deletion'", id).Scan(&currentURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Feed{}, core.ErrNotFound
+	}
+	if err != nil {
+		return core.Feed{}, fmt.Errorf("querying feed: %w", err)
+	}
+
+	if patch.Name != nil {
+		if _, err := tx.ExecContext(ctx, "UPDATE feeds SET name = ? WHERE id = ?", *patch.Name, id); err != nil {
+			return core.Feed{}, fmt.Errorf("updating feed name: %w", err)
+		}
+	}
+
+	if patch.URL != nil && *patch.URL != currentURL {
+		var taken int
+		err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM feeds WHERE url = ? AND id != ?", *patch.URL, id).Scan(&taken)
+		if err != nil {
+			return core.Feed{}, fmt.Errorf("checking feed url: %w", err)
+		}
+		if taken > 0 {
+			return core.Feed{}, core.ErrConflict
+		}
+
+		query := `
+			UPDATE feeds
+			SET url = ?, etag = '', last_modified = '', last_synced_at = ?, last_sync_error = ''
+			WHERE id = ?
+		`
+		if _, err := tx.ExecContext(ctx, query, *patch.URL, time.Time{}, id); err != nil {
+			return core.Feed{}, fmt.Errorf("updating feed url: %w", err)
+		}
+	}
+
+	if patch.Paused != nil {
+		status := "active"
+		if *patch.Paused {
+			status = "paused"
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE feeds SET status = ? WHERE id = ?", status, id); err != nil {
+			return core.Feed{}, fmt.Errorf("updating feed status: %w", err)
+		}
+	}
+
+	if patch.CategoryID != nil {
+		var categoryID sql.NullInt64
+		if *patch.CategoryID != 0 {
+			var exists int
+			err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM categories WHERE id = ?", *patch.CategoryID).Scan(&exists)
+			if err != nil {
+				return core.Feed{}, fmt.Errorf("checking category: %w", err)
+			}
+			if exists == 0 {
+				return core.Feed{}, fmt.Errorf("%w: category %d does not exist", core.ErrBadRequest, *patch.CategoryID)
+			}
+			categoryID = sql.NullInt64{Int64: *patch.CategoryID, Valid: true}
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE feeds SET category_id = ? WHERE id = ?", categoryID, id); err != nil {
+			return core.Feed{}, fmt.Errorf("updating feed category: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return core.Feed{}, fmt.Errorf("committing feed update: %w", err)
+	}
+	return q.GetFeed(ctx, id)
+}
+
+// RecordFeedSyncError notes a failed sync without touching last_synced_at,
+// so the feed stays at the front of the sync queue.
+func (q *Queries) RecordFeedSyncError(ctx context.Context, id int64, message string) error {
+	query := `UPDATE feeds SET last_sync_error = ?, last_attempted_at = ? WHERE id = ?`
+	if _, err := q.db.ExecContext(ctx, query, message, time.Now(), id); err != nil {
+		return fmt.Errorf("recording sync error: %w", err)
+	}
+	return nil
+}
+
 func (q *Queries) UpdateFeedName(ctx context.Context, id int64, name string) error {
 	query := `UPDATE feeds SET name = ? WHERE id = ?`
 	_, err := q.db.ExecContext(ctx, query, name, id)
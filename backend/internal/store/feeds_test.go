@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -31,6 +32,10 @@ func TestCreateFeed(t *testing.T) {
 	if feed.Status != "active" {
 		t.Errorf("CreateFeed() Status = %v, want 'active'", feed.Status)
 	}
+
+	if _, err := q.CreateFeed(ctx, "https://example.com/feed", "Again"); !errors.Is(err, core.ErrConflict) {
+		t.Errorf("CreateFeed() duplicate error = %v, want ErrConflict", err)
+	}
 }
 
 func TestGetAllFeeds(t *testing.T) {
@@ -211,7 +216,7 @@ func TestMarkFeedForDeletion(t *testing.T) {
 	}
 
 	// Verify feed is marked for deletion
-	pendingFeeds, err := q.GetFeedsPendingDeletion(ctx)
+	pendingFeeds, err := q.GetFeedsPendingDeletion(ctx, time.Now().Add(time.Second))
 	if err != nil {
 		t.Fatalf("GetFeedsPendingDeletion() error = %v", err)
 	}
@@ -243,6 +248,49 @@ func TestMarkFeedForDeletion(t *testing.T) {
 	}
 }
 
+func TestRestoreFeed(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	paused := true
+	if _, err := q.UpdateFeed(ctx, feed.ID, core.FeedPatch{Paused: &paused}); err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+
+	if err := q.MarkFeedForDeletion(ctx, feed.ID); err != nil {
+		t.Fatalf("MarkFeedForDeletion() error = %v", err)
+	}
+	if err := q.MarkFeedForDeletion(ctx, feed.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("MarkFeedForDeletion() twice error = %v, want ErrNotFound", err)
+	}
+
+	// Still inside the grace period.
+	pending, err := q.GetFeedsPendingDeletion(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("GetFeedsPendingDeletion() error = %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("GetFeedsPendingDeletion() returned %d feeds inside the grace period, want 0", len(pending))
+	}
+
+	restored, err := q.RestoreFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("RestoreFeed() error = %v", err)
+	}
+	if restored.Status != "paused" {
+		t.Errorf("RestoreFeed() Status = %q, want paused", restored.Status)
+	}
+	if _, err := q.RestoreFeed(ctx, feed.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("RestoreFeed() on live feed error = %v, want ErrNotFound", err)
+	}
+}
+
 func TestDeleteFeed(t *testing.T) {
 	q, cleanup := setupTestQueries(t)
 	defer cleanup()
@@ -253,11 +301,18 @@ func TestDeleteFeed(t *testing.T) {
 	if err != nil {
 		t.Fatalf("CreateFeed() error = %v", err)
 	}
+	rule, err := q.CreateIngestRule(ctx, core.IngestRule{FeedID: feed.ID, Field: "title", MatchType: "substring", Pattern: "ad", Action: "drop", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
 
 	err = q.DeleteFeed(ctx, feed.ID)
 	if err != nil {
 		t.Fatalf("DeleteFeed() error = %v", err)
 	}
+	if _, err := q.GetIngestRule(ctx, rule.ID); err != core.ErrNotFound {
+		t.Errorf("GetIngestRule() after deleting its feed error = %v, want ErrNotFound", err)
+	}
 
 	// Verify feed is deleted
 	feeds, err := q.GetAllFeeds(ctx)
@@ -284,3 +339,143 @@ func TestDeleteFeed_NotFound(t *testing.T) {
 	}
 }
 
+
+func TestCreateFeedWithType(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if _, err := q.CreateFeed(ctx, "https://example.com/feed", "RSS Feed"); err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	hn, err := q.CreateFeedWithType(ctx, "https://news.ycombinator.com/", "", "hackernews")
+	if err != nil {
+		t.Fatalf("CreateFeedWithType() error = %v", err)
+	}
+	if hn.Type != "hackernews" {
+		t.Errorf("CreateFeedWithType() Type = %v, want hackernews", hn.Type)
+	}
+
+	feeds, err := q.GetFeedsToSync(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetFeedsToSync() error = %v", err)
+	}
+
+	types := make(map[int64]string)
+	for _, f := range feeds {
+		types[f.ID] = f.Type
+	}
+	if types[hn.ID] != "hackernews" {
+		t.Errorf("GetFeedsToSync() Type = %v, want hackernews", types[hn.ID])
+	}
+	for id, typ := range types {
+		if id != hn.ID && typ != "rss" {
+			t.Errorf("GetFeedsToSync() Type = %v, want rss", typ)
+		}
+	}
+}
+
+func TestGetFeedByURL(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	created, err := q.CreateFeedWithType(ctx, "mailto:news@example.com", "Example Weekly", "newsletter")
+	if err != nil {
+		t.Fatalf("CreateFeedWithType() error = %v", err)
+	}
+
+	got, err := q.GetFeedByURL(ctx, "mailto:news@example.com")
+	if err != nil {
+		t.Fatalf("GetFeedByURL() error = %v", err)
+	}
+	if got.ID != created.ID || got.Type != "newsletter" {
+		t.Errorf("GetFeedByURL() = %+v, want feed %d of type newsletter", got, created.ID)
+	}
+
+	if _, err := q.GetFeedByURL(ctx, "mailto:other@example.com"); err != core.ErrNotFound {
+		t.Errorf("GetFeedByURL() error = %v, want ErrNotFound", err)
+	}
+
+	feeds, err := q.GetFeedsToSync(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetFeedsToSync() error = %v", err)
+	}
+	for _, f := range feeds {
+		if f.ID == created.ID {
+			t.Error("GetFeedsToSync() returned a newsletter feed")
+		}
+	}
+}
+
+func TestUpdateFeed(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Old Name")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if _, err := q.CreateFeed(ctx, "https://taken.com/feed", "Other"); err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if err := q.UpdateFeedHeaders(ctx, feed.ID, "etag-1", "Mon, 01 Jan 2024 00:00:00 GMT", time.Now()); err != nil {
+		t.Fatalf("UpdateFeedHeaders() error = %v", err)
+	}
+
+	name := "New Name"
+	got, err := q.UpdateFeed(ctx, feed.ID, core.FeedPatch{Name: &name})
+	if err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+	if got.Name != "New Name" || got.Etag != "etag-1" {
+		t.Errorf("after rename: Name %q, Etag %q; want New Name with headers kept", got.Name, got.Etag)
+	}
+
+	newURL := "https://example.com/atom.xml"
+	got, err = q.UpdateFeed(ctx, feed.ID, core.FeedPatch{URL: &newURL})
+	if err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+	if got.URL != newURL || got.Etag != "" || got.LastModified != "" || !got.LastSyncedAt.IsZero() {
+		t.Errorf("after URL change: %+v, want headers reset", got)
+	}
+
+	taken := "https://taken.com/feed"
+	if _, err := q.UpdateFeed(ctx, feed.ID, core.FeedPatch{URL: &taken}); err != core.ErrConflict {
+		t.Errorf("UpdateFeed() to a taken URL error = %v, want ErrConflict", err)
+	}
+
+	paused := true
+	if got, err = q.UpdateFeed(ctx, feed.ID, core.FeedPatch{Paused: &paused}); err != nil || got.Status != "paused" {
+		t.Fatalf("UpdateFeed() pause = %v, %v; want paused", got.Status, err)
+	}
+	feeds, err := q.GetFeedsToSync(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetFeedsToSync() error = %v", err)
+	}
+	for _, f := range feeds {
+		if f.ID == feed.ID {
+			t.Error("GetFeedsToSync() returned a paused feed")
+		}
+	}
+
+	paused = false
+	if got, err = q.UpdateFeed(ctx, feed.ID, core.FeedPatch{Paused: &paused}); err != nil || got.Status != "active" {
+		t.Errorf("UpdateFeed() resume = %v, %v; want active", got.Status, err)
+	}
+
+	if _, err := q.UpdateFeed(ctx, 999, core.FeedPatch{Name: &name}); err != core.ErrNotFound {
+		t.Errorf("UpdateFeed() missing feed error = %v, want ErrNotFound", err)
+	}
+	if err := q.MarkFeedForDeletion(ctx, feed.ID); err != nil {
+		t.Fatalf("MarkFeedForDeletion() error = %v", err)
+	}
+	if _, err := q.UpdateFeed(ctx, feed.ID, core.FeedPatch{Name: &name}); err != core.ErrNotFound {
+		t.Errorf("UpdateFeed() deleted feed error = %v, want ErrNotFound", err)
+	}
+}
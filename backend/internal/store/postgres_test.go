@@ -0,0 +1,35 @@
+package store
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query, want string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM feeds WHERE id = ? AND url = ?", "SELECT * FROM feeds WHERE id = $1 AND url = $2"},
+		{"SELECT '?', \"odd?col\" FROM t WHERE a = ?", "SELECT '?', \"odd?col\" FROM t WHERE a = $1"},
+		{"SELECT 'it''s ?' WHERE a = ?", "SELECT 'it''s ?' WHERE a = $1"},
+		{"-- what's this?\nSELECT ?", "-- what's this?\nSELECT $1"},
+	}
+	for _, tt := range tests {
+		if got := rebind(tt.query); got != tt.want {
+			t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestIsPostgresURL(t *testing.T) {
+	tests := map[string]bool{
+		"postgres://synapse@db/synapse":           true,
+		"postgresql://synapse@db/synapse":         true,
+		"data/synapse.db":                         false,
+		"/var/lib/synapse/postgres.db":            false,
+		"file:synapse.db?_pragma=foreign_keys(1)": false,
+	}
+	for dsn, want := range tests {
+		if got := IsPostgresURL(dsn); got != want {
+			t.Errorf("IsPostgresURL(%q) = %t, want %t", dsn, got, want)
+		}
+	}
+}
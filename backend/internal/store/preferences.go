@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// followBoost is added to the score of articles with a followed tag when
+// ranking the personalized list.
+const followBoost = 15
+
+// GetPreferences returns the followed and muted tags, each sorted by name.
+func (q *Queries) GetPreferences(ctx context.Context) (core.Preferences, error) {
+	var prefs core.Preferences
+	rows, err := q.read.QueryContext(ctx, `SELECT tag, muted, override_score FROM tag_preferences ORDER BY tag`)
+	if err != nil {
+		return prefs, fmt.Errorf("querying tag preferences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m core.MutedTag
+		var muted bool
+		if err := rows.Scan(&m.Tag, &muted, &m.OverrideScore); err != nil {
+			return prefs, fmt.Errorf("scanning tag preference: %w", err)
+		}
+		if muted {
+			prefs.MutedTags = append(prefs.MutedTags, m)
+		} else {
+			prefs.FollowedTags = append(prefs.FollowedTags, m.Tag)
+		}
+	}
+	return prefs, rows.Err()
+}
+
+// SetPreferences replaces the followed and muted tags. A tag listed as both
+// is muted.
+func (q *Queries) SetPreferences(ctx context.Context, prefs core.Preferences) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tag_preferences`); err != nil {
+		return fmt.Errorf("clearing tag preferences: %w", err)
+	}
+	upsert := `INSERT INTO tag_preferences (tag, muted, override_score) VALUES (?, ?, ?)
+		ON CONFLICT(tag) DO UPDATE SET muted = excluded.muted, override_score = excluded.override_score`
+	for _, tag := range prefs.FollowedTags {
+		if _, err := tx.ExecContext(ctx, upsert, tag, false, 0); err != nil {
+			return fmt.Errorf("following tag %s: %w", tag, err)
+		}
+	}
+	for _, m := range prefs.MutedTags {
+		if _, err := tx.ExecContext(ctx, upsert, m.Tag, true, m.OverrideScore); err != nil {
+			return fmt.Errorf("muting tag %s: %w", m.Tag, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestPreferences_SetAndGet(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	if prefs, err := q.GetPreferences(ctx); err != nil || prefs.FollowedTags != nil || prefs.MutedTags != nil {
+		t.Fatalf("GetPreferences() = %+v, %v; want none", prefs, err)
+	}
+
+	err := q.SetPreferences(ctx, core.Preferences{
+		FollowedTags: []string{"Go", "Distributed Systems"},
+		MutedTags:    []core.MutedTag{{Tag: "Frontend", OverrideScore: 90}, {Tag: "Crypto"}},
+	})
+	if err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	prefs, err := q.GetPreferences(ctx)
+	if err != nil {
+		t.Fatalf("GetPreferences() error = %v", err)
+	}
+	if got, want := fmt.Sprint(prefs), "{[Distributed Systems Go] [{Crypto 0} {Frontend 90}]}"; got != want {
+		t.Errorf("GetPreferences() = %s, want %s", got, want)
+	}
+
+	// Replacing drops what isn't listed; a tag both followed and muted ends up muted.
+	err = q.SetPreferences(ctx, core.Preferences{
+		FollowedTags: []string{"Go"},
+		MutedTags:    []core.MutedTag{{Tag: "Go", OverrideScore: 50}},
+	})
+	if err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	prefs, err = q.GetPreferences(ctx)
+	if err != nil {
+		t.Fatalf("GetPreferences() error = %v", err)
+	}
+	if got, want := fmt.Sprint(prefs), "{[] [{Go 50}]}"; got != want {
+		t.Errorf("GetPreferences() after replacing = %s, want %s", got, want)
+	}
+}
+
+func TestListArticles_Preferences(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	now := time.Now()
+	articles := []struct {
+		title string
+		score int
+		tags  []string
+	}{
+		{"Databases", 80, []string{"Databases"}},
+		{"Systems", 70, []string{"Distributed Systems"}},
+		{"Frontend", 75, []string{"Frontend"}},
+		{"Great Frontend", 95, []string{"Frontend", "Performance"}},
+		{"Crypto", 99, []string{"Crypto"}},
+	}
+	for i, a := range articles {
+		id, _, err := q.CreateArticle(ctx, core.Article{
+			FeedID: feed.ID, Title: a.title, URL: "https://example.com/" + a.title, PublishedAt: now.Add(-time.Duration(i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := q.UpdateArticleScore(ctx, id, a.score, "", "Why", "test", a.tags); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+	}
+	err = q.SetPreferences(ctx, core.Preferences{
+		FollowedTags: []string{"Distributed Systems"},
+		MutedTags:    []core.MutedTag{{Tag: "Frontend", OverrideScore: 90}, {Tag: "Crypto"}},
+	})
+	if err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter core.ArticleFilter
+		want   []string
+	}{
+		{"daily", core.ArticleFilter{HideMuted: true}, []string{"Great Frontend", "Systems", "Databases"}},
+		{"muted tag asked for", core.ArticleFilter{HideMuted: true, Tags: []string{"Frontend"}}, []string{"Great Frontend", "Frontend"}},
+		{"by score", core.ArticleFilter{HideMuted: true, Sort: core.SortScore}, []string{"Great Frontend", "Databases", "Systems"}},
+		{"mutes off", core.ArticleFilter{}, []string{"Crypto", "Great Frontend", "Systems", "Databases", "Frontend"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Pages of one check the cursor keeps the boosted order.
+			var got []string
+			cursor := ""
+			for range len(articles) + 1 {
+				page, err := q.ListArticles(ctx, tt.filter, cursor, 1)
+				if err != nil {
+					t.Fatalf("ListArticles() error = %v", err)
+				}
+				for _, a := range page.Articles {
+					got = append(got, a.Title)
+				}
+				if cursor = page.NextCursor; cursor == "" {
+					break
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ListArticles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
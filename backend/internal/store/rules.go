@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const ruleColumns = `id, feed_id, field, match_type, pattern, action, tags, score, enabled,
+	match_count, last_matched_at, created_at`
+
+func (q *Queries) CreateIngestRule(ctx context.Context, rule core.IngestRule) (core.IngestRule, error) {
+	tags, err := encodeRuleTags(rule.Tags)
+	if err != nil {
+		return core.IngestRule{}, err
+	}
+
+	rule.CreatedAt = time.Now()
+	query := `
+		INSERT INTO ingest_rules (feed_id, field, match_type, pattern, action, tags, score, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = q.db.QueryRowContext(ctx, query,
+		nullableFeedID(rule.FeedID), rule.Field, rule.MatchType, rule.Pattern,
+		rule.Action, tags, rule.Score, rule.Enabled, rule.CreatedAt,
+	).Scan(&rule.ID)
+	if err != nil {
+		return core.IngestRule{}, fmt.Errorf("inserting ingest rule: %w", err)
+	}
+	return rule, nil
+}
+
+func (q *Queries) GetIngestRule(ctx context.Context, id int64) (core.IngestRule, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+ruleColumns+` FROM ingest_rules WHERE id = ?`, id)
+	if err != nil {
+		return core.IngestRule{}, fmt.Errorf("querying ingest rule: %w", err)
+	}
+	defer rows.Close()
+
+	rules, err := scanRules(rows)
+	if err != nil {
+		return core.IngestRule{}, err
+	}
+	if len(rules) == 0 {
+		return core.IngestRule{}, core.ErrNotFound
+	}
+	return rules[0], nil
+}
+
+func (q *Queries) GetIngestRules(ctx context.Context) ([]core.IngestRule, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+ruleColumns+` FROM ingest_rules ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying ingest rules: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRules(rows)
+}
+
+// GetIngestRulesForFeed returns the enabled global rules plus the feed's own.
+func (q *Queries) GetIngestRulesForFeed(ctx context.Context, feedID int64) ([]core.IngestRule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM ingest_rules
+		WHERE enabled = TRUE AND (feed_id IS NULL OR feed_id = ?)
+		ORDER BY id`
+	rows, err := q.read.QueryContext(ctx, query, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("querying ingest rules for feed: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRules(rows)
+}
+
+func (q *Queries) UpdateIngestRule(ctx context.Context, rule core.IngestRule) error {
+	tags, err := encodeRuleTags(rule.Tags)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE ingest_rules
+		SET feed_id = ?, field = ?, match_type = ?, pattern = ?, action = ?, tags = ?, score = ?, enabled = ?
+		WHERE id = ?
+	`
+	res, err := q.db.ExecContext(ctx, query,
+		nullableFeedID(rule.FeedID), rule.Field, rule.MatchType, rule.Pattern,
+		rule.Action, tags, rule.Score, rule.Enabled, rule.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating ingest rule: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+func (q *Queries) DeleteIngestRule(ctx context.Context, id int64) error {
+	res, err := q.db.ExecContext(ctx, `DELETE FROM ingest_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting ingest rule: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// RecordRuleMatches adds counts[id] to each rule's running match total.
+func (q *Queries) RecordRuleMatches(ctx context.Context, counts map[int64]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE ingest_rules
+		SET match_count = match_count + ?, last_matched_at = ?
+		WHERE id = ?
+	`)
+	if err != nil {
+		return fmt.Errorf("preparing rule match update: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for id, n := range counts {
+		if _, err := stmt.ExecContext(ctx, n, now, id); err != nil {
+			return fmt.Errorf("recording rule matches: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func scanRules(rows *sql.Rows) ([]core.IngestRule, error) {
+	var rules []core.IngestRule
+	for rows.Next() {
+		var r core.IngestRule
+		var feedID sql.NullInt64
+		var tags string
+		var lastMatched sql.NullTime
+		if err := rows.Scan(&r.ID, &feedID, &r.Field, &r.MatchType, &r.Pattern, &r.Action,
+			&tags, &r.Score, &r.Enabled, &r.MatchCount, &lastMatched, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning ingest rule: %w", err)
+		}
+		r.FeedID = feedID.Int64
+		r.LastMatchedAt = lastMatched.Time
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &r.Tags); err != nil {
+				return nil, fmt.Errorf("decoding rule tags: %w", err)
+			}
+		}
+		rules = append(rules, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return rules, nil
+}
+
+func encodeRuleTags(tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("encoding rule tags: %w", err)
+	}
+	return string(b), nil
+}
+
+func nullableFeedID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
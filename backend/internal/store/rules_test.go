@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestIngestRules_CRUD(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	other, err := q.CreateFeed(ctx, "https://example.com/other", "Other Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	global, err := q.CreateIngestRule(ctx, core.IngestRule{
+		Field: "title", MatchType: "substring", Pattern: "Sponsored", Action: "drop", Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
+	feedRule, err := q.CreateIngestRule(ctx, core.IngestRule{
+		FeedID: feed.ID, Field: "categories", MatchType: "substring", Pattern: "postgres",
+		Action: "tag", Tags: []string{"Databases", "Postgres"}, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
+	if _, err := q.CreateIngestRule(ctx, core.IngestRule{
+		FeedID: other.ID, Field: "title", MatchType: "substring", Pattern: "x", Action: "drop", Enabled: true,
+	}); err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
+
+	forFeed, err := q.GetIngestRulesForFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetIngestRulesForFeed() error = %v", err)
+	}
+	if len(forFeed) != 2 {
+		t.Fatalf("GetIngestRulesForFeed() returned %d rules, want 2", len(forFeed))
+	}
+	if len(forFeed[1].Tags) != 2 || forFeed[1].Tags[0] != "Databases" {
+		t.Errorf("GetIngestRulesForFeed() Tags = %v, want [Databases Postgres]", forFeed[1].Tags)
+	}
+
+	feedRule.Enabled = false
+	if err := q.UpdateIngestRule(ctx, feedRule); err != nil {
+		t.Fatalf("UpdateIngestRule() error = %v", err)
+	}
+	forFeed, err = q.GetIngestRulesForFeed(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetIngestRulesForFeed() error = %v", err)
+	}
+	if len(forFeed) != 1 {
+		t.Errorf("GetIngestRulesForFeed() returned %d rules after disabling, want 1", len(forFeed))
+	}
+
+	if err := q.RecordRuleMatches(ctx, map[int64]int{global.ID: 3}); err != nil {
+		t.Fatalf("RecordRuleMatches() error = %v", err)
+	}
+	if err := q.RecordRuleMatches(ctx, map[int64]int{global.ID: 2}); err != nil {
+		t.Fatalf("RecordRuleMatches() error = %v", err)
+	}
+	got, err := q.GetIngestRule(ctx, global.ID)
+	if err != nil {
+		t.Fatalf("GetIngestRule() error = %v", err)
+	}
+	if got.MatchCount != 5 {
+		t.Errorf("GetIngestRule() MatchCount = %d, want 5", got.MatchCount)
+	}
+	if time.Since(got.LastMatchedAt) > time.Minute {
+		t.Errorf("GetIngestRule() LastMatchedAt = %v, want recent", got.LastMatchedAt)
+	}
+
+	if err := q.DeleteIngestRule(ctx, global.ID); err != nil {
+		t.Fatalf("DeleteIngestRule() error = %v", err)
+	}
+	if _, err := q.GetIngestRule(ctx, global.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("GetIngestRule() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := q.DeleteIngestRule(ctx, global.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("DeleteIngestRule() twice error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAddArticleTags_SurviveScoring(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	id, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID:      feed.ID,
+		Title:       "Pinned",
+		URL:         "https://example.com/pinned",
+		PublishedAt: time.Now(),
+		Summary:     "This is a test article summary that is long enough to pass validation",
+	})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	if err := q.AddArticleTags(ctx, id, []string{"Forced"}); err != nil {
+		t.Fatalf("AddArticleTags() error = %v", err)
+	}
+	if err := q.UpdateArticleScore(ctx, id, 80, "summary", "why", "model", []string{"Go", "Forced"}); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+	if err := q.UpdateArticleScore(ctx, id, 85, "summary", "why", "model", []string{"Rust"}); err != nil {
+		t.Fatalf("UpdateArticleScore() error = %v", err)
+	}
+
+	tags, err := q.GetArticleTags(ctx, id)
+	if err != nil {
+		t.Fatalf("GetArticleTags() error = %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "Forced" || tags[1] != "Rust" {
+		t.Errorf("GetArticleTags() = %v, want [Forced Rust]", tags)
+	}
+}
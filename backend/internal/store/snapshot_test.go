@@ -0,0 +1,263 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/secret"
+)
+
+// openMigrated opens databases built by the real migrations rather than
+// the hand-written test schema.
+func openMigrated(t *testing.T) func() *sql.DB {
+	t.Helper()
+	// Migrations are found relative to the repository root.
+	t.Chdir("../../..")
+	return func() *sql.DB {
+		t.Helper()
+		db, err := Open(filepath.Join(t.TempDir(), "synapse.db"))
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+}
+
+func TestSnapshot_CoversSchema(t *testing.T) {
+	db := openMigrated(t)()
+
+	skipped := map[string]bool{"schema_migrations": true, "webhook_deliveries": true}
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		t.Fatalf("listing tables: %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		if !skipped[name] {
+			tables = append(tables, name)
+		}
+	}
+	rows.Close()
+
+	for _, name := range tables {
+		i := slices.IndexFunc(snapshotTables, func(st snapshotTable) bool { return st.name == name })
+		if i < 0 {
+			t.Errorf("table %s is missing from snapshotTables", name)
+			continue
+		}
+		var want []string
+		cols, err := db.Query(`SELECT name FROM pragma_table_info(?)`, name)
+		if err != nil {
+			t.Fatalf("listing columns of %s: %v", name, err)
+		}
+		for cols.Next() {
+			var col string
+			cols.Scan(&col)
+			want = append(want, col)
+		}
+		cols.Close()
+
+		var got []string
+		for _, c := range snapshotTables[i].columns {
+			got = append(got, c.name)
+		}
+		slices.Sort(want)
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("snapshot columns of %s = %v, want %v", name, got, want)
+		}
+	}
+	if len(tables) != len(snapshotTables) {
+		t.Errorf("snapshotTables has %d tables, the schema %d", len(snapshotTables), len(tables))
+	}
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	open := openMigrated(t)
+	box, err := secret.NewBox("test key")
+	if err != nil {
+		t.Fatalf("NewBox() error = %v", err)
+	}
+	src := NewQueries(open()).WithSecretBox(box)
+	ctx := context.Background()
+
+	cat, err := src.CreateCategory(ctx, "Systems", 60)
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	feed, err := src.CreateFeed(ctx, "https://example.com/feed", "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if _, err := src.UpdateFeed(ctx, feed.ID, core.FeedPatch{CategoryID: &cat.ID}); err != nil {
+		t.Fatalf("UpdateFeed() error = %v", err)
+	}
+	if err := src.SaveFeedSettings(ctx, core.FeedSettings{FeedID: feed.ID, Username: "me", Password: "hunter2"}); err != nil {
+		t.Fatalf("SaveFeedSettings() error = %v", err)
+	}
+
+	var ids []int64
+	for i, title := range []string{"Kept", "Dismissed"} {
+		id, _, err := src.CreateArticle(ctx, core.Article{
+			FeedID: feed.ID, Title: title, URL: "https://example.com/" + title, GUID: title,
+			PublishedAt: time.Date(2026, 5, i+1, 12, 0, 0, 0, time.UTC),
+			Categories:  []string{"Databases"},
+			Authors:     []core.Author{{Name: "Ada", Email: "ada@example.com"}},
+			Enclosures:  []core.Enclosure{{URL: "https://example.com/a.mp3", MIMEType: "audio/mpeg", Length: 42}},
+		})
+		if err != nil {
+			t.Fatalf("CreateArticle() error = %v", err)
+		}
+		if err := src.UpdateArticleScore(ctx, id, 85, "Summary", "Why", "model", []string{"Go", "SQL"}); err != nil {
+			t.Fatalf("UpdateArticleScore() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := src.AddArticleTags(ctx, ids[0], []string{"Pinned"}); err != nil {
+		t.Fatalf("AddArticleTags() error = %v", err)
+	}
+	if err := src.SaveArticleContent(ctx, ids[0], "<p>Body</p>"); err != nil {
+		t.Fatalf("SaveArticleContent() error = %v", err)
+	}
+	if err := src.MarkArticleRead(ctx, ids[0]); err != nil {
+		t.Fatalf("MarkArticleRead() error = %v", err)
+	}
+	if _, err := src.ToggleArticleSaved(ctx, ids[0]); err != nil {
+		t.Fatalf("ToggleArticleSaved() error = %v", err)
+	}
+	if err := src.DismissArticle(ctx, ids[1]); err != nil {
+		t.Fatalf("DismissArticle() error = %v", err)
+	}
+	if _, err := src.CreateIngestRule(ctx, core.IngestRule{Field: "title", MatchType: "contains", Pattern: "Go", Action: "tag", Tags: []string{"Go"}, Enabled: true}); err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
+	if _, err := src.CreateWebhook(ctx, core.Webhook{URL: "https://hooks.example.com", Secret: "s3cret", Events: []string{"article.scored"}, Enabled: true}); err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	if _, err := src.CreateNotificationChannel(ctx, core.NotificationChannel{
+		Name: "Team", Type: core.ChannelSlack, URL: "https://hooks.slack.com/services/X", MinScore: 90, FeedIDs: []int64{feed.ID}, RateLimit: 10, Enabled: true,
+	}); err != nil {
+		t.Fatalf("CreateNotificationChannel() error = %v", err)
+	}
+	if err := src.SetPreferences(ctx, core.Preferences{FollowedTags: []string{"Go"}, MutedTags: []core.MutedTag{{Tag: "Frontend", OverrideScore: 90}}}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	snap, err := src.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(snap); err != nil {
+		t.Fatalf("encoding snapshot: %v", err)
+	}
+	exported := buf.String()
+	if strings.Contains(exported, "hunter2") || strings.Contains(exported, "s3cret") {
+		t.Error("snapshot holds plaintext secrets; they should stay sealed")
+	}
+
+	read, err := ReadSnapshot(strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	dst := NewQueries(open()).WithSecretBox(box)
+	if err := dst.Restore(ctx, read); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	again, err := dst.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() of the restored database error = %v", err)
+	}
+	again.ExportedAt = snap.ExportedAt
+	if !reflect.DeepEqual(jsonValue(t, again), jsonValue(t, snap)) {
+		t.Errorf("restored database differs:\n got %s\nwant %s", mustJSON(t, again), exported)
+	}
+
+	article, err := dst.GetArticleByID(ctx, ids[0])
+	if err != nil {
+		t.Fatalf("GetArticleByID() error = %v", err)
+	}
+	if !article.IsRead || !article.ReadLater || article.QualityRank != 85 || article.Content != "<p>Body</p>" || len(article.Authors) != 1 {
+		t.Errorf("restored article = %+v", article)
+	}
+	if tags, _ := dst.GetArticleTags(ctx, ids[0]); len(tags) != 3 {
+		t.Errorf("restored tags = %v, want Go, SQL and Pinned", tags)
+	}
+	if _, err := dst.GetArticleByID(ctx, ids[1]); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("dismissed article error = %v, want it to stay dismissed", err)
+	}
+	if prefs, err := dst.GetPreferences(ctx); err != nil || len(prefs.FollowedTags) != 1 || len(prefs.MutedTags) != 1 {
+		t.Errorf("restored preferences = %+v, %v; want Go followed and Frontend muted", prefs, err)
+	}
+	settings, err := dst.GetFeedSettings(ctx, feed.ID)
+	if err != nil || settings.Password != "hunter2" {
+		t.Errorf("restored feed settings = %+v, %v; want the password to open with the same key", settings, err)
+	}
+
+	if err := dst.Restore(ctx, read); !errors.Is(err, core.ErrConflict) {
+		t.Errorf("Restore() into a database with feeds error = %v, want ErrConflict", err)
+	}
+}
+
+func TestReadSnapshot_Invalid(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`{"format": "something-else", "version": 1, "tables": {}}`,
+		`{"format": "synapse-snapshot", "version": 2, "tables": {}}`,
+	}
+	for _, input := range tests {
+		if _, err := ReadSnapshot(strings.NewReader(input)); !errors.Is(err, core.ErrBadRequest) {
+			t.Errorf("ReadSnapshot(%s) error = %v, want ErrBadRequest", input, err)
+		}
+	}
+
+	open := openMigrated(t)
+	q := NewQueries(open())
+	bad := []string{
+		`{"format": "synapse-snapshot", "version": 1, "tables": {"users": []}}`,
+		`{"format": "synapse-snapshot", "version": 1, "tables": {"feeds": [{"id": 1, "url": "https://x", "colour": "red"}]}}`,
+		`{"format": "synapse-snapshot", "version": 1, "tables": {"feeds": [{"id": "one", "url": "https://x"}]}}`,
+	}
+	for _, input := range bad {
+		snap, err := ReadSnapshot(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ReadSnapshot(%s) error = %v", input, err)
+		}
+		if err := q.Restore(context.Background(), snap); !errors.Is(err, core.ErrBadRequest) {
+			t.Errorf("Restore(%s) error = %v, want ErrBadRequest", input, err)
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return string(b)
+}
+
+// jsonValue compares snapshots the way they are written out.
+func jsonValue(t *testing.T, v any) any {
+	t.Helper()
+	var out any
+	if err := json.Unmarshal([]byte(mustJSON(t, v)), &out); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return out
+}
@@ -9,34 +9,95 @@ import (
 
 type FeedStore interface {
 	CreateFeed(ctx context.Context, url, name string) (core.Feed, error)
+	CreateFeedWithType(ctx context.Context, url, name, sourceType string) (core.Feed, error)
 	DeleteFeed(ctx context.Context, id int64) error
 	MarkFeedForDeletion(ctx context.Context, id int64) error
-	GetFeedsPendingDeletion(ctx context.Context) ([]core.Feed, error)
+	RestoreFeed(ctx context.Context, id int64) (core.Feed, error)
+	GetFeedsPendingDeletion(ctx context.Context, cutoff time.Time) ([]core.Feed, error)
 	GetAllFeeds(ctx context.Context) ([]core.Feed, error)
+	GetFeed(ctx context.Context, id int64) (core.Feed, error)
+	GetFeedByURL(ctx context.Context, url string) (core.Feed, error)
+	GetFeedStats(ctx context.Context, feedID int64) (core.FeedStats, error)
 	GetFeedsToSync(ctx context.Context, limit int) ([]core.Feed, error)
 	UpdateFeedHeaders(ctx context.Context, id int64, etag, lastModified string, lastSyncedAt time.Time) error
 	UpdateFeedName(ctx context.Context, id int64, name string) error
+	UpdateFeed(ctx context.Context, id int64, patch core.FeedPatch) (core.Feed, error)
+	RecordFeedSyncError(ctx context.Context, id int64, message string) error
+	GetFeedSettings(ctx context.Context, feedID int64) (core.FeedSettings, error)
+	SaveFeedSettings(ctx context.Context, settings core.FeedSettings) error
 }
 
 type ArticleStore interface {
-	CreateArticle(ctx context.Context, article core.Article) (int64, error)
+	CreateArticle(ctx context.Context, article core.Article) (id int64, created bool, err error)
 	DeleteOldArticles(ctx context.Context, horizon time.Time) (int64, error)
 	DeleteArticlesByFeedID(ctx context.Context, feedID int64) error
 	GetUnscoredArticles(ctx context.Context, limit int) ([]core.Article, error)
 	UpdateArticleScore(ctx context.Context, id int64, rank int, summary, justification, model string, tags []string) error
-	GetTopArticles(ctx context.Context, limit, offset int) ([]core.Article, int, error)
+	ListArticles(ctx context.Context, filter core.ArticleFilter, cursor string, limit int) (core.ArticlePage, error)
+	BulkUpdateArticles(ctx context.Context, filter core.ArticleFilter, action core.BulkAction, tags []string) ([]int64, error)
 	GetArticleByID(ctx context.Context, id int64) (*core.Article, error)
-	GetArticlesByTags(ctx context.Context, tags []string, limit int) ([]core.Article, error)
+	GetArticlesByFeed(ctx context.Context, feedID int64, limit, offset int) ([]core.Article, int, error)
 	GetAllTags(ctx context.Context) ([]core.TagCount, error)
+	GetRelatedTags(ctx context.Context, filter core.ArticleFilter, limit int) ([]core.TagCount, error)
+	GetPreferences(ctx context.Context) (core.Preferences, error)
+	SetPreferences(ctx context.Context, prefs core.Preferences) error
 	GetArticleTags(ctx context.Context, articleID int64) ([]string, error)
 	MarkArticleRead(ctx context.Context, id int64) error
 	MarkArticleUnread(ctx context.Context, id int64) error
 	ToggleArticleSaved(ctx context.Context, id int64) (bool, error)
-	GetSavedArticles(ctx context.Context) ([]core.Article, error)
 	DeleteArticle(ctx context.Context, id int64) error
+	DismissArticle(ctx context.Context, id int64) error
+	RestoreArticle(ctx context.Context, id int64) error
+	PurgeDismissedArticles(ctx context.Context, cutoff time.Time) (int64, error)
+	PruneTombstones(ctx context.Context, horizon time.Time) (int64, error)
+	DropArticle(ctx context.Context, article core.Article) (bool, error)
+	RejectArticle(ctx context.Context, id int64, score int) error
+	AddArticleTags(ctx context.Context, id int64, tags []string) error
+	SaveArticleContent(ctx context.Context, id int64, content string) error
+}
+
+type RuleStore interface {
+	CreateIngestRule(ctx context.Context, rule core.IngestRule) (core.IngestRule, error)
+	GetIngestRule(ctx context.Context, id int64) (core.IngestRule, error)
+	GetIngestRules(ctx context.Context) ([]core.IngestRule, error)
+	GetIngestRulesForFeed(ctx context.Context, feedID int64) ([]core.IngestRule, error)
+	UpdateIngestRule(ctx context.Context, rule core.IngestRule) error
+	DeleteIngestRule(ctx context.Context, id int64) error
+	RecordRuleMatches(ctx context.Context, counts map[int64]int) error
+}
+
+type CategoryStore interface {
+	CreateCategory(ctx context.Context, name string, minScore int) (core.Category, error)
+	GetOrCreateCategory(ctx context.Context, name string) (core.Category, error)
+	GetCategory(ctx context.Context, id int64) (core.Category, error)
+	GetCategories(ctx context.Context) ([]core.Category, error)
+	UpdateCategory(ctx context.Context, category core.Category) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, hook core.Webhook) (core.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (core.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]core.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook core.Webhook) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	RecordWebhookDelivery(ctx context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error)
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]core.WebhookDelivery, error)
+}
+
+type NotificationStore interface {
+	CreateNotificationChannel(ctx context.Context, ch core.NotificationChannel) (core.NotificationChannel, error)
+	GetNotificationChannel(ctx context.Context, id int64) (core.NotificationChannel, error)
+	GetNotificationChannels(ctx context.Context) ([]core.NotificationChannel, error)
+	UpdateNotificationChannel(ctx context.Context, ch core.NotificationChannel) error
+	DeleteNotificationChannel(ctx context.Context, id int64) error
 }
 
 type Store interface {
 	FeedStore
 	ArticleStore
+	RuleStore
+	CategoryStore
+	WebhookStore
+	NotificationStore
 }
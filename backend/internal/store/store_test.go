@@ -45,7 +45,20 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 			etag TEXT,
 			last_modified TEXT,
 			status TEXT DEFAULT 'active',
-			last_synced_at DATETIME
+			source_type TEXT DEFAULT 'rss',
+			last_synced_at DATETIME,
+			last_sync_error TEXT DEFAULT '',
+			last_attempted_at DATETIME,
+			category_id INTEGER REFERENCES categories(id),
+			deleted_at DATETIME,
+			status_before_delete TEXT DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS categories (
+			id INTEGER PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL,
+			min_score INTEGER DEFAULT 0,
+			created_at DATETIME NOT NULL
 		);
 
 		CREATE TABLE IF NOT EXISTS articles (
@@ -58,7 +71,34 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 			summary TEXT,
 			justification TEXT,
 			is_read BOOLEAN DEFAULT 0,
-			read_later BOOLEAN DEFAULT 0
+			read_later BOOLEAN DEFAULT 0,
+			guid TEXT DEFAULT '',
+			updated_at DATETIME,
+			image_url TEXT DEFAULT '',
+			categories TEXT DEFAULT '',
+			deleted_at DATETIME,
+			scored_at DATETIME
+		);
+
+		CREATE TABLE IF NOT EXISTS authors (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL DEFAULT '',
+			UNIQUE (name, email)
+		);
+
+		CREATE TABLE IF NOT EXISTS article_authors (
+			article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+			author_id INTEGER NOT NULL REFERENCES authors(id),
+			PRIMARY KEY (article_id, author_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS enclosures (
+			id INTEGER PRIMARY KEY,
+			article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+			url TEXT NOT NULL,
+			mime_type TEXT DEFAULT '',
+			length INTEGER DEFAULT 0
 		);
 
 		CREATE TABLE IF NOT EXISTS tags (
@@ -69,10 +109,109 @@ func setupTestDB(t *testing.T) (*sql.DB, func()) {
 		CREATE TABLE IF NOT EXISTS article_tags (
 			article_id INTEGER NOT NULL REFERENCES articles(id),
 			tag_id INTEGER NOT NULL REFERENCES tags(id),
+			pinned BOOLEAN DEFAULT 0,
 			PRIMARY KEY (article_id, tag_id)
 		);
 
+		CREATE TABLE IF NOT EXISTS feed_settings (
+			feed_id INTEGER PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+			headers TEXT DEFAULT '',
+			username TEXT DEFAULT '',
+			password TEXT DEFAULT '',
+			user_agent TEXT DEFAULT '',
+			proxy_url TEXT DEFAULT '',
+			tls_insecure_skip_verify BOOLEAN DEFAULT 0,
+			tls_server_name TEXT DEFAULT ''
+		);
+
+		CREATE TABLE IF NOT EXISTS article_content (
+			article_id INTEGER PRIMARY KEY REFERENCES articles(id) ON DELETE CASCADE,
+			content TEXT,
+			judge_model TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS rejected_articles (
+			id INTEGER PRIMARY KEY,
+			feed_id INTEGER NOT NULL REFERENCES feeds(id),
+			score INTEGER NOT NULL,
+			published_at DATETIME,
+			rejected_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS tombstones (
+			id INTEGER PRIMARY KEY,
+			feed_id INTEGER NOT NULL,
+			url TEXT NOT NULL,
+			guid TEXT DEFAULT '',
+			published_at DATETIME,
+			reason TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_tombstones_url ON tombstones (url);
+
+		CREATE TABLE IF NOT EXISTS ingest_rules (
+			id INTEGER PRIMARY KEY,
+			feed_id INTEGER REFERENCES feeds(id),
+			field TEXT NOT NULL,
+			match_type TEXT NOT NULL,
+			pattern TEXT NOT NULL,
+			action TEXT NOT NULL,
+			tags TEXT DEFAULT '',
+			score INTEGER DEFAULT 0,
+			enabled BOOLEAN DEFAULT 1,
+			match_count INTEGER DEFAULT 0,
+			last_matched_at DATETIME,
+			created_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id INTEGER PRIMARY KEY,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			events TEXT NOT NULL,
+			min_score INTEGER DEFAULT 0,
+			tags TEXT DEFAULT '',
+			enabled BOOLEAN DEFAULT 1,
+			created_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id INTEGER PRIMARY KEY,
+			webhook_id INTEGER NOT NULL REFERENCES webhooks(id),
+			event_id INTEGER NOT NULL,
+			event TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status_code INTEGER DEFAULT 0,
+			attempts INTEGER DEFAULT 0,
+			error TEXT DEFAULT '',
+			duration_ms INTEGER DEFAULT 0,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
+
+		CREATE TABLE IF NOT EXISTS notification_channels (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			url TEXT NOT NULL,
+			room_id TEXT DEFAULT '',
+			token TEXT DEFAULT '',
+			min_score INTEGER DEFAULT 90,
+			tags TEXT DEFAULT '',
+			feed_ids TEXT DEFAULT '',
+			rate_limit INTEGER DEFAULT 10,
+			enabled BOOLEAN DEFAULT 1,
+			created_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS tag_preferences (
+			tag TEXT PRIMARY KEY,
+			muted BOOLEAN NOT NULL DEFAULT 0,
+			override_score INTEGER DEFAULT 0
+		);
+
 		CREATE INDEX IF NOT EXISTS idx_articles_quality_rank ON articles (quality_rank DESC);
+		CREATE INDEX IF NOT EXISTS idx_articles_scored_at ON articles (scored_at);
 		CREATE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
 		CREATE INDEX IF NOT EXISTS idx_article_tags_tag_id ON article_tags (tag_id);
 	`
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Tombstone reasons.
+const (
+	tombstoneDismissed = "dismissed"
+	tombstoneRejected  = "rejected"
+	tombstoneDropped   = "dropped"
+)
+
+// tombstoneArticles remembers the URL and GUID of the articles matching
+// where so CreateArticle won't bring them back once the rows are gone.
+func tombstoneArticles(ctx context.Context, tx *sql.Tx, reason, where string, args ...any) error {
+	query := `
+		INSERT INTO tombstones (feed_id, url, guid, published_at, reason, created_at)
+		SELECT feed_id, url, COALESCE(guid, ''), published_at, ?, ? FROM articles WHERE ` + where + `
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, append([]any{reason, time.Now()}, args...)...); err != nil {
+		return fmt.Errorf("recording tombstones: %w", err)
+	}
+	return nil
+}
+
+// isTombstoned matches on URL, or on GUID within the same feed, the same way
+// CreateArticle detects duplicates.
+func isTombstoned(ctx context.Context, tx *sql.Tx, article core.Article) (bool, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM tombstones
+		WHERE url = ? OR (? != '' AND feed_id = ? AND guid = ?)
+		LIMIT 1
+	`, article.URL, article.GUID, article.FeedID, article.GUID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking tombstones: %w", err)
+	}
+	return true, nil
+}
+
+// DropArticle keeps a tombstone for an item an ingest rule dropped, so later
+// syncs skip it. It reports false when the feed already had the item, stored
+// or as a tombstone, so each drop is counted once.
+func (q *Queries) DropArticle(ctx context.Context, article core.Article) (bool, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if gone, err := isTombstoned(ctx, tx, article); err != nil || gone {
+		return false, err
+	}
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM articles
+		WHERE feed_id = ? AND (url = ? OR (? != '' AND guid = ?))
+		LIMIT 1
+	`, article.FeedID, article.URL, article.GUID, article.GUID).Scan(&id)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("looking up existing article: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tombstones (feed_id, url, guid, published_at, reason, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING`,
+		article.FeedID, article.URL, article.GUID, article.PublishedAt, tombstoneDropped, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("recording tombstone: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("committing transaction: %w", err)
+	}
+	return true, nil
+}
+
+// PruneTombstones forgets items published before horizon. The syncer skips
+// anything that old, so their tombstones can no longer match.
+func (q *Queries) PruneTombstones(ctx context.Context, horizon time.Time) (int64, error) {
+	res, err := q.db.ExecContext(ctx, `DELETE FROM tombstones WHERE published_at < ?`, horizon)
+	if err != nil {
+		return 0, fmt.Errorf("pruning tombstones: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return count, nil
+}
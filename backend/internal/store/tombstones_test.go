@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestTombstones_BlockReingestion(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+
+	published := time.Now().Add(-48 * time.Hour)
+	rejected, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID: feed.ID, Title: "Rejected", URL: "https://example.com/rejected",
+		GUID: "guid-rejected", PublishedAt: published,
+	})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	dismissed, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID: feed.ID, Title: "Dismissed", URL: "https://example.com/dismissed", PublishedAt: published,
+	})
+	if err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+
+	if err := q.RejectArticle(ctx, rejected, 20); err != nil {
+		t.Fatalf("RejectArticle() error = %v", err)
+	}
+	if err := q.DismissArticle(ctx, dismissed); err != nil {
+		t.Fatalf("DismissArticle() error = %v", err)
+	}
+	if _, err := q.PurgeDismissedArticles(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("PurgeDismissedArticles() error = %v", err)
+	}
+
+	again := []core.Article{
+		{FeedID: feed.ID, Title: "Rejected", URL: "https://example.com/rejected", PublishedAt: published},
+		// Same GUID under a new tracking URL.
+		{FeedID: feed.ID, Title: "Rejected", URL: "https://example.com/rejected?utm=1", GUID: "guid-rejected", PublishedAt: published},
+		{FeedID: feed.ID, Title: "Dismissed", URL: "https://example.com/dismissed", PublishedAt: published},
+	}
+	for _, a := range again {
+		id, _, err := q.CreateArticle(ctx, a)
+		if err != nil {
+			t.Fatalf("CreateArticle(%s) error = %v", a.URL, err)
+		}
+		if id != 0 {
+			t.Errorf("CreateArticle(%s) = %d, want 0 for a tombstoned item", a.URL, id)
+		}
+	}
+
+	// A GUID only blocks within its own feed.
+	other, err := q.CreateFeed(ctx, "https://example.com/other", "Other Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if id, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID: other.ID, Title: "Other", URL: "https://example.com/other-item", GUID: "guid-rejected", PublishedAt: published,
+	}); err != nil || id == 0 {
+		t.Errorf("CreateArticle() in another feed = %d, %v, want a new article", id, err)
+	}
+
+	pruned, err := q.PruneTombstones(ctx, time.Now().Add(-72*time.Hour))
+	if err != nil {
+		t.Fatalf("PruneTombstones() error = %v", err)
+	}
+	if pruned != 0 {
+		t.Errorf("PruneTombstones() inside the horizon pruned %d, want 0", pruned)
+	}
+	pruned, err = q.PruneTombstones(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("PruneTombstones() error = %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("PruneTombstones() pruned %d, want 2", pruned)
+	}
+
+	id, _, err := q.CreateArticle(ctx, core.Article{
+		FeedID: feed.ID, Title: "Dismissed", URL: "https://example.com/dismissed", PublishedAt: published,
+	})
+	if err != nil || id == 0 {
+		t.Errorf("CreateArticle() after pruning = %d, %v, want a new article", id, err)
+	}
+}
+
+func TestDropArticle(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	feed, err := q.CreateFeed(ctx, "https://example.com/feed", "Test Feed")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	stored := core.Article{FeedID: feed.ID, Title: "Stored", URL: "https://example.com/stored", PublishedAt: time.Now()}
+	if _, _, err := q.CreateArticle(ctx, stored); err != nil {
+		t.Fatalf("CreateArticle() error = %v", err)
+	}
+	junk := core.Article{FeedID: feed.ID, Title: "Sponsored", URL: "https://example.com/sponsored", GUID: "ad-1", PublishedAt: time.Now()}
+
+	tests := []struct {
+		name    string
+		article core.Article
+		want    bool
+	}{
+		{"new item", junk, true},
+		{"dropped again", junk, false},
+		{"same GUID, new URL", core.Article{FeedID: feed.ID, URL: "https://example.com/sponsored?utm=1", GUID: "ad-1", PublishedAt: time.Now()}, false},
+		{"already stored", stored, false},
+	}
+	for _, tt := range tests {
+		if got, err := q.DropArticle(ctx, tt.article); err != nil || got != tt.want {
+			t.Errorf("DropArticle() %s = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+	if id, _, err := q.CreateArticle(ctx, junk); err != nil || id != 0 {
+		t.Errorf("CreateArticle() of a dropped item = %d, %v; want 0", id, err)
+	}
+}
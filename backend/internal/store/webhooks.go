@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// deliveryLogSize is how many deliveries are kept per webhook.
+const deliveryLogSize = 100
+
+const webhookColumns = `id, url, secret, events, min_score, tags, enabled, created_at`
+
+// CreateWebhook stores a webhook with its secret sealed, which needs
+// SECRET_KEY like feed credentials do.
+func (q *Queries) CreateWebhook(ctx context.Context, hook core.Webhook) (core.Webhook, error) {
+	secret, events, tags, err := q.encodeWebhook(hook)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+
+	hook.CreatedAt = time.Now()
+	query := `
+		INSERT INTO webhooks (url, secret, events, min_score, tags, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = q.db.QueryRowContext(ctx, query,
+		hook.URL, secret, events, hook.MinScore, tags, hook.Enabled, hook.CreatedAt,
+	).Scan(&hook.ID)
+	if err != nil {
+		return core.Webhook{}, fmt.Errorf("inserting webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, id int64) (core.Webhook, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return core.Webhook{}, fmt.Errorf("querying webhook: %w", err)
+	}
+	defer rows.Close()
+
+	hooks, err := q.scanWebhooks(rows)
+	if err != nil {
+		return core.Webhook{}, err
+	}
+	if len(hooks) == 0 {
+		return core.Webhook{}, core.ErrNotFound
+	}
+	return hooks[0], nil
+}
+
+func (q *Queries) GetWebhooks(ctx context.Context) ([]core.Webhook, error) {
+	rows, err := q.read.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	return q.scanWebhooks(rows)
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, hook core.Webhook) error {
+	secret, events, tags, err := q.encodeWebhook(hook)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = ?, secret = ?, events = ?, min_score = ?, tags = ?, enabled = ?
+		WHERE id = ?
+	`
+	res, err := q.db.ExecContext(ctx, query,
+		hook.URL, secret, events, hook.MinScore, tags, hook.Enabled, hook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating webhook: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log.
+func (q *Queries) DeleteWebhook(ctx context.Context, id int64) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return core.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("deleting webhook deliveries: %w", err)
+	}
+	return tx.Commit()
+}
+
+// RecordWebhookDelivery appends to a webhook's delivery log, keeping only
+// the most recent deliveryLogSize entries.
+func (q *Queries) RecordWebhookDelivery(ctx context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.WebhookDelivery{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	d.CreatedAt = time.Now()
+	query := `
+		INSERT INTO webhook_deliveries
+			(webhook_id, event_id, event, payload, status_code, attempts, error, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query,
+		d.WebhookID, int64(d.EventID), d.Event, d.Payload, d.StatusCode, d.Attempts,
+		d.Error, d.Duration.Milliseconds(), d.CreatedAt,
+	).Scan(&d.ID)
+	if err != nil {
+		return core.WebhookDelivery{}, fmt.Errorf("inserting webhook delivery: %w", err)
+	}
+
+	prune := `
+		DELETE FROM webhook_deliveries
+		WHERE webhook_id = ? AND id NOT IN (
+			SELECT id FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?
+		)
+	`
+	if _, err := tx.ExecContext(ctx, prune, d.WebhookID, d.WebhookID, deliveryLogSize); err != nil {
+		return core.WebhookDelivery{}, fmt.Errorf("pruning webhook deliveries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return core.WebhookDelivery{}, fmt.Errorf("commit: %w", err)
+	}
+	return d, nil
+}
+
+// GetWebhookDeliveries returns a webhook's most recent deliveries, newest
+// first.
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) ([]core.WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, event_id, event, payload, status_code, attempts, error, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`
+	rows, err := q.read.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []core.WebhookDelivery
+	for rows.Next() {
+		var d core.WebhookDelivery
+		var eventID, durationMS int64
+		if err := rows.Scan(&d.ID, &d.WebhookID, &eventID, &d.Event, &d.Payload, &d.StatusCode,
+			&d.Attempts, &d.Error, &durationMS, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning webhook delivery: %w", err)
+		}
+		d.EventID = uint64(eventID)
+		d.Duration = time.Duration(durationMS) * time.Millisecond
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (q *Queries) scanWebhooks(rows *sql.Rows) ([]core.Webhook, error) {
+	var hooks []core.Webhook
+	for rows.Next() {
+		var h core.Webhook
+		var secret, events, tags string
+		if err := rows.Scan(&h.ID, &h.URL, &secret, &events, &h.MinScore, &tags,
+			&h.Enabled, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning webhook: %w", err)
+		}
+
+		var err error
+		if h.Secret, err = q.box.Open(secret); err != nil {
+			return nil, fmt.Errorf("opening webhook secret: %w", err)
+		}
+		if err := json.Unmarshal([]byte(events), &h.Events); err != nil {
+			return nil, fmt.Errorf("decoding webhook events: %w", err)
+		}
+		if tags != "" {
+			if err := json.Unmarshal([]byte(tags), &h.Tags); err != nil {
+				return nil, fmt.Errorf("decoding webhook tags: %w", err)
+			}
+		}
+		hooks = append(hooks, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+	return hooks, nil
+}
+
+func (q *Queries) encodeWebhook(hook core.Webhook) (secret, events, tags string, err error) {
+	if secret, err = q.seal(hook.Secret); err != nil {
+		return "", "", "", err
+	}
+	b, err := json.Marshal(hook.Events)
+	if err != nil {
+		return "", "", "", fmt.Errorf("encoding webhook events: %w", err)
+	}
+	if tags, err = encodeRuleTags(hook.Tags); err != nil {
+		return "", "", "", err
+	}
+	return secret, string(b), tags, nil
+}
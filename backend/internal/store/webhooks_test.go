@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/secret"
+)
+
+func TestWebhooks_CRUD(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	hook := core.Webhook{URL: "https://example.com/hook", Secret: "s3cret", Events: []string{"article.scored"}, Enabled: true}
+	if _, err := q.CreateWebhook(ctx, hook); !errors.Is(err, core.ErrBadRequest) {
+		t.Errorf("CreateWebhook() without SECRET_KEY error = %v, want ErrBadRequest", err)
+	}
+
+	box, err := secret.NewBox("test-key")
+	if err != nil {
+		t.Fatalf("NewBox() error = %v", err)
+	}
+	q = q.WithSecretBox(box)
+
+	hook.MinScore = 70
+	hook.Tags = []string{"Go"}
+	created, err := q.CreateWebhook(ctx, hook)
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+
+	var stored string
+	if err := q.db.QueryRowContext(ctx, `SELECT secret FROM webhooks WHERE id = ?`, created.ID).Scan(&stored); err != nil {
+		t.Fatalf("querying secret: %v", err)
+	}
+	if stored == "s3cret" {
+		t.Error("webhook secret stored in plaintext")
+	}
+
+	got, err := q.GetWebhook(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetWebhook() error = %v", err)
+	}
+	if got.Secret != "s3cret" || got.MinScore != 70 || len(got.Events) != 1 || len(got.Tags) != 1 || !got.Enabled {
+		t.Errorf("GetWebhook() = %+v, want the created webhook", got)
+	}
+
+	got.Events = []string{"feed.failed", "feed.added"}
+	got.Enabled = false
+	if err := q.UpdateWebhook(ctx, got); err != nil {
+		t.Fatalf("UpdateWebhook() error = %v", err)
+	}
+	hooks, err := q.GetWebhooks(ctx)
+	if err != nil {
+		t.Fatalf("GetWebhooks() error = %v", err)
+	}
+	if len(hooks) != 1 || len(hooks[0].Events) != 2 || hooks[0].Enabled {
+		t.Errorf("GetWebhooks() = %+v, want the updated webhook", hooks)
+	}
+
+	if err := q.DeleteWebhook(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteWebhook() error = %v", err)
+	}
+	if _, err := q.GetWebhook(ctx, created.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("GetWebhook() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := q.UpdateWebhook(ctx, got); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("UpdateWebhook() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := q.DeleteWebhook(ctx, created.ID); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("DeleteWebhook() twice error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	q, cleanup := setupTestQueries(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for i := 1; i <= deliveryLogSize+5; i++ {
+		d := core.WebhookDelivery{
+			WebhookID: 1, EventID: uint64(i), Event: "article.scored",
+			Payload: fmt.Sprintf(`{"id":%d}`, i), StatusCode: 200, Attempts: 1, Duration: 42 * time.Millisecond,
+		}
+		if _, err := q.RecordWebhookDelivery(ctx, d); err != nil {
+			t.Fatalf("RecordWebhookDelivery() error = %v", err)
+		}
+	}
+	if _, err := q.RecordWebhookDelivery(ctx, core.WebhookDelivery{WebhookID: 2, EventID: 1, Event: "feed.added", Payload: "{}", Error: "timeout", Attempts: 3}); err != nil {
+		t.Fatalf("RecordWebhookDelivery() error = %v", err)
+	}
+
+	all, err := q.GetWebhookDeliveries(ctx, 1, 1000)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries() error = %v", err)
+	}
+	if len(all) != deliveryLogSize {
+		t.Fatalf("GetWebhookDeliveries() returned %d deliveries, want %d", len(all), deliveryLogSize)
+	}
+	if all[0].EventID != deliveryLogSize+5 || all[0].Duration != 42*time.Millisecond || all[0].StatusCode != 200 {
+		t.Errorf("newest delivery = %+v, want event %d in 42ms with status 200", all[0], deliveryLogSize+5)
+	}
+
+	other, err := q.GetWebhookDeliveries(ctx, 2, 10)
+	if err != nil {
+		t.Fatalf("GetWebhookDeliveries() error = %v", err)
+	}
+	if len(other) != 1 || other[0].Error != "timeout" || other[0].Attempts != 3 {
+		t.Errorf("GetWebhookDeliveries(2) = %+v, want one failed delivery", other)
+	}
+}
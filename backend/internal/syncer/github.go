@@ -0,0 +1,109 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// GitHubReleases reads published releases of a repository, e.g.
+// https://github.com/golang/go/releases. Private repositories or higher rate
+// limits need a bearer token in the feed settings.
+type GitHubReleases struct {
+	BaseURL string
+}
+
+func NewGitHubReleases() *GitHubReleases {
+	return &GitHubReleases{BaseURL: "https://api.github.com"}
+}
+
+type githubRelease struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	TagName     string    `json:"tag_name"`
+	HTMLURL     string    `json:"html_url"`
+	Body        string    `json:"body"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+func (g *GitHubReleases) Fetch(ctx context.Context, client *sourceClient, feed core.Feed) ([]core.Article, error) {
+	owner, repo := githubRepo(feed.URL)
+	if owner == "" {
+		return nil, fmt.Errorf("no owner/repo in %q", feed.URL)
+	}
+
+	limit := intParam(queryParams(feed.URL), "limit", 20)
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases?per_page=%s",
+		g.BaseURL, url.PathEscape(owner), url.PathEscape(repo), strconv.Itoa(limit))
+
+	var releases []githubRelease
+	if err := client.getJSON(ctx, endpoint, &releases); err != nil {
+		return nil, err
+	}
+
+	articles := make([]core.Article, 0, len(releases))
+	for _, rel := range releases {
+		if rel.Draft {
+			continue
+		}
+
+		name := rel.Name
+		if name == "" {
+			name = rel.TagName
+		}
+
+		categories := []string{owner + "/" + repo}
+		if rel.Prerelease {
+			categories = append(categories, "prerelease")
+		}
+
+		summary := fmt.Sprintf("Release %s of %s/%s.", rel.TagName, owner, repo)
+		if rel.Body != "" {
+			summary += "\n\n" + rel.Body
+		}
+
+		article := core.Article{
+			FeedID:      feed.ID,
+			Title:       fmt.Sprintf("%s %s", repo, name),
+			URL:         rel.HTMLURL,
+			PublishedAt: rel.PublishedAt,
+			Summary:     summary,
+			Content:     summary,
+			GUID:        "github-release:" + strconv.FormatInt(rel.ID, 10),
+			Categories:  categories,
+		}
+		if rel.Author.Login != "" {
+			article.Authors = []core.Author{{Name: rel.Author.Login}}
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+func (g *GitHubReleases) DefaultName(feedURL string) string {
+	if owner, repo := githubRepo(feedURL); owner != "" {
+		return owner + "/" + repo + " releases"
+	}
+	return "GitHub releases"
+}
+
+func githubRepo(feedURL string) (owner, repo string) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "", ""
+	}
+	segments := pathSegments(u.Path)
+	if len(segments) < 2 {
+		return "", ""
+	}
+	return segments[0], segments[1]
+}
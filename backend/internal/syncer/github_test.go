@@ -0,0 +1,59 @@
+package syncer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestGitHubReleases_Fetch(t *testing.T) {
+	server, client, requested := serveFixture(t, "github_releases.json")
+
+	g := &GitHubReleases{BaseURL: server.URL}
+	feed := core.Feed{ID: 9, URL: "https://github.com/acme/widget/releases"}
+
+	articles, err := g.Fetch(context.Background(), client, feed)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if *requested != "/repos/acme/widget/releases?per_page=20" {
+		t.Errorf("requested %q, want /repos/acme/widget/releases?per_page=20", *requested)
+	}
+
+	// Drafts are skipped
+	if len(articles) != 2 {
+		t.Fatalf("Fetch() returned %d articles, want 2", len(articles))
+	}
+
+	stable := articles[0]
+	if stable.Title != "widget v1.3.0" {
+		t.Errorf("Title = %v, want widget v1.3.0", stable.Title)
+	}
+	if stable.GUID != "github-release:155000001" {
+		t.Errorf("GUID = %v, want github-release:155000001", stable.GUID)
+	}
+	if !strings.Contains(stable.Summary, "Faster compaction") {
+		t.Errorf("Summary = %q, want release notes", stable.Summary)
+	}
+	if len(stable.Authors) != 1 || stable.Authors[0].Name != "octocat" {
+		t.Errorf("Authors = %v, want octocat", stable.Authors)
+	}
+
+	rc := articles[1]
+	if rc.Title != "widget v1.4.0-rc1" {
+		t.Errorf("Title = %v, want tag name fallback", rc.Title)
+	}
+	if len(rc.Categories) != 2 || rc.Categories[1] != "prerelease" {
+		t.Errorf("Categories = %v, want prerelease", rc.Categories)
+	}
+}
+
+func TestGitHubReleases_DefaultName(t *testing.T) {
+	g := NewGitHubReleases()
+	if got := g.DefaultName("https://github.com/golang/go"); got != "golang/go releases" {
+		t.Errorf("DefaultName() = %v, want golang/go releases", got)
+	}
+}
@@ -0,0 +1,105 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// HackerNews reads the front page through the Algolia search API.
+// Feed URL parameters: points (minimum score) and limit (default 30),
+// e.g. https://news.ycombinator.com/?points=100&limit=20.
+type HackerNews struct {
+	BaseURL string
+}
+
+func NewHackerNews() *HackerNews {
+	return &HackerNews{BaseURL: "https://hn.algolia.com"}
+}
+
+type hnResponse struct {
+	Hits []struct {
+		ObjectID    string `json:"objectID"`
+		Title       string `json:"title"`
+		URL         string `json:"url"`
+		Author      string `json:"author"`
+		Points      int    `json:"points"`
+		NumComments int    `json:"num_comments"`
+		StoryText   string `json:"story_text"`
+		CreatedAtI  int64  `json:"created_at_i"`
+	} `json:"hits"`
+}
+
+func (h *HackerNews) Fetch(ctx context.Context, client *sourceClient, feed core.Feed) ([]core.Article, error) {
+	params := queryParams(feed.URL)
+	limit := intParam(params, "limit", 30)
+	minPoints := intParam(params, "points", 0)
+
+	q := url.Values{}
+	q.Set("tags", "front_page")
+	q.Set("hitsPerPage", strconv.Itoa(limit))
+	if minPoints > 0 {
+		q.Set("numericFilters", fmt.Sprintf("points>=%d", minPoints))
+	}
+
+	var resp hnResponse
+	if err := client.getJSON(ctx, h.BaseURL+"/api/v1/search?"+q.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	articles := make([]core.Article, 0, len(resp.Hits))
+	for _, hit := range resp.Hits {
+		discussion := "https://news.ycombinator.com/item?id=" + hit.ObjectID
+		link := hit.URL
+		if link == "" {
+			link = discussion
+		}
+
+		summary := fmt.Sprintf("Hacker News front page: %d points, %d comments. Discussion: %s",
+			hit.Points, hit.NumComments, discussion)
+		if hit.StoryText != "" {
+			summary = hit.StoryText + "\n\n" + summary
+		}
+
+		article := core.Article{
+			FeedID:      feed.ID,
+			Title:       hit.Title,
+			URL:         link,
+			PublishedAt: time.Unix(hit.CreatedAtI, 0),
+			Summary:     summary,
+			Content:     summary,
+			GUID:        "hn:" + hit.ObjectID,
+		}
+		if hit.Author != "" {
+			article.Authors = []core.Author{{Name: hit.Author}}
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+func (h *HackerNews) DefaultName(feedURL string) string {
+	if points := intParam(queryParams(feedURL), "points", 0); points > 0 {
+		return fmt.Sprintf("Hacker News (%d+ points)", points)
+	}
+	return "Hacker News"
+}
+
+func queryParams(rawURL string) url.Values {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return url.Values{}
+	}
+	return u.Query()
+}
+
+func intParam(params url.Values, key string, fallback int) int {
+	if v, err := strconv.Atoi(params.Get(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,67 @@
+package syncer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestHackerNews_Fetch(t *testing.T) {
+	server, client, requested := serveFixture(t, "hackernews_front_page.json")
+
+	hn := &HackerNews{BaseURL: server.URL}
+	feed := core.Feed{ID: 7, URL: "https://news.ycombinator.com/?points=100&limit=10"}
+
+	articles, err := hn.Fetch(context.Background(), client, feed)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if !strings.Contains(*requested, "hitsPerPage=10") || !strings.Contains(*requested, "points%3E%3D100") {
+		t.Errorf("requested %q, want hitsPerPage=10 and points>=100", *requested)
+	}
+
+	if len(articles) != 2 {
+		t.Fatalf("Fetch() returned %d articles, want 2", len(articles))
+	}
+
+	story := articles[0]
+	if story.URL != "https://example.com/postgres-storage" {
+		t.Errorf("URL = %v, want the linked article", story.URL)
+	}
+	if story.GUID != "hn:40356789" {
+		t.Errorf("GUID = %v, want hn:40356789", story.GUID)
+	}
+	if story.FeedID != 7 {
+		t.Errorf("FeedID = %v, want 7", story.FeedID)
+	}
+	if len(story.Authors) != 1 || story.Authors[0].Name != "pgfan" {
+		t.Errorf("Authors = %v, want pgfan", story.Authors)
+	}
+	if story.PublishedAt.Unix() != 1715702531 {
+		t.Errorf("PublishedAt = %v, want 1715702531", story.PublishedAt.Unix())
+	}
+	if !strings.Contains(story.Summary, "412 points") {
+		t.Errorf("Summary = %q, want points", story.Summary)
+	}
+
+	ask := articles[1]
+	if ask.URL != "https://news.ycombinator.com/item?id=40355555" {
+		t.Errorf("Ask HN URL = %v, want discussion link", ask.URL)
+	}
+	if !strings.HasPrefix(ask.Summary, "<p>Our team struggles") {
+		t.Errorf("Ask HN Summary = %q, want story text first", ask.Summary)
+	}
+}
+
+func TestHackerNews_DefaultName(t *testing.T) {
+	hn := NewHackerNews()
+	if got := hn.DefaultName("https://news.ycombinator.com/"); got != "Hacker News" {
+		t.Errorf("DefaultName() = %v, want Hacker News", got)
+	}
+	if got := hn.DefaultName("https://news.ycombinator.com/?points=200"); got != "Hacker News (200+ points)" {
+		t.Errorf("DefaultName() = %v, want Hacker News (200+ points)", got)
+	}
+}
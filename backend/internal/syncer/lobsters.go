@@ -0,0 +1,112 @@
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Lobsters reads a tag page, e.g. https://lobste.rs/t/databases, or the
+// hottest stories when no tag is given. Multiple tags can be joined with
+// commas as on the site itself.
+type Lobsters struct {
+	BaseURL string
+}
+
+func NewLobsters() *Lobsters {
+	return &Lobsters{BaseURL: "https://lobste.rs"}
+}
+
+type lobstersStory struct {
+	ShortID      string          `json:"short_id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Title        string          `json:"title"`
+	URL          string          `json:"url"`
+	Score        int             `json:"score"`
+	CommentCount int             `json:"comment_count"`
+	Description  string          `json:"description"`
+	CommentsURL  string          `json:"comments_url"`
+	Submitter    json.RawMessage `json:"submitter_user"`
+	Tags         []string        `json:"tags"`
+}
+
+// submitter handles both the current string form and the older object form.
+func (s lobstersStory) submitter() string {
+	var name string
+	if err := json.Unmarshal(s.Submitter, &name); err == nil {
+		return name
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(s.Submitter, &user); err == nil {
+		return user.Username
+	}
+	return ""
+}
+
+func (l *Lobsters) Fetch(ctx context.Context, client *sourceClient, feed core.Feed) ([]core.Article, error) {
+	endpoint := l.BaseURL + "/hottest.json"
+	if tag := lobstersTag(feed.URL); tag != "" {
+		endpoint = l.BaseURL + "/t/" + url.PathEscape(tag) + ".json"
+	}
+
+	var stories []lobstersStory
+	if err := client.getJSON(ctx, endpoint, &stories); err != nil {
+		return nil, err
+	}
+
+	articles := make([]core.Article, 0, len(stories))
+	for _, story := range stories {
+		link := story.URL
+		if link == "" {
+			link = story.CommentsURL
+		}
+
+		summary := fmt.Sprintf("Lobsters: %d points, %d comments. Discussion: %s",
+			story.Score, story.CommentCount, story.CommentsURL)
+		if story.Description != "" {
+			summary = story.Description + "\n\n" + summary
+		}
+
+		article := core.Article{
+			FeedID:      feed.ID,
+			Title:       story.Title,
+			URL:         link,
+			PublishedAt: story.CreatedAt,
+			Summary:     summary,
+			Content:     summary,
+			GUID:        "lobsters:" + story.ShortID,
+			Categories:  story.Tags,
+		}
+		if name := story.submitter(); name != "" {
+			article.Authors = []core.Author{{Name: name}}
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+func (l *Lobsters) DefaultName(feedURL string) string {
+	if tag := lobstersTag(feedURL); tag != "" {
+		return "Lobsters: " + tag
+	}
+	return "Lobsters"
+}
+
+func lobstersTag(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+	segments := pathSegments(u.Path)
+	if len(segments) >= 2 && segments[0] == "t" {
+		return strings.TrimSuffix(segments[1], ".json")
+	}
+	return ""
+}
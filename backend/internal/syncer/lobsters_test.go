@@ -0,0 +1,65 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestLobsters_Fetch(t *testing.T) {
+	server, client, requested := serveFixture(t, "lobsters_tag.json")
+
+	l := &Lobsters{BaseURL: server.URL}
+	feed := core.Feed{ID: 3, URL: "https://lobste.rs/t/databases"}
+
+	articles, err := l.Fetch(context.Background(), client, feed)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if *requested != "/t/databases.json" {
+		t.Errorf("requested %q, want /t/databases.json", *requested)
+	}
+
+	if len(articles) != 2 {
+		t.Fatalf("Fetch() returned %d articles, want 2", len(articles))
+	}
+
+	story := articles[0]
+	if story.URL != "https://example.org/wal" {
+		t.Errorf("URL = %v, want https://example.org/wal", story.URL)
+	}
+	if story.GUID != "lobsters:abc123" {
+		t.Errorf("GUID = %v, want lobsters:abc123", story.GUID)
+	}
+	if len(story.Categories) != 2 || story.Categories[0] != "databases" {
+		t.Errorf("Categories = %v, want [databases distributed]", story.Categories)
+	}
+	if len(story.Authors) != 1 || story.Authors[0].Name != "dbhacker" {
+		t.Errorf("Authors = %v, want dbhacker", story.Authors)
+	}
+	if story.PublishedAt.IsZero() {
+		t.Error("PublishedAt is zero")
+	}
+
+	text := articles[1]
+	if text.URL != "https://lobste.rs/s/def456/what_are_you_working_on_this_week" {
+		t.Errorf("text post URL = %v, want comments URL", text.URL)
+	}
+	if len(text.Authors) != 1 || text.Authors[0].Name != "mod" {
+		t.Errorf("Authors = %v, want mod from object form", text.Authors)
+	}
+}
+
+func TestLobsters_Hottest(t *testing.T) {
+	server, client, requested := serveFixture(t, "lobsters_tag.json")
+
+	l := &Lobsters{BaseURL: server.URL}
+	if _, err := l.Fetch(context.Background(), client, core.Feed{URL: "https://lobste.rs/"}); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if *requested != "/hottest.json" {
+		t.Errorf("requested %q, want /hottest.json", *requested)
+	}
+}
@@ -0,0 +1,132 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+)
+
+// Reddit reads a subreddit listing, e.g. https://www.reddit.com/r/golang
+// (hot) or https://www.reddit.com/r/golang/top?t=week&limit=10.
+type Reddit struct {
+	BaseURL string
+}
+
+func NewReddit() *Reddit {
+	return &Reddit{BaseURL: "https://www.reddit.com"}
+}
+
+type redditListing struct {
+	Data struct {
+		Children []struct {
+			Data struct {
+				ID          string  `json:"id"`
+				Title       string  `json:"title"`
+				URL         string  `json:"url"`
+				Permalink   string  `json:"permalink"`
+				CreatedUTC  float64 `json:"created_utc"`
+				Selftext    string  `json:"selftext"`
+				Author      string  `json:"author"`
+				Score       int     `json:"score"`
+				NumComments int     `json:"num_comments"`
+				IsSelf      bool    `json:"is_self"`
+				Stickied    bool    `json:"stickied"`
+				Flair       string  `json:"link_flair_text"`
+				Subreddit   string  `json:"subreddit"`
+			} `json:"data"`
+		} `json:"children"`
+	} `json:"data"`
+}
+
+func (r *Reddit) Fetch(ctx context.Context, client *sourceClient, feed core.Feed) ([]core.Article, error) {
+	sub, sort := redditPath(feed.URL)
+	if sub == "" {
+		return nil, fmt.Errorf("no subreddit in %q", feed.URL)
+	}
+
+	params := queryParams(feed.URL)
+	q := url.Values{}
+	q.Set("limit", strconv.Itoa(intParam(params, "limit", 25)))
+	q.Set("raw_json", "1")
+	if t := params.Get("t"); t != "" {
+		q.Set("t", t)
+	}
+
+	endpoint := fmt.Sprintf("%s/r/%s/%s.json?%s", r.BaseURL, url.PathEscape(sub), sort, q.Encode())
+
+	var listing redditListing
+	if err := client.getJSON(ctx, endpoint, &listing); err != nil {
+		return nil, err
+	}
+
+	articles := make([]core.Article, 0, len(listing.Data.Children))
+	for _, child := range listing.Data.Children {
+		post := child.Data
+		if post.Stickied {
+			continue
+		}
+
+		discussion := "https://www.reddit.com" + post.Permalink
+		link := post.URL
+		if post.IsSelf || link == "" {
+			link = discussion
+		}
+
+		summary := fmt.Sprintf("r/%s: %d points, %d comments. Discussion: %s",
+			post.Subreddit, post.Score, post.NumComments, discussion)
+		if post.Selftext != "" {
+			summary = post.Selftext + "\n\n" + summary
+		}
+
+		article := core.Article{
+			FeedID:      feed.ID,
+			Title:       post.Title,
+			URL:         link,
+			PublishedAt: time.Unix(int64(post.CreatedUTC), 0),
+			Summary:     summary,
+			Content:     summary,
+			GUID:        "reddit:" + post.ID,
+		}
+		if post.Author != "" {
+			article.Authors = []core.Author{{Name: post.Author}}
+		}
+		if post.Flair != "" {
+			article.Categories = []string{post.Flair}
+		}
+		articles = append(articles, article)
+	}
+	return articles, nil
+}
+
+func (r *Reddit) DefaultName(feedURL string) string {
+	if sub, _ := redditPath(feedURL); sub != "" {
+		return "r/" + sub
+	}
+	return "Reddit"
+}
+
+// redditPath extracts the subreddit and listing sort from /r/{sub}[/{sort}].
+func redditPath(feedURL string) (sub, sort string) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return "", ""
+	}
+
+	segments := pathSegments(u.Path)
+	if len(segments) < 2 || segments[0] != "r" {
+		return "", ""
+	}
+
+	sort = "hot"
+	if len(segments) >= 3 {
+		switch segments[2] {
+		case "hot", "new", "top", "rising":
+			sort = segments[2]
+		}
+	}
+	return segments[1], sort
+}
@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+func TestReddit_Fetch(t *testing.T) {
+	server, client, requested := serveFixture(t, "reddit_listing.json")
+
+	r := &Reddit{BaseURL: server.URL}
+	feed := core.Feed{ID: 5, URL: "https://www.reddit.com/r/golang/top?t=week&limit=10"}
+
+	articles, err := r.Fetch(context.Background(), client, feed)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if !strings.HasPrefix(*requested, "/r/golang/top.json?") ||
+		!strings.Contains(*requested, "limit=10") || !strings.Contains(*requested, "t=week") {
+		t.Errorf("requested %q, want /r/golang/top.json with limit and t", *requested)
+	}
+
+	// The stickied thread is skipped
+	if len(articles) != 2 {
+		t.Fatalf("Fetch() returned %d articles, want 2", len(articles))
+	}
+
+	link := articles[0]
+	if link.URL != "https://example.dev/go-scheduler" {
+		t.Errorf("URL = %v, want external link", link.URL)
+	}
+	if link.GUID != "reddit:1cr111" {
+		t.Errorf("GUID = %v, want reddit:1cr111", link.GUID)
+	}
+	if len(link.Categories) != 1 || link.Categories[0] != "Internals" {
+		t.Errorf("Categories = %v, want flair", link.Categories)
+	}
+	if link.PublishedAt.Unix() != 1715650000 {
+		t.Errorf("PublishedAt = %v, want 1715650000", link.PublishedAt.Unix())
+	}
+
+	self := articles[1]
+	if !strings.HasPrefix(self.URL, "https://www.reddit.com/r/golang/comments/1cr222/") {
+		t.Errorf("self post URL = %v, want permalink", self.URL)
+	}
+	if !strings.HasPrefix(self.Summary, "I wrote an LSM-based store") {
+		t.Errorf("self post Summary = %q, want selftext first", self.Summary)
+	}
+}
+
+func TestReddit_MissingSubreddit(t *testing.T) {
+	server, client, _ := serveFixture(t, "reddit_listing.json")
+
+	r := &Reddit{BaseURL: server.URL}
+	if _, err := r.Fetch(context.Background(), client, core.Feed{URL: "https://www.reddit.com/"}); err == nil {
+		t.Error("Fetch() without subreddit succeeded, want error")
+	}
+}
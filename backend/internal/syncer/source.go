@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const (
+	TypeRSS            = "rss"
+	TypeHackerNews     = "hackernews"
+	TypeLobsters       = "lobsters"
+	TypeReddit         = "reddit"
+	TypeGitHubReleases = "github-releases"
+
+	// TypeNewsletter feeds are virtual: they are keyed by the sender's
+	// mailto: URL and filled by the newsletter receiver instead of polling.
+	TypeNewsletter = "newsletter"
+)
+
+// Source fetches items from an origin that isn't an RSS/Atom feed and maps
+// them into articles. The subscription's URL carries the source parameters,
+// e.g. https://lobste.rs/t/databases or https://github.com/golang/go.
+type Source interface {
+	Fetch(ctx context.Context, client *sourceClient, feed core.Feed) ([]core.Article, error)
+	DefaultName(feedURL string) string
+}
+
+func defaultSources() map[string]Source {
+	return map[string]Source{
+		TypeHackerNews:     NewHackerNews(),
+		TypeLobsters:       NewLobsters(),
+		TypeReddit:         NewReddit(),
+		TypeGitHubReleases: NewGitHubReleases(),
+	}
+}
+
+// SourceTypes lists the supported subscription types.
+var SourceTypes = []string{TypeRSS, TypeHackerNews, TypeLobsters, TypeReddit, TypeGitHubReleases, TypeNewsletter}
+
+// ValidSourceType reports whether t names a supported subscription type.
+func ValidSourceType(t string) bool {
+	return slices.Contains(SourceTypes, t)
+}
+
+// DetectSourceType guesses the subscription type from a URL. Anything that
+// looks like a feed file or an unknown host is treated as RSS.
+func DetectSourceType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return TypeRSS
+	}
+	if u.Scheme == "mailto" {
+		return TypeNewsletter
+	}
+
+	path := strings.ToLower(u.Path)
+	if strings.HasSuffix(path, ".rss") || strings.HasSuffix(path, ".atom") || strings.HasSuffix(path, ".xml") {
+		return TypeRSS
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := pathSegments(u.Path)
+
+	switch host {
+	case "news.ycombinator.com":
+		return TypeHackerNews
+	case "lobste.rs":
+		return TypeLobsters
+	case "reddit.com", "old.reddit.com":
+		if len(segments) >= 2 && segments[0] == "r" {
+			return TypeReddit
+		}
+	case "github.com":
+		if len(segments) == 2 || (len(segments) == 3 && segments[2] == "releases") {
+			return TypeGitHubReleases
+		}
+	}
+	return TypeRSS
+}
+
+// sourceClient performs requests with the feed's settings applied.
+type sourceClient struct {
+	http     *http.Client
+	settings core.FeedSettings
+}
+
+func (c *sourceClient) getJSON(ctx context.Context, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	applySettings(req, c.settings)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned error: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 10<<20)).Decode(v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+func pathSegments(p string) []string {
+	var segments []string
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
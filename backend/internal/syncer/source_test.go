@@ -0,0 +1,61 @@
+package syncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serveFixture serves a recorded API response and records the request URI
+// the adapter asked for.
+func serveFixture(t *testing.T, file string) (*httptest.Server, *sourceClient, *string) {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("request missing User-Agent")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &sourceClient{http: server.Client()}, &requested
+}
+
+func TestDetectSourceType(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://news.ycombinator.com/", TypeHackerNews},
+		{"https://news.ycombinator.com/?points=100", TypeHackerNews},
+		{"https://lobste.rs/t/databases", TypeLobsters},
+		{"https://lobste.rs/t/databases.rss", TypeRSS},
+		{"https://www.reddit.com/r/golang", TypeReddit},
+		{"https://old.reddit.com/r/golang/top?t=week", TypeReddit},
+		{"https://www.reddit.com/r/golang/.rss", TypeRSS},
+		{"https://github.com/golang/go", TypeGitHubReleases},
+		{"https://github.com/golang/go/releases", TypeGitHubReleases},
+		{"https://github.com/golang/go/releases.atom", TypeRSS},
+		{"https://github.com/golang/go/issues", TypeRSS},
+		{"https://go.dev/blog/feed.atom", TypeRSS},
+		{"https://blog.example.com/feed", TypeRSS},
+		{"mailto:news@example.com", TypeNewsletter},
+	}
+
+	for _, tt := range tests {
+		if got := DetectSourceType(tt.url); got != tt.want {
+			t.Errorf("DetectSourceType(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"dailysynapse/backend/internal/config"
 	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/rules"
 	"dailysynapse/backend/internal/store"
 
 	"github.com/mmcdole/gofeed"
@@ -18,20 +22,27 @@ import (
 type Syncer struct {
 	store    store.Store
 	fp       *gofeed.Parser
+	sources  map[string]Source
 	feedChan chan core.Feed
 	cfg      *config.Config
-	logger   *slog.Logger
+
+	mu      sync.Mutex // guards stopped and sends on feedChan
+	stopped bool
+	events  *events.Bus
+	logger  *slog.Logger
 }
 
-func New(s store.Store, cfg *config.Config, logger *slog.Logger) *Syncer {
+func New(s store.Store, cfg *config.Config, bus *events.Bus, logger *slog.Logger) *Syncer {
 	fp := gofeed.NewParser()
 	fp.Client = &http.Client{Timeout: cfg.HTTPTimeout}
 
 	return &Syncer{
 		store:    s,
 		fp:       fp,
+		sources:  defaultSources(),
 		feedChan: make(chan core.Feed, 100),
 		cfg:      cfg,
+		events:   bus,
 		logger:   logger,
 	}
 }
@@ -44,20 +55,27 @@ func (s *Syncer) StartBackgroundWorkers(ctx context.Context) {
 		go func(workerID int) {
 			defer wg.Done()
 			for feed := range s.feedChan {
-				if err := s.syncFeed(ctx, feed); err != nil {
+				added, err := s.syncFeed(ctx, feed)
+				if err != nil {
 					s.logger.Error("sync failed",
 						slog.Int("worker", workerID),
 						slog.String("feed", feed.Name),
 						slog.String("error", err.Error()),
 					)
+					if err := s.store.RecordFeedSyncError(ctx, feed.ID, err.Error()); err != nil {
+						s.logger.Error("failed to record sync error", slog.String("error", err.Error()))
+					}
+					s.events.Publish(events.FeedFailed, events.FeedSync{FeedID: feed.ID, Name: feed.Name, Error: err.Error()})
+					continue
 				}
+				s.events.Publish(events.FeedSynced, events.FeedSync{FeedID: feed.ID, Name: feed.Name, NewArticles: added})
 			}
 		}(i)
 	}
 
 	ticker := time.NewTicker(s.cfg.SyncInterval)
 	cleanupTicker := time.NewTicker(24 * time.Hour)
-	purgeTicker := time.NewTicker(1 * time.Hour)
+	purgeTicker := time.NewTicker(s.purgeInterval())
 	defer ticker.Stop()
 	defer cleanupTicker.Stop()
 	defer purgeTicker.Stop()
@@ -67,7 +85,10 @@ func (s *Syncer) StartBackgroundWorkers(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
+			s.mu.Lock()
+			s.stopped = true
 			close(s.feedChan)
+			s.mu.Unlock()
 			wg.Wait()
 			return
 		case <-ticker.C:
@@ -88,9 +109,7 @@ func (s *Syncer) TriggerSync(ctx context.Context) error {
 
 	go func() {
 		for _, feed := range feeds {
-			select {
-			case s.feedChan <- feed:
-			default:
+			if !s.Queue(feed) {
 				s.logger.Warn("worker queue full", slog.String("feed", feed.Name))
 			}
 		}
@@ -99,8 +118,39 @@ func (s *Syncer) TriggerSync(ctx context.Context) error {
 	return nil
 }
 
+// Queue hands one feed to the background workers, e.g. after its URL
+// changed. It reports false if the queue is full or the workers have
+// stopped.
+func (s *Syncer) Queue(feed core.Feed) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	select {
+	case s.feedChan <- feed:
+		return true
+	default:
+		return false
+	}
+}
+
+// purgeInterval checks hourly, or more often when the grace period is
+// shorter than that.
+func (s *Syncer) purgeInterval() time.Duration {
+	interval := time.Hour
+	if s.cfg.DeleteGracePeriod < interval {
+		interval = max(s.cfg.DeleteGracePeriod, time.Minute)
+	}
+	return interval
+}
+
+// purgeDeletedFeeds removes feeds and dismissed articles whose undo window
+// has closed.
 func (s *Syncer) purgeDeletedFeeds(ctx context.Context) {
-	feeds, err := s.store.GetFeedsPendingDeletion(ctx)
+	cutoff := time.Now().Add(-s.cfg.DeleteGracePeriod)
+
+	feeds, err := s.store.GetFeedsPendingDeletion(ctx, cutoff)
 	if err != nil {
 		s.logger.Error("failed to fetch feeds pending deletion", slog.String("error", err.Error()))
 		return
@@ -120,6 +170,15 @@ func (s *Syncer) purgeDeletedFeeds(ctx context.Context) {
 			s.logger.Info("purged feed", slog.String("name", feed.Name))
 		}
 	}
+
+	count, err := s.store.PurgeDismissedArticles(ctx, cutoff)
+	if err != nil {
+		s.logger.Error("failed to purge dismissed articles", slog.String("error", err.Error()))
+		return
+	}
+	if count > 0 {
+		s.logger.Info("purged dismissed articles", slog.Int64("deleted", count))
+	}
 }
 
 func (s *Syncer) runCleanup(ctx context.Context) {
@@ -135,15 +194,42 @@ func (s *Syncer) runCleanup(ctx context.Context) {
 	if count > 0 {
 		s.logger.Info("cleanup complete", slog.Int64("deleted", count))
 	}
+
+	// Tombstones outlive the articles: they must cover everything the
+	// syncer would still ingest.
+	horizon = time.Now().AddDate(0, 0, -s.cfg.ArticleHorizonDays)
+	pruned, err := s.store.PruneTombstones(ctx, horizon)
+	if err != nil {
+		s.logger.Error("failed to prune tombstones", slog.String("error", err.Error()))
+		return
+	}
+	if pruned > 0 {
+		s.logger.Info("pruned tombstones", slog.Int64("deleted", pruned))
+	}
 }
 
-func (s *Syncer) syncFeed(ctx context.Context, feed core.Feed) error {
+// syncFeed fetches one feed and returns how many new articles it stored.
+func (s *Syncer) syncFeed(ctx context.Context, feed core.Feed) (int, error) {
+	settings, err := s.store.GetFeedSettings(ctx, feed.ID)
+	if err != nil {
+		return 0, fmt.Errorf("loading feed settings: %w", err)
+	}
+
+	client, err := s.clientFor(settings)
+	if err != nil {
+		return 0, err
+	}
+
+	if src, ok := s.sources[feed.Type]; ok {
+		return s.syncSource(ctx, feed, src, &sourceClient{http: client, settings: settings})
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", feed.URL, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	req.Header.Set("User-Agent", "TheDailySynapse/1.0")
+	applySettings(req, settings)
 	if feed.Etag != "" {
 		req.Header.Set("If-None-Match", feed.Etag)
 	}
@@ -151,23 +237,23 @@ func (s *Syncer) syncFeed(ctx context.Context, feed core.Feed) error {
 		req.Header.Set("If-Modified-Since", feed.LastModified)
 	}
 
-	resp, err := s.fp.Client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotModified {
-		return s.store.UpdateFeedHeaders(ctx, feed.ID, feed.Etag, feed.LastModified, time.Now())
+		return 0, s.store.UpdateFeedHeaders(ctx, feed.ID, feed.Etag, feed.LastModified, time.Now())
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("server returned error: %s", resp.Status)
+		return 0, fmt.Errorf("server returned error: %s", resp.Status)
 	}
 
 	parsed, err := s.fp.Parse(resp.Body)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if parsed.Title != "" && feed.Name == "" {
@@ -177,50 +263,266 @@ func (s *Syncer) syncFeed(ctx context.Context, feed core.Feed) error {
 	newEtag := resp.Header.Get("ETag")
 	newLastMod := resp.Header.Get("Last-Modified")
 
-	horizon := time.Now().AddDate(0, 0, -s.cfg.ArticleHorizonDays)
-
+	articles := make([]core.Article, 0, len(parsed.Items))
 	for _, item := range parsed.Items {
-		published := item.PublishedParsed
-		if published == nil {
-			now := time.Now()
-			published = &now
-		}
+		articles = append(articles, articleFromItem(feed.ID, item))
+	}
 
-		if published.Before(horizon) {
-			continue
+	added, err := s.ingest(ctx, feed, parsed.Language, articles)
+	if err != nil {
+		return 0, err
+	}
+
+	return added, s.store.UpdateFeedHeaders(ctx, feed.ID, newEtag, newLastMod, time.Now())
+}
+
+func (s *Syncer) syncSource(ctx context.Context, feed core.Feed, src Source, client *sourceClient) (int, error) {
+	articles, err := src.Fetch(ctx, client, feed)
+	if err != nil {
+		return 0, fmt.Errorf("fetching %s source: %w", feed.Type, err)
+	}
+
+	if feed.Name == "" {
+		if name := src.DefaultName(feed.URL); name != "" {
+			s.store.UpdateFeedName(ctx, feed.ID, name)
 		}
+	}
 
-		// Use RSS description for scoring - no full page extraction needed
-		description := item.Description
-		if description == "" && item.Content != "" {
-			description = item.Content // Some feeds put content here
+	added, err := s.ingest(ctx, feed, "", articles)
+	if err != nil {
+		return 0, err
+	}
+
+	return added, s.store.UpdateFeedHeaders(ctx, feed.ID, "", "", time.Now())
+}
+
+// Ingest runs pushed articles through the same rules and storage path as
+// polled feeds. It is used by receivers that aren't driven by the sync loop.
+func (s *Syncer) Ingest(ctx context.Context, feed core.Feed, articles []core.Article) error {
+	_, err := s.ingest(ctx, feed, "", articles)
+	return err
+}
+
+// ingest filters a batch of fetched articles through the horizon, length and
+// ingest rule checks, stores the survivors and returns how many were new.
+func (s *Syncer) ingest(ctx context.Context, feed core.Feed, language string, articles []core.Article) (int, error) {
+	horizon := time.Now().AddDate(0, 0, -s.cfg.ArticleHorizonDays)
+
+	ingestRules, err := s.store.GetIngestRulesForFeed(ctx, feed.ID)
+	if err != nil {
+		return 0, fmt.Errorf("loading ingest rules: %w", err)
+	}
+	engine := rules.New(ingestRules)
+	ruleMatches := make(map[int64]int)
+	added := 0
+
+	for _, article := range articles {
+		if article.PublishedAt.Before(horizon) {
+			continue
 		}
 
 		// Skip articles with no description at all
-		if len(description) < 50 {
+		if len(article.Summary) < 50 {
 			s.logger.Debug("skipping article, insufficient description",
-				slog.String("url", item.Link),
-				slog.String("title", item.Title),
+				slog.String("url", article.URL),
+				slog.String("title", article.Title),
 			)
 			continue
 		}
 
-		article := core.Article{
-			FeedID:      feed.ID,
-			Title:       item.Title,
-			URL:         item.Link,
-			PublishedAt: *published,
-			Summary:     description,
-			Content:     description, // Use description for scoring
+		// Matches are counted once per item, not once per poll that lists it.
+		decision := engine.Evaluate(ruleItem(article, language))
+		countMatches := func() {
+			for _, id := range decision.Matched {
+				ruleMatches[id]++
+			}
+		}
+		if decision.Drop {
+			s.logger.Debug("dropping article by ingest rule",
+				slog.String("url", article.URL),
+				slog.String("title", article.Title),
+			)
+			dropped, err := s.store.DropArticle(ctx, article)
+			if err != nil {
+				s.logger.Error("failed to record dropped article",
+					slog.String("title", article.Title),
+					slog.String("error", err.Error()),
+				)
+			}
+			if dropped {
+				countMatches()
+			}
+			continue
 		}
 
-		if _, err := s.store.CreateArticle(ctx, article); err != nil {
+		id, created, err := s.store.CreateArticle(ctx, article)
+		if err != nil {
 			s.logger.Error("failed to save article",
-				slog.String("title", item.Title),
+				slog.String("title", article.Title),
 				slog.String("error", err.Error()),
 			)
+			continue
 		}
+		if id == 0 {
+			continue
+		}
+		if created {
+			added++
+		}
+		countMatches()
+		// Newsletters have no page to go back to, so the body is kept for the reader.
+		if feed.Type == TypeNewsletter && article.Content != "" {
+			if err := s.store.SaveArticleContent(ctx, id, article.Content); err != nil {
+				s.logger.Error("failed to save article content",
+					slog.Int64("article_id", id),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+		s.applyDecision(ctx, id, article, decision)
+	}
+
+	if err := s.store.RecordRuleMatches(ctx, ruleMatches); err != nil {
+		s.logger.Error("failed to record rule matches", slog.String("error", err.Error()))
 	}
+	return added, nil
+}
+
+func articleFromItem(feedID int64, item *gofeed.Item) core.Article {
+	published := time.Now()
+	if item.PublishedParsed != nil {
+		published = *item.PublishedParsed
+	}
+
+	// Use RSS description for scoring - no full page extraction needed
+	description := item.Description
+	if description == "" && item.Content != "" {
+		description = item.Content // Some feeds put content here
+	}
+
+	article := core.Article{
+		FeedID:      feedID,
+		Title:       item.Title,
+		URL:         item.Link,
+		PublishedAt: published,
+		Summary:     description,
+		Content:     description, // Use description for scoring
+		GUID:        item.GUID,
+		ImageURL:    leadImage(item),
+		Categories:  item.Categories,
+		Authors:     itemAuthors(item),
+		Enclosures:  itemEnclosures(item),
+	}
+	if item.UpdatedParsed != nil {
+		article.UpdatedAt = *item.UpdatedParsed
+	}
+	return article
+}
+
+// applyDecision pins rule tags and, for accepted items, records a score so
+// the judge never sees them.
+func (s *Syncer) applyDecision(ctx context.Context, id int64, article core.Article, decision rules.Decision) {
+	if decision.Accept {
+		err := s.store.UpdateArticleScore(ctx, id, decision.AcceptScore, article.Summary,
+			"Auto-accepted by ingest rule", "rules", nil)
+		if err != nil {
+			s.logger.Error("failed to auto-accept article",
+				slog.Int64("id", id),
+				slog.String("error", err.Error()),
+			)
+		} else {
+			s.events.Publish(events.ArticleScored, events.ArticleScore{
+				ArticleID: id,
+				FeedID:    article.FeedID,
+				Title:     article.Title,
+				URL:       article.URL,
+				Score:     decision.AcceptScore,
+				Summary:   article.Summary,
+				Tags:      decision.Tags,
+			})
+		}
+	}
+
+	if err := s.store.AddArticleTags(ctx, id, decision.Tags); err != nil {
+		s.logger.Error("failed to tag article",
+			slog.Int64("id", id),
+			slog.String("error", err.Error()),
+		)
+	}
+}
 
-	return s.store.UpdateFeedHeaders(ctx, feed.ID, newEtag, newLastMod, time.Now())
+func ruleItem(article core.Article, language string) rules.Item {
+	var authors []string
+	for _, a := range article.Authors {
+		if a.Name != "" {
+			authors = append(authors, a.Name)
+		}
+		if a.Email != "" {
+			authors = append(authors, a.Email)
+		}
+	}
+
+	return rules.Item{
+		Title:      article.Title,
+		URL:        article.URL,
+		Authors:    authors,
+		Categories: article.Categories,
+		Content:    article.Summary,
+		Language:   language,
+	}
+}
+
+func itemAuthors(item *gofeed.Item) []core.Author {
+	people := item.Authors
+	if len(people) == 0 && item.Author != nil {
+		people = []*gofeed.Person{item.Author}
+	}
+
+	var authors []core.Author
+	for _, p := range people {
+		if p == nil || (p.Name == "" && p.Email == "") {
+			continue
+		}
+		authors = append(authors, core.Author{Name: p.Name, Email: p.Email})
+	}
+	return authors
+}
+
+func itemEnclosures(item *gofeed.Item) []core.Enclosure {
+	var enclosures []core.Enclosure
+	for _, e := range item.Enclosures {
+		if e == nil || e.URL == "" {
+			continue
+		}
+		length, _ := strconv.ParseInt(e.Length, 10, 64)
+		enclosures = append(enclosures, core.Enclosure{URL: e.URL, MIMEType: e.Type, Length: length})
+	}
+	return enclosures
+}
+
+// leadImage picks the item's image, falling back to an image enclosure and
+// then to Media RSS thumbnails.
+func leadImage(item *gofeed.Item) string {
+	if item.Image != nil && item.Image.URL != "" {
+		return item.Image.URL
+	}
+
+	for _, e := range item.Enclosures {
+		if e != nil && e.URL != "" && strings.HasPrefix(e.Type, "image/") {
+			return e.URL
+		}
+	}
+
+	media := item.Extensions["media"]
+	for _, name := range []string{"thumbnail", "content"} {
+		for _, ext := range media[name] {
+			if url := ext.Attrs["url"]; url != "" {
+				if name == "content" && ext.Attrs["medium"] != "image" && !strings.HasPrefix(ext.Attrs["type"], "image/") {
+					continue
+				}
+				return url
+			}
+		}
+	}
+	return ""
 }
@@ -0,0 +1,169 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/internal/store"
+	"dailysynapse/backend/pkg/secret"
+)
+
+// newTestSyncer returns a syncer backed by a fresh, migrated database.
+func newTestSyncer(t *testing.T) (*Syncer, *store.Queries) {
+	t.Helper()
+	// Migrations are found relative to the repository root.
+	t.Chdir("../../..")
+	db, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	box, err := secret.NewBox("test key")
+	if err != nil {
+		t.Fatalf("secret.NewBox() error = %v", err)
+	}
+	q := store.NewQueries(db).WithSecretBox(box)
+	cfg := &config.Config{HTTPTimeout: time.Second, ArticleHorizonDays: 30}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(q, cfg, events.New(), logger), q
+}
+
+// atomEntry renders one entry of an Atom feed with a summary long enough to
+// be kept.
+func atomEntry(id, title string, updated time.Time) string {
+	return fmt.Sprintf(`<entry><id>%s</id><title>%s</title><link href="https://example.com/%s"/>
+<published>%s</published><updated>%s</updated>
+<summary>%s</summary></entry>`, id, title, id, updated.Format(time.RFC3339), updated.Format(time.RFC3339),
+		strings.Repeat("A long enough summary for the judge to work with. ", 2))
+}
+
+func TestSyncFeed_SameFeedTwice(t *testing.T) {
+	s, q := newTestSyncer(t)
+	ctx := context.Background()
+
+	published := time.Now().Add(-time.Hour).Truncate(time.Second)
+	entries := []string{atomEntry("one", "One", published), atomEntry("two", "Two", published)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Example</title>%s</feed>`, strings.Join(entries, ""))
+	}))
+	defer server.Close()
+
+	feed, err := q.CreateFeed(ctx, server.URL, "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if added, err := s.syncFeed(ctx, feed); err != nil || added != 2 {
+		t.Fatalf("first syncFeed() = %d, %v, want 2 new", added, err)
+	}
+
+	// One entry is revised and one is new; only the new one counts.
+	entries[0] = atomEntry("one", "One, revised", published.Add(time.Minute))
+	entries = append(entries, atomEntry("three", "Three", published))
+	if added, err := s.syncFeed(ctx, feed); err != nil || added != 1 {
+		t.Fatalf("second syncFeed() = %d, %v, want 1 new", added, err)
+	}
+
+	articles, total, err := q.GetArticlesByFeed(ctx, feed.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("GetArticlesByFeed() error = %v", err)
+	}
+	var titles []string
+	for _, a := range articles {
+		titles = append(titles, a.Title)
+	}
+	if total != 3 || !strings.Contains(strings.Join(titles, ","), "One, revised") {
+		t.Errorf("GetArticlesByFeed() = %d articles %v, want 3 with the revised title", total, titles)
+	}
+}
+
+func TestSyncFeed_RuleMatchesCountedOnce(t *testing.T) {
+	s, q := newTestSyncer(t)
+	ctx := context.Background()
+
+	published := time.Now().Add(-time.Hour).Truncate(time.Second)
+	entries := atomEntry("one", "Sponsored: a tool", published) + atomEntry("two", "Go internals", published)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Example</title>%s</feed>`, entries)
+	}))
+	defer server.Close()
+
+	if _, err := q.CreateFeed(ctx, server.URL, "Example"); err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	drop, err := q.CreateIngestRule(ctx, core.IngestRule{Field: "title", MatchType: "substring", Pattern: "sponsored", Action: "drop", Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
+	tag, err := q.CreateIngestRule(ctx, core.IngestRule{Field: "any", MatchType: "substring", Pattern: "a", Action: "tag", Tags: []string{"Seen"}, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateIngestRule() error = %v", err)
+	}
+
+	// Like the workers, each sync starts from the feed as stored.
+	for range 2 {
+		feeds, err := q.GetFeedsToSync(ctx, 1)
+		if err != nil || len(feeds) != 1 {
+			t.Fatalf("GetFeedsToSync() = %d feeds, %v, want 1", len(feeds), err)
+		}
+		if _, err := s.syncFeed(ctx, feeds[0]); err != nil {
+			t.Fatalf("syncFeed() error = %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		id   int64
+		want int
+	}{{drop.ID, 1}, {tag.ID, 2}} {
+		rule, err := q.GetIngestRule(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetIngestRule() error = %v", err)
+		}
+		if rule.MatchCount != tt.want {
+			t.Errorf("rule %s MatchCount = %d after two syncs, want %d", rule.Action, rule.MatchCount, tt.want)
+		}
+	}
+}
+
+func TestQueue(t *testing.T) {
+	s, _ := newTestSyncer(t)
+	s.cfg.SyncWorkers = 1
+	s.cfg.SyncInterval = time.Hour
+
+	feed := core.Feed{ID: 7, Name: "Example"}
+	if !s.Queue(feed) {
+		t.Fatal("Queue() = false, want the feed queued")
+	}
+	if got := <-s.feedChan; got.ID != feed.ID {
+		t.Errorf("queued feed %d, want %d", got.ID, feed.ID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.StartBackgroundWorkers(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	// Handlers may still queue feeds while the server shuts down.
+	if s.Queue(feed) {
+		t.Error("Queue() after the workers stopped = true, want false")
+	}
+}
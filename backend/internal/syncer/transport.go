@@ -0,0 +1,81 @@
+package syncer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/pkg/readability"
+)
+
+const defaultUserAgent = "TheDailySynapse/1.0"
+
+// newHTTPClient builds a client honouring the feed's proxy and TLS options.
+// Feeds without transport-level overrides share the syncer's default client.
+func newHTTPClient(settings core.FeedSettings, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if settings.ProxyURL != "" {
+		proxyURL, err := url.Parse(settings.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if settings.TLSInsecureSkipVerify || settings.TLSServerName != "" {
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: settings.TLSInsecureSkipVerify,
+			ServerName:         settings.TLSServerName,
+		}
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+func needsCustomTransport(settings core.FeedSettings) bool {
+	return settings.ProxyURL != "" || settings.TLSInsecureSkipVerify || settings.TLSServerName != ""
+}
+
+// applySettings sets the user agent, auth and extra headers on req.
+func applySettings(req *http.Request, settings core.FeedSettings) {
+	req.Header.Set("User-Agent", defaultUserAgent)
+	if settings.UserAgent != "" {
+		req.Header.Set("User-Agent", settings.UserAgent)
+	}
+	if settings.Username != "" || settings.Password != "" {
+		req.SetBasicAuth(settings.Username, settings.Password)
+	}
+	for k, v := range settings.Headers {
+		req.Header.Set(k, v)
+	}
+}
+
+func (s *Syncer) clientFor(settings core.FeedSettings) (*http.Client, error) {
+	if !needsCustomTransport(settings) {
+		return s.fp.Client, nil
+	}
+	return newHTTPClient(settings, s.cfg.HTTPTimeout)
+}
+
+// ExtractorFor returns a readability extractor that fetches with the given
+// feed's headers, credentials, proxy and TLS settings.
+func (s *Syncer) ExtractorFor(ctx context.Context, feedID int64) (readability.Extractor, error) {
+	settings, err := s.store.GetFeedSettings(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("loading feed settings: %w", err)
+	}
+
+	client, err := s.clientFor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return readability.NewExtractorWithClient(client, func(req *http.Request) {
+		applySettings(req, settings)
+	}), nil
+}
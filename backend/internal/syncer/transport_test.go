@@ -0,0 +1,136 @@
+package syncer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dailysynapse/backend/internal/core"
+)
+
+const emptyAtom = `<?xml version="1.0" encoding="utf-8"?><feed xmlns="http://www.w3.org/2005/Atom"><title>Example</title></feed>`
+
+// recordRequests serves an empty Atom feed and passes each request's
+// headers to seen.
+func recordRequests(t *testing.T, tls bool) (*httptest.Server, chan http.Header) {
+	t.Helper()
+	seen := make(chan http.Header, 4)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen <- r.Header.Clone()
+		w.Header().Set("Content-Type", "application/atom+xml")
+		io.WriteString(w, emptyAtom)
+	})
+	server := httptest.NewUnstartedServer(handler)
+	if tls {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return server, seen
+}
+
+func TestSyncFeed_Settings(t *testing.T) {
+	s, q := newTestSyncer(t)
+	ctx := context.Background()
+	server, seen := recordRequests(t, false)
+
+	feed, err := q.CreateFeed(ctx, server.URL, "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	settings := core.FeedSettings{
+		FeedID:    feed.ID,
+		Headers:   map[string]string{"X-Api-Key": "k3y"},
+		Username:  "reader",
+		Password:  "hunter2",
+		UserAgent: "CustomAgent/2.0",
+	}
+	if err := q.SaveFeedSettings(ctx, settings); err != nil {
+		t.Fatalf("SaveFeedSettings() error = %v", err)
+	}
+
+	check := func(name string, h http.Header) {
+		t.Helper()
+		req := http.Request{Header: h}
+		user, pass, ok := req.BasicAuth()
+		if !ok || user != "reader" || pass != "hunter2" {
+			t.Errorf("%s basic auth = %q, %q, %v; want the decrypted credentials", name, user, pass, ok)
+		}
+		if h.Get("X-Api-Key") != "k3y" || h.Get("User-Agent") != "CustomAgent/2.0" {
+			t.Errorf("%s headers = %v, want the feed's header and user agent", name, h)
+		}
+	}
+
+	if _, err := s.syncFeed(ctx, feed); err != nil {
+		t.Fatalf("syncFeed() error = %v", err)
+	}
+	check("syncFeed()", <-seen)
+
+	extractor, err := s.ExtractorFor(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("ExtractorFor() error = %v", err)
+	}
+	// The page is not an article; only the request matters here.
+	extractor.Extract(ctx, server.URL+"/post")
+	check("Extract()", <-seen)
+}
+
+func TestSyncFeed_DefaultSettings(t *testing.T) {
+	s, q := newTestSyncer(t)
+	ctx := context.Background()
+	server, seen := recordRequests(t, false)
+
+	feed, err := q.CreateFeed(ctx, server.URL, "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if _, err := s.syncFeed(ctx, feed); err != nil {
+		t.Fatalf("syncFeed() error = %v", err)
+	}
+	h := <-seen
+	if _, _, ok := (&http.Request{Header: h}).BasicAuth(); ok || h.Get("User-Agent") != defaultUserAgent {
+		t.Errorf("headers = %v, want no auth and the default user agent", h)
+	}
+
+	settings, err := q.GetFeedSettings(ctx, feed.ID)
+	if err != nil {
+		t.Fatalf("GetFeedSettings() error = %v", err)
+	}
+	if client, err := s.clientFor(settings); err != nil || client != s.fp.Client {
+		t.Errorf("clientFor() = %p, %v; want the shared default client %p", client, err, s.fp.Client)
+	}
+}
+
+func TestSyncFeed_TLSSettings(t *testing.T) {
+	s, q := newTestSyncer(t)
+	ctx := context.Background()
+	server, seen := recordRequests(t, true)
+
+	feed, err := q.CreateFeed(ctx, server.URL, "Example")
+	if err != nil {
+		t.Fatalf("CreateFeed() error = %v", err)
+	}
+	if _, err := s.syncFeed(ctx, feed); err == nil {
+		t.Fatal("syncFeed() against a self-signed certificate succeeded, want a verification error")
+	}
+
+	settings := core.FeedSettings{FeedID: feed.ID, TLSInsecureSkipVerify: true}
+	if err := q.SaveFeedSettings(ctx, settings); err != nil {
+		t.Fatalf("SaveFeedSettings() error = %v", err)
+	}
+	if _, err := s.syncFeed(ctx, feed); err != nil {
+		t.Fatalf("syncFeed() with verification off error = %v", err)
+	}
+	<-seen
+
+	client, err := s.clientFor(settings)
+	if err != nil {
+		t.Fatalf("clientFor() error = %v", err)
+	}
+	if client == s.fp.Client || client.Timeout != s.cfg.HTTPTimeout {
+		t.Errorf("clientFor() = %+v, want its own client with the configured timeout", client)
+	}
+}
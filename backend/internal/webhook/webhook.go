@@ -0,0 +1,381 @@
+// Package webhook posts bus events to user-configured HTTP endpoints. Each
+// request is signed with the webhook's secret so receivers can check it
+// came from us, retried with backoff, and recorded in a delivery log.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+	"dailysynapse/backend/pkg/retry"
+)
+
+const (
+	SignatureHeader = "X-Synapse-Signature"
+	TimestampHeader = "X-Synapse-Timestamp"
+	EventHeader     = "X-Synapse-Event"
+
+	userAgent = "TheDailySynapse/1.0"
+	// maxConcurrent bounds deliveries in flight across all webhooks.
+	maxConcurrent = 8
+	// resubscribeDelay spaces out resubscribing after the bus drops us.
+	resubscribeDelay = time.Second
+)
+
+// Events lists the event types a webhook can subscribe to.
+var Events = []events.Type{events.ArticleScored, events.ArticleSaved, events.FeedFailed, events.FeedAdded}
+
+func ValidEvent(name string) bool {
+	return slices.Contains(Events, events.Type(name))
+}
+
+func Validate(hook core.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", core.ErrBadRequest)
+	}
+	if hook.Secret == "" {
+		return fmt.Errorf("%w: secret is required", core.ErrBadRequest)
+	}
+	if len(hook.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", core.ErrBadRequest)
+	}
+	for _, name := range hook.Events {
+		if !ValidEvent(name) {
+			return fmt.Errorf("%w: unknown event %q", core.ErrBadRequest, name)
+		}
+	}
+	if hook.MinScore < 0 || hook.MinScore > 100 {
+		return fmt.Errorf("%w: min_score must be between 0 and 100", core.ErrBadRequest)
+	}
+	return nil
+}
+
+// Article is the data of article.scored and article.saved deliveries.
+type Article struct {
+	ID          int64     `json:"id"`
+	FeedID      int64     `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Score       int       `json:"score"`
+	Summary     string    `json:"summary"`
+	Tags        []string  `json:"tags"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+type Store interface {
+	GetWebhooks(ctx context.Context) ([]core.Webhook, error)
+	GetArticleByID(ctx context.Context, id int64) (*core.Article, error)
+	GetArticleTags(ctx context.Context, articleID int64) ([]string, error)
+	RecordWebhookDelivery(ctx context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error)
+}
+
+type Dispatcher struct {
+	store       Store
+	bus         *events.Bus
+	client      *http.Client
+	maxAttempts int
+	logger      *slog.Logger
+
+	sub *events.Subscription
+	sem chan struct{}
+	wg  sync.WaitGroup
+}
+
+// NewDispatcher subscribes to bus straight away, so events published
+// before Run starts are not missed.
+func NewDispatcher(store Store, bus *events.Bus, cfg *config.Config, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		bus:         bus,
+		client:      &http.Client{Timeout: cfg.WebhookTimeout},
+		maxAttempts: max(cfg.WebhookMaxAttempts, 1),
+		logger:      logger,
+		sub:         bus.Subscribe(0),
+		sem:         make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Run delivers events until ctx is cancelled, then waits for deliveries in
+// flight. If the bus drops the dispatcher for falling behind, it
+// resubscribes from the last event it handled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	defer d.wg.Wait()
+
+	var last uint64
+	for {
+		last = d.consume(ctx, d.sub, last)
+		d.sub.Close()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+		d.sub = d.bus.Subscribe(last)
+	}
+}
+
+func (d *Dispatcher) consume(ctx context.Context, sub *events.Subscription, last uint64) uint64 {
+	for {
+		select {
+		case <-ctx.Done():
+			return last
+		case ev, ok := <-sub.C:
+			if !ok {
+				return last
+			}
+			last = ev.ID
+			if ValidEvent(string(ev.Type)) || ev.Type == events.ArticlesBulk {
+				d.dispatch(ctx, ev)
+			}
+		}
+	}
+}
+
+// dispatch finds the webhooks that want ev and starts a delivery to each.
+// A bulk save is delivered as one article.saved per article, each carrying
+// the bulk event's ID.
+func (d *Dispatcher) dispatch(ctx context.Context, ev events.Event) {
+	typ := ev.Type
+	change, bulk := ev.Data.(events.BulkChange)
+	if bulk {
+		if change.Action != string(core.BulkSave) {
+			return
+		}
+		typ = events.ArticleSaved
+	}
+
+	hooks, err := d.store.GetWebhooks(ctx)
+	if err != nil {
+		d.logger.Error("failed to load webhooks", "event", typ, "error", err)
+		return
+	}
+	hooks = slices.DeleteFunc(hooks, func(h core.Webhook) bool {
+		return !h.Enabled || !slices.Contains(h.Events, string(typ))
+	})
+	if len(hooks) == 0 {
+		return
+	}
+
+	if !bulk {
+		d.send(ctx, hooks, ev)
+		return
+	}
+	for _, id := range change.IDs {
+		d.send(ctx, hooks, events.Event{ID: ev.ID, Type: typ, Time: ev.Time, Data: events.ArticleState{ArticleID: id}})
+	}
+}
+
+// send starts a delivery of ev to each of hooks. Article events are sent
+// with the article as it is stored, so receivers get the feed name and
+// every tag, and filters see the same.
+func (d *Dispatcher) send(ctx context.Context, hooks []core.Webhook, ev events.Event) {
+	var article *Article
+	var err error
+	switch data := ev.Data.(type) {
+	case events.ArticleScore:
+		article, err = d.loadArticle(ctx, data.ArticleID)
+	case events.ArticleState:
+		article, err = d.loadArticle(ctx, data.ArticleID)
+	}
+	if err != nil {
+		d.logger.Error("failed to load article for webhook", "event", ev.Type, "error", err)
+		return
+	}
+	if article != nil {
+		ev.Data = article
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		d.logger.Error("failed to encode webhook payload", "event", ev.Type, "error", err)
+		return
+	}
+
+	for _, hook := range hooks {
+		if article != nil && !Matches(hook, *article) {
+			continue
+		}
+		d.wg.Add(1)
+		go d.deliver(ctx, hook, ev, body)
+	}
+}
+
+func (d *Dispatcher) loadArticle(ctx context.Context, id int64) (*Article, error) {
+	a, err := d.store.GetArticleByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting article %d: %w", id, err)
+	}
+	tags, err := d.store.GetArticleTags(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting tags for article %d: %w", id, err)
+	}
+	return &Article{
+		ID:          a.ID,
+		FeedID:      a.FeedID,
+		FeedName:    a.FeedName,
+		Title:       a.Title,
+		URL:         a.URL,
+		Score:       a.QualityRank,
+		Summary:     a.Summary,
+		Tags:        tags,
+		PublishedAt: a.PublishedAt,
+	}, nil
+}
+
+// Matches reports whether an article passes a webhook's filters: a score
+// of at least MinScore and, when Tags is set, at least one of those tags.
+func Matches(hook core.Webhook, article Article) bool {
+	if article.Score < hook.MinScore {
+		return false
+	}
+	if len(hook.Tags) == 0 {
+		return true
+	}
+	for _, want := range hook.Tags {
+		for _, tag := range article.Tags {
+			if strings.EqualFold(want, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, hook core.Webhook, ev events.Event, body []byte) {
+	defer d.wg.Done()
+	select {
+	case d.sem <- struct{}{}:
+		defer func() { <-d.sem }()
+	case <-ctx.Done():
+		return
+	}
+
+	delivery := core.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventID:   ev.ID,
+		Event:     string(ev.Type),
+		Payload:   string(body),
+	}
+	start := time.Now()
+
+	// A 4xx other than 408 or 429 means the receiver rejected the payload,
+	// and sending it again will not help.
+	var rejected error
+	err := retry.Do(ctx, d.maxAttempts, func() error {
+		delivery.Attempts++
+		status, err := d.post(ctx, hook, ev.Type, body)
+		delivery.StatusCode = status
+		if err != nil && status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+			rejected = err
+			return nil
+		}
+		return err
+	})
+	if rejected != nil {
+		err = rejected
+	}
+	delivery.Duration = time.Since(start)
+
+	if err != nil {
+		delivery.Error = err.Error()
+		d.logger.Warn("webhook delivery failed",
+			"webhook_id", hook.ID, "event", ev.Type, "attempts", delivery.Attempts, "error", err)
+	} else {
+		d.logger.Debug("webhook delivered",
+			"webhook_id", hook.ID, "event", ev.Type, "attempts", delivery.Attempts)
+	}
+
+	if _, err := d.store.RecordWebhookDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		d.logger.Error("failed to record webhook delivery", "webhook_id", hook.ID, "error", err)
+	}
+}
+
+// post sends one attempt and returns the response status, or 0 if there
+// was no response.
+func (d *Dispatcher) post(ctx context.Context, hook core.Webhook, typ events.Type, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("creating request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, string(typ))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// Drop the URL: the webhook already names it, and digits in it
+		// such as a port of 4290 would look like a rate limit to retry.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret,
+// which is what SignatureHeader carries after "sha256=". Including the
+// timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a request's signature headers against body, rejecting
+// timestamps more than tolerance away from now. Receivers written in Go can
+// use it directly.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid timestamp")
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return errors.New("timestamp outside tolerance")
+	}
+	signature, ok := strings.CutPrefix(header.Get(SignatureHeader), "sha256=")
+	if !ok {
+		return errors.New("missing signature")
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.New("malformed signature")
+	}
+	want, _ := hex.DecodeString(Sign(secret, timestamp, body))
+	if !hmac.Equal(got, want) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
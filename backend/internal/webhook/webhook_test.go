@@ -0,0 +1,301 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"dailysynapse/backend/internal/config"
+	"dailysynapse/backend/internal/core"
+	"dailysynapse/backend/internal/events"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	hooks      []core.Webhook
+	articles   map[int64]core.Article
+	tags       map[int64][]string
+	deliveries chan core.WebhookDelivery
+}
+
+func (s *fakeStore) GetWebhooks(ctx context.Context) ([]core.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]core.Webhook(nil), s.hooks...), nil
+}
+
+func (s *fakeStore) GetArticleByID(ctx context.Context, id int64) (*core.Article, error) {
+	a, ok := s.articles[id]
+	if !ok {
+		return nil, core.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (s *fakeStore) GetArticleTags(ctx context.Context, id int64) ([]string, error) {
+	return s.tags[id], nil
+}
+
+func (s *fakeStore) RecordWebhookDelivery(ctx context.Context, d core.WebhookDelivery) (core.WebhookDelivery, error) {
+	s.deliveries <- d
+	return d, nil
+}
+
+// receiver is an httptest endpoint that verifies signatures and answers
+// with the queued statuses, then 200.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	bodies   chan []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses, bodies: make(chan []byte, 16)}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if err := Verify("s3cret", req.Header, body, time.Minute); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+		if req.Header.Get(EventHeader) == "" {
+			t.Errorf("%s header missing", EventHeader)
+		}
+
+		r.mu.Lock()
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+		if status == http.StatusOK {
+			r.bodies <- body
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func startDispatcher(t *testing.T, store *fakeStore) *events.Bus {
+	t.Helper()
+	bus := events.New()
+	cfg := &config.Config{WebhookTimeout: time.Second, WebhookMaxAttempts: 3}
+	d := NewDispatcher(store, bus, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return bus
+}
+
+func nextDelivery(t *testing.T, store *fakeStore) core.WebhookDelivery {
+	t.Helper()
+	select {
+	case d := <-store.deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+	}
+	return core.WebhookDelivery{}
+}
+
+func newStore(hooks ...core.Webhook) *fakeStore {
+	return &fakeStore{
+		hooks: hooks,
+		articles: map[int64]core.Article{
+			1: {ID: 1, FeedID: 3, FeedName: "Go Blog", Title: "Go 1.24", URL: "https://go.dev/blog/go1.24", QualityRank: 90},
+			2: {ID: 2, FeedID: 3, FeedName: "Go Blog", Title: "Minor news", URL: "https://go.dev/blog/minor", QualityRank: 40},
+		},
+		tags:       map[int64][]string{1: {"Go", "Releases"}, 2: {"Go"}},
+		deliveries: make(chan core.WebhookDelivery, 16),
+	}
+}
+
+func TestDispatcher_SignsAndDelivers(t *testing.T) {
+	recv := newReceiver(t)
+	store := newStore(core.Webhook{ID: 1, URL: recv.URL, Secret: "s3cret", Events: []string{"article.scored", "feed.added"}, Enabled: true})
+	bus := startDispatcher(t, store)
+
+	bus.Publish(events.ArticleScored, events.ArticleScore{ArticleID: 1, Score: 90})
+
+	d := nextDelivery(t, store)
+	if d.WebhookID != 1 || d.Event != "article.scored" || d.StatusCode != 200 || d.Attempts != 1 || d.Error != "" {
+		t.Errorf("delivery = %+v, want one successful attempt", d)
+	}
+
+	var payload struct {
+		ID   uint64  `json:"id"`
+		Type string  `json:"type"`
+		Data Article `json:"data"`
+	}
+	if err := json.Unmarshal(<-recv.bodies, &payload); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if payload.ID != 1 || payload.Type != "article.scored" || payload.Data.FeedName != "Go Blog" || len(payload.Data.Tags) != 2 {
+		t.Errorf("payload = %+v, want article 1 with feed name and tags", payload)
+	}
+
+	bus.Publish(events.FeedAdded, events.FeedInfo{FeedID: 4, Name: "New", URL: "https://example.com/feed", Type: "rss"})
+	if d := nextDelivery(t, store); d.Event != "feed.added" || d.StatusCode != 200 {
+		t.Errorf("delivery = %+v, want feed.added", d)
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	recv := newReceiver(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	store := newStore(core.Webhook{ID: 1, URL: recv.URL, Secret: "s3cret", Events: []string{"feed.failed"}, Enabled: true})
+	bus := startDispatcher(t, store)
+
+	bus.Publish(events.FeedFailed, events.FeedSync{FeedID: 3, Error: "timeout"})
+	if d := nextDelivery(t, store); d.Attempts != 3 || d.StatusCode != 200 || d.Error != "" {
+		t.Errorf("delivery = %+v, want success on the third attempt", d)
+	}
+
+	// A rejected payload is not sent again.
+	recv.mu.Lock()
+	recv.statuses = []int{http.StatusGone}
+	recv.mu.Unlock()
+	bus.Publish(events.FeedFailed, events.FeedSync{FeedID: 3, Error: "timeout"})
+	if d := nextDelivery(t, store); d.Attempts != 1 || d.StatusCode != http.StatusGone || d.Error == "" {
+		t.Errorf("delivery = %+v, want one rejected attempt", d)
+	}
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	recv := newReceiver(t, 500, 500, 500)
+	store := newStore(core.Webhook{ID: 1, URL: recv.URL, Secret: "s3cret", Events: []string{"feed.failed"}, Enabled: true})
+	bus := startDispatcher(t, store)
+
+	bus.Publish(events.FeedFailed, events.FeedSync{FeedID: 3})
+	d := nextDelivery(t, store)
+	if d.Attempts != 3 || d.StatusCode != 500 || d.Error != "receiver responded 500 Internal Server Error" {
+		t.Errorf("delivery = %+v, want three failed attempts", d)
+	}
+}
+
+func TestDispatcher_Filters(t *testing.T) {
+	recv := newReceiver(t)
+	store := newStore(
+		core.Webhook{ID: 1, URL: recv.URL, Secret: "s3cret", Events: []string{"article.saved"}, MinScore: 70, Enabled: true},
+		core.Webhook{ID: 2, URL: recv.URL, Secret: "s3cret", Events: []string{"article.saved"}, Tags: []string{"releases"}, Enabled: true},
+		core.Webhook{ID: 3, URL: recv.URL, Secret: "s3cret", Events: []string{"article.saved"}, Enabled: false},
+		core.Webhook{ID: 4, URL: recv.URL, Secret: "s3cret", Events: []string{"article.scored"}, Enabled: true},
+	)
+	bus := startDispatcher(t, store)
+
+	bus.Publish(events.ArticleSaved, events.ArticleState{ArticleID: 2})
+	bus.Publish(events.ArticleSaved, events.ArticleState{ArticleID: 1})
+
+	got := map[int64]bool{}
+	for range 2 {
+		d := nextDelivery(t, store)
+		if d.Payload == "" || d.Event != "article.saved" {
+			t.Errorf("delivery = %+v, want article.saved", d)
+		}
+		got[d.WebhookID] = true
+	}
+	if !got[1] || !got[2] {
+		t.Errorf("delivered to webhooks %v, want 1 and 2", got)
+	}
+	select {
+	case d := <-store.deliveries:
+		t.Errorf("unexpected delivery %+v", d)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDispatcher_BulkSave(t *testing.T) {
+	recv := newReceiver(t)
+	store := newStore(
+		core.Webhook{ID: 1, URL: recv.URL, Secret: "s3cret", Events: []string{"article.saved"}, MinScore: 70, Enabled: true},
+		core.Webhook{ID: 2, URL: recv.URL, Secret: "s3cret", Events: []string{"article.read"}, Enabled: true},
+	)
+	bus := startDispatcher(t, store)
+
+	bus.Publish(events.ArticlesBulk, events.BulkChange{Action: "read", IDs: []int64{1, 2}, Affected: 2})
+	bus.Publish(events.ArticlesBulk, events.BulkChange{Action: "save", IDs: []int64{1, 2}, Affected: 2})
+
+	// Only article 1 passes the score filter; bulk reads are not expanded.
+	d := nextDelivery(t, store)
+	if d.WebhookID != 1 || d.Event != "article.saved" || d.EventID != 2 {
+		t.Errorf("delivery = %+v, want article.saved from event 2", d)
+	}
+	var payload struct {
+		Data Article `json:"data"`
+	}
+	if err := json.Unmarshal(<-recv.bodies, &payload); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	if payload.Data.ID != 1 {
+		t.Errorf("payload article = %d, want 1", payload.Data.ID)
+	}
+	select {
+	case d := <-store.deliveries:
+		t.Errorf("unexpected delivery %+v", d)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	now := time.Now().Unix()
+	header := http.Header{}
+	header.Set(TimestampHeader, strconv.FormatInt(now, 10))
+	header.Set(SignatureHeader, "sha256="+Sign("s3cret", now, body))
+	if err := Verify("s3cret", header, body, time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := Verify("other", header, body, time.Minute); err == nil {
+		t.Error("Verify() with the wrong secret succeeded")
+	}
+	if err := Verify("s3cret", header, []byte(`{"id":2}`), time.Minute); err == nil {
+		t.Error("Verify() with a tampered body succeeded")
+	}
+
+	old := now - 600
+	header.Set(TimestampHeader, strconv.FormatInt(old, 10))
+	header.Set(SignatureHeader, "sha256="+Sign("s3cret", old, body))
+	if err := Verify("s3cret", header, body, time.Minute); err == nil {
+		t.Error("Verify() with a stale timestamp succeeded")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := core.Webhook{URL: "https://example.com/hook", Secret: "s", Events: []string{"article.scored"}}
+	if err := Validate(valid); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	tests := map[string]func(*core.Webhook){
+		"relative url":  func(h *core.Webhook) { h.URL = "/hook" },
+		"ftp url":       func(h *core.Webhook) { h.URL = "ftp://example.com/hook" },
+		"no secret":     func(h *core.Webhook) { h.Secret = "" },
+		"no events":     func(h *core.Webhook) { h.Events = nil },
+		"unknown event": func(h *core.Webhook) { h.Events = []string{"article.read"} },
+		"min score":     func(h *core.Webhook) { h.MinScore = 101 },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			hook := valid
+			mutate(&hook)
+			if err := Validate(hook); !errors.Is(err, core.ErrBadRequest) {
+				t.Errorf("Validate() error = %v, want ErrBadRequest", err)
+			}
+		})
+	}
+}
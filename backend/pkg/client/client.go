@@ -0,0 +1,551 @@
+// Package client is a typed Go client for the Daily Synapse HTTP API, as
+// described by the server's GET /api/openapi.json.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is a non-2xx answer from the server. Code is stable, e.g.
+// feed_exists or article_not_found; Message is for people.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("synapse: %d %s: %s (request %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set timeouts or
+// authenticate through a proxy.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// New returns a client for the server at baseURL, e.g.
+// "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{baseURL: strings.TrimRight(baseURL, "/"), httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// envelope is the server's {"data": ...} or {"error": ..., "code": ...}
+// wrapper.
+type envelope struct {
+	Data      json.RawMessage `json:"data"`
+	Error     string          `json:"error"`
+	Code      string          `json:"code"`
+	RequestID string          `json:"request_id"`
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader, contentType = b, "application/xml"
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reader, contentType = bytes.NewReader(raw), "application/json"
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil && err != io.EOF {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		return responseError(resp, env)
+	}
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func responseError(resp *http.Response, env envelope) *Error {
+	msg := env.Error
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	requestID := env.RequestID
+	if requestID == "" {
+		requestID = resp.Header.Get("X-Request-ID")
+	}
+	return &Error{StatusCode: resp.StatusCode, Code: env.Code, Message: msg, RequestID: requestID}
+}
+
+func idPath(format string, id int64) string {
+	return fmt.Sprintf(format, id)
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	setInt := func(key string, n int64) {
+		if n != 0 {
+			v.Set(key, strconv.FormatInt(n, 10))
+		}
+	}
+	setInt("feed", o.Feed)
+	setInt("category", o.Category)
+	setInt("min_score", int64(o.MinScore))
+	setInt("max_score", int64(o.MaxScore))
+	setInt("limit", int64(o.Limit))
+	terms := slices.Clone(o.Tags)
+	if len(o.RequireTags) > 0 {
+		terms = append(terms, "+"+strings.Join(o.RequireTags, "+"))
+	}
+	for _, t := range o.ExcludeTags {
+		terms = append(terms, "-"+t)
+	}
+	if len(terms) > 0 {
+		v.Set("tags", strings.Join(terms, ","))
+	}
+	if o.MatchAll {
+		v.Set("tag_mode", "all")
+	}
+	if !o.Since.IsZero() {
+		v.Set("since", o.Since.Format(time.RFC3339))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.Format(time.RFC3339))
+	}
+	if o.Read != "" {
+		v.Set("read", o.Read)
+	}
+	if o.Saved != nil {
+		v.Set("saved", strconv.FormatBool(*o.Saved))
+	}
+	if o.Sort != "" {
+		v.Set("sort", o.Sort)
+	}
+	if o.Cursor != "" {
+		v.Set("cursor", o.Cursor)
+	}
+	return v
+}
+
+// Health reports whether the server is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil, nil)
+}
+
+// Ready reports whether the server can reach its database.
+func (c *Client) Ready(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/ready", nil, nil, nil)
+}
+
+// Sync starts syncing every feed in the background.
+func (c *Client) Sync(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/sync", nil, nil, nil)
+}
+
+func (c *Client) ListFeeds(ctx context.Context) ([]Feed, error) {
+	var feeds []Feed
+	err := c.do(ctx, http.MethodGet, "/api/feeds", nil, nil, &feeds)
+	return feeds, err
+}
+
+func (c *Client) CreateFeed(ctx context.Context, req CreateFeedRequest) (Feed, error) {
+	var feed Feed
+	err := c.do(ctx, http.MethodPost, "/api/feeds", nil, req, &feed)
+	return feed, err
+}
+
+// GetFeed returns a feed with its statistics and a page of its articles.
+func (c *Client) GetFeed(ctx context.Context, id int64, limit, offset int) (FeedDetail, error) {
+	v := url.Values{}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		v.Set("offset", strconv.Itoa(offset))
+	}
+	var detail FeedDetail
+	err := c.do(ctx, http.MethodGet, idPath("/api/feeds/%d", id), v, nil, &detail)
+	return detail, err
+}
+
+func (c *Client) UpdateFeed(ctx context.Context, id int64, patch FeedPatch) (Feed, error) {
+	var feed Feed
+	err := c.do(ctx, http.MethodPatch, idPath("/api/feeds/%d", id), nil, patch, &feed)
+	return feed, err
+}
+
+// DeleteFeed marks a feed for deletion; RestoreFeed undoes it within the
+// server's grace period.
+func (c *Client) DeleteFeed(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/feeds/%d", id), nil, nil, nil)
+}
+
+func (c *Client) RestoreFeed(ctx context.Context, id int64) (Feed, error) {
+	var feed Feed
+	err := c.do(ctx, http.MethodPost, idPath("/api/feeds/%d/restore", id), nil, nil, &feed)
+	return feed, err
+}
+
+func (c *Client) GetFeedSettings(ctx context.Context, id int64) (FeedSettings, error) {
+	var settings FeedSettings
+	err := c.do(ctx, http.MethodGet, idPath("/api/feeds/%d/settings", id), nil, nil, &settings)
+	return settings, err
+}
+
+func (c *Client) UpdateFeedSettings(ctx context.Context, id int64, req FeedSettingsRequest) (FeedSettings, error) {
+	var settings FeedSettings
+	err := c.do(ctx, http.MethodPut, idPath("/api/feeds/%d/settings", id), nil, req, &settings)
+	return settings, err
+}
+
+func (c *Client) ListCategories(ctx context.Context) ([]Category, error) {
+	var categories []Category
+	err := c.do(ctx, http.MethodGet, "/api/categories", nil, nil, &categories)
+	return categories, err
+}
+
+func (c *Client) CreateCategory(ctx context.Context, req CategoryRequest) (Category, error) {
+	var category Category
+	err := c.do(ctx, http.MethodPost, "/api/categories", nil, req, &category)
+	return category, err
+}
+
+func (c *Client) UpdateCategory(ctx context.Context, id int64, req CategoryRequest) (Category, error) {
+	var category Category
+	err := c.do(ctx, http.MethodPut, idPath("/api/categories/%d", id), nil, req, &category)
+	return category, err
+}
+
+func (c *Client) DeleteCategory(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/categories/%d", id), nil, nil, nil)
+}
+
+// ImportOPML subscribes to the feeds of an OPML document, filing them under
+// categories named after its folders.
+func (c *Client) ImportOPML(ctx context.Context, opml io.Reader) (OPMLImportResult, error) {
+	var result OPMLImportResult
+	err := c.do(ctx, http.MethodPost, "/api/opml", nil, opml, &result)
+	return result, err
+}
+
+// Daily lists the daily ranking, unread articles first unless opts.Sort
+// says otherwise.
+func (c *Client) Daily(ctx context.Context, opts ListOptions) (ArticleList, error) {
+	var list ArticleList
+	err := c.do(ctx, http.MethodGet, "/api/daily", opts.values(), nil, &list)
+	return list, err
+}
+
+// Articles lists scored articles, by score unless opts.Sort says otherwise.
+func (c *Client) Articles(ctx context.Context, opts ListOptions) (ArticleList, error) {
+	var list ArticleList
+	err := c.do(ctx, http.MethodGet, "/api/articles", opts.values(), nil, &list)
+	return list, err
+}
+
+// Saved lists saved articles, newest first unless opts.Sort says otherwise.
+func (c *Client) Saved(ctx context.Context, opts ListOptions) (ArticleList, error) {
+	var list ArticleList
+	err := c.do(ctx, http.MethodGet, "/api/saved", opts.values(), nil, &list)
+	return list, err
+}
+
+// Export downloads the articles opts selects as a markdown, epub, json or
+// csv file; saved articles unless opts.Saved is set. Its Limit and Cursor
+// are ignored. The caller must close the returned body.
+func (c *Client) Export(ctx context.Context, format string, opts ListOptions) (io.ReadCloser, error) {
+	opts.Limit, opts.Cursor = 0, ""
+	v := opts.values()
+	v.Set("format", format)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/export?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var env envelope
+		json.NewDecoder(resp.Body).Decode(&env)
+		return nil, responseError(resp, env)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) GetArticle(ctx context.Context, id int64) (Article, error) {
+	var article Article
+	err := c.do(ctx, http.MethodGet, idPath("/api/articles/%d", id), nil, nil, &article)
+	return article, err
+}
+
+func (c *Client) MarkRead(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/articles/%d/read", id), nil, nil, nil)
+}
+
+func (c *Client) MarkUnread(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/articles/%d/unread", id), nil, nil, nil)
+}
+
+// ToggleSaved flips whether an article is saved and returns the new state.
+func (c *Client) ToggleSaved(ctx context.Context, id int64) (bool, error) {
+	var out struct {
+		Saved bool `json:"saved"`
+	}
+	err := c.do(ctx, http.MethodPost, idPath("/api/articles/%d/save", id), nil, nil, &out)
+	return out.Saved, err
+}
+
+// DismissArticle hides an article; RestoreArticle undoes it within the
+// server's grace period.
+func (c *Client) DismissArticle(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/articles/%d", id), nil, nil, nil)
+}
+
+func (c *Client) RestoreArticle(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/articles/%d/restore", id), nil, nil, nil)
+}
+
+// Bulk applies one action to many articles in a single transaction.
+func (c *Client) Bulk(ctx context.Context, req BulkRequest) (BulkResult, error) {
+	var result BulkResult
+	err := c.do(ctx, http.MethodPost, "/api/articles/bulk", nil, req, &result)
+	return result, err
+}
+
+func (c *Client) ListTags(ctx context.Context) ([]TagCount, error) {
+	var tags []TagCount
+	err := c.do(ctx, http.MethodGet, "/api/tags", nil, nil, &tags)
+	return tags, err
+}
+
+// RelatedTags lists the tags found alongside name on the articles opts
+// matches, most common first. opts.Limit caps the number of tags.
+func (c *Client) RelatedTags(ctx context.Context, name string, opts ListOptions) ([]TagCount, error) {
+	var tags []TagCount
+	err := c.do(ctx, http.MethodGet, "/api/tags/"+url.PathEscape(name)+"/related", opts.values(), nil, &tags)
+	return tags, err
+}
+
+func (c *Client) GetPreferences(ctx context.Context) (Preferences, error) {
+	var prefs Preferences
+	err := c.do(ctx, http.MethodGet, "/api/preferences", nil, nil, &prefs)
+	return prefs, err
+}
+
+// SetPreferences replaces the followed and muted tags.
+func (c *Client) SetPreferences(ctx context.Context, req PreferencesRequest) (Preferences, error) {
+	var prefs Preferences
+	err := c.do(ctx, http.MethodPut, "/api/preferences", nil, req, &prefs)
+	return prefs, err
+}
+
+func (c *Client) ListRules(ctx context.Context) ([]IngestRule, error) {
+	var rules []IngestRule
+	err := c.do(ctx, http.MethodGet, "/api/rules", nil, nil, &rules)
+	return rules, err
+}
+
+func (c *Client) GetRule(ctx context.Context, id int64) (IngestRule, error) {
+	var rule IngestRule
+	err := c.do(ctx, http.MethodGet, idPath("/api/rules/%d", id), nil, nil, &rule)
+	return rule, err
+}
+
+func (c *Client) CreateRule(ctx context.Context, req IngestRuleRequest) (IngestRule, error) {
+	var rule IngestRule
+	err := c.do(ctx, http.MethodPost, "/api/rules", nil, req, &rule)
+	return rule, err
+}
+
+func (c *Client) UpdateRule(ctx context.Context, id int64, req IngestRuleRequest) (IngestRule, error) {
+	var rule IngestRule
+	err := c.do(ctx, http.MethodPut, idPath("/api/rules/%d", id), nil, req, &rule)
+	return rule, err
+}
+
+func (c *Client) DeleteRule(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/rules/%d", id), nil, nil, nil)
+}
+
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var hooks []Webhook
+	err := c.do(ctx, http.MethodGet, "/api/webhooks", nil, nil, &hooks)
+	return hooks, err
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int64) (Webhook, error) {
+	var hook Webhook
+	err := c.do(ctx, http.MethodGet, idPath("/api/webhooks/%d", id), nil, nil, &hook)
+	return hook, err
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, req WebhookRequest) (Webhook, error) {
+	var hook Webhook
+	err := c.do(ctx, http.MethodPost, "/api/webhooks", nil, req, &hook)
+	return hook, err
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id int64, req WebhookRequest) (Webhook, error) {
+	var hook Webhook
+	err := c.do(ctx, http.MethodPut, idPath("/api/webhooks/%d", id), nil, req, &hook)
+	return hook, err
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/webhooks/%d", id), nil, nil, nil)
+}
+
+// WebhookDeliveries returns up to limit recent deliveries, newest first;
+// limit 0 uses the server default.
+func (c *Client) WebhookDeliveries(ctx context.Context, id int64, limit int) ([]WebhookDelivery, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, http.MethodGet, idPath("/api/webhooks/%d/deliveries", id), query, nil, &deliveries)
+	return deliveries, err
+}
+
+func (c *Client) ListChannels(ctx context.Context) ([]Channel, error) {
+	var channels []Channel
+	err := c.do(ctx, http.MethodGet, "/api/channels", nil, nil, &channels)
+	return channels, err
+}
+
+func (c *Client) GetChannel(ctx context.Context, id int64) (Channel, error) {
+	var ch Channel
+	err := c.do(ctx, http.MethodGet, idPath("/api/channels/%d", id), nil, nil, &ch)
+	return ch, err
+}
+
+func (c *Client) CreateChannel(ctx context.Context, req ChannelRequest) (Channel, error) {
+	var ch Channel
+	err := c.do(ctx, http.MethodPost, "/api/channels", nil, req, &ch)
+	return ch, err
+}
+
+func (c *Client) UpdateChannel(ctx context.Context, id int64, req ChannelRequest) (Channel, error) {
+	var ch Channel
+	err := c.do(ctx, http.MethodPut, idPath("/api/channels/%d", id), nil, req, &ch)
+	return ch, err
+}
+
+func (c *Client) DeleteChannel(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, idPath("/api/channels/%d", id), nil, nil, nil)
+}
+
+// TestChannel asks the server to send a sample message to the channel.
+func (c *Client) TestChannel(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodPost, idPath("/api/channels/%d/test", id), nil, nil, nil)
+}
+
+// Events subscribes to the server's live event stream. Scored articles are
+// filtered like Daily with opts; its Sort, Limit and Cursor are ignored.
+// The channel is closed when ctx ends or the connection drops.
+func (c *Client) Events(ctx context.Context, opts ListOptions) (<-chan Event, error) {
+	opts.Sort, opts.Limit, opts.Cursor = "", 0, ""
+	u := c.baseURL + "/api/events"
+	if v := opts.values(); len(v) > 0 {
+		u += "?" + v.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var env envelope
+		json.NewDecoder(resp.Body).Decode(&env)
+		return nil, responseError(resp, env)
+	}
+
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		var data []byte
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) > 0 {
+				if payload, ok := bytes.CutPrefix(line, []byte("data: ")); ok {
+					data = append(data, payload...)
+				}
+				continue
+			}
+			if len(data) == 0 {
+				continue
+			}
+			var ev Event
+			err := json.Unmarshal(data, &ev)
+			data = data[:0]
+			if err != nil {
+				continue
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
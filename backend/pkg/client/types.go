@@ -0,0 +1,341 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The response types mirror the server's JSON, which uses Go field names.
+
+type Feed struct {
+	ID              int64
+	URL             string
+	Name            string
+	Type            string
+	Status          string
+	Etag            string
+	LastModified    string
+	LastSyncedAt    time.Time
+	LastAttemptedAt time.Time
+	LastSyncError   string
+	CategoryID      int64
+}
+
+type Category struct {
+	ID        int64
+	Name      string
+	MinScore  int
+	FeedCount int
+	CreatedAt time.Time
+}
+
+type Article struct {
+	ID            int64
+	FeedID        int64
+	FeedName      string
+	Title         string
+	URL           string
+	PublishedAt   time.Time
+	Content       string
+	QualityRank   int
+	Summary       string
+	Justification string
+	JudgeModel    string
+	IsRead        bool
+	ReadLater     bool
+	GUID          string
+	UpdatedAt     time.Time
+	ImageURL      string
+	Categories    []string
+	Authors       []Author
+	Enclosures    []Enclosure
+	Tags          []string
+}
+
+type Author struct {
+	Name  string
+	Email string
+}
+
+type Enclosure struct {
+	URL      string
+	MIMEType string
+	Length   int64
+}
+
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// Preferences are the followed and muted tags. Articles with a followed
+// tag rank higher on the daily list and ones with a muted tag are hidden.
+type Preferences struct {
+	FollowedTags []string
+	MutedTags    []MutedTag
+}
+
+// MutedTag hides articles carrying Tag unless they score at least
+// OverrideScore; 0 hides them all.
+type MutedTag struct {
+	Tag           string
+	OverrideScore int
+}
+
+type ScoreBucket struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+type FeedStats struct {
+	FeedID          int64
+	ArticleCount    int
+	ScoredCount     int
+	UnscoredCount   int
+	RejectedCount   int
+	AverageScore    float64
+	AcceptanceRate  float64
+	ArticlesPerWeek float64
+	LastPublishedAt time.Time
+	Distribution    []ScoreBucket
+	TopTags         []TagCount
+}
+
+// FeedDetail is a feed with its statistics and one page of its articles.
+type FeedDetail struct {
+	Feed     Feed      `json:"feed"`
+	Stats    FeedStats `json:"stats"`
+	Articles []Article `json:"articles"`
+	Total    int       `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+// ArticleList is one page of an article list. NextCursor is empty on the
+// last page.
+type ArticleList struct {
+	Articles   []Article `json:"articles"`
+	NextCursor string    `json:"next_cursor"`
+	Limit      int       `json:"limit"`
+}
+
+// FeedSettings is what the server reports about a feed's fetch settings;
+// secrets are only reported as present or not.
+type FeedSettings struct {
+	FeedID                int64    `json:"feed_id"`
+	HeaderNames           []string `json:"header_names"`
+	Username              string   `json:"username"`
+	HasPassword           bool     `json:"has_password"`
+	UserAgent             string   `json:"user_agent"`
+	HasProxy              bool     `json:"has_proxy"`
+	TLSInsecureSkipVerify bool     `json:"tls_insecure_skip_verify"`
+	TLSServerName         string   `json:"tls_server_name"`
+}
+
+type IngestRule struct {
+	ID            int64
+	FeedID        int64
+	Field         string
+	MatchType     string
+	Pattern       string
+	Action        string
+	Tags          []string
+	Score         int
+	Enabled       bool
+	MatchCount    int
+	LastMatchedAt time.Time
+	CreatedAt     time.Time
+}
+
+// Webhook never carries its secret, except in the result of a CreateWebhook
+// that generated one.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	MinScore  int       `json:"min_score"`
+	Tags      []string  `json:"tags"`
+	Enabled   bool      `json:"enabled"`
+	HasSecret bool      `json:"has_secret"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID         int64     `json:"id"`
+	EventID    uint64    `json:"event_id"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload"`
+	StatusCode int       `json:"status_code"`
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Channel is a Slack, Discord or Matrix notification channel. Its URL and
+// token are never returned; Host shows where messages go.
+type Channel struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Host      string    `json:"host"`
+	RoomID    string    `json:"room_id"`
+	HasToken  bool      `json:"has_token"`
+	MinScore  int       `json:"min_score"`
+	Tags      []string  `json:"tags"`
+	FeedIDs   []int64   `json:"feed_ids"`
+	RateLimit int       `json:"rate_limit"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OPMLImportResult struct {
+	Imported   int      `json:"imported"`
+	Skipped    int      `json:"skipped"`
+	Failed     int      `json:"failed"`
+	Categories []string `json:"categories"`
+}
+
+type BulkResult struct {
+	Action   string `json:"action"`
+	Affected int64  `json:"affected"`
+}
+
+// Event is one message from the live event stream. The shape of Data
+// depends on Type, e.g. article.scored carries article_id, title and score.
+type Event struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Request bodies.
+
+type CreateFeedRequest struct {
+	URL  string `json:"url"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// FeedPatch lists the changes to make to a feed; nil fields are left as is.
+type FeedPatch struct {
+	Name       *string `json:"name,omitempty"`
+	URL        *string `json:"url,omitempty"`
+	Paused     *bool   `json:"paused,omitempty"`
+	CategoryID *int64  `json:"category_id,omitempty"` // 0 clears the category
+}
+
+// FeedSettingsRequest replaces all of a feed's settings; secrets must be
+// resent with every update.
+type FeedSettingsRequest struct {
+	Headers               map[string]string `json:"headers,omitempty"`
+	Username              string            `json:"username,omitempty"`
+	Password              string            `json:"password,omitempty"`
+	BearerToken           string            `json:"bearer_token,omitempty"`
+	Cookie                string            `json:"cookie,omitempty"`
+	UserAgent             string            `json:"user_agent,omitempty"`
+	ProxyURL              string            `json:"proxy_url,omitempty"`
+	TLSInsecureSkipVerify bool              `json:"tls_insecure_skip_verify,omitempty"`
+	TLSServerName         string            `json:"tls_server_name,omitempty"`
+}
+
+type CategoryRequest struct {
+	Name     string `json:"name"`
+	MinScore int    `json:"min_score"`
+}
+
+type MutedTagRequest struct {
+	Tag           string `json:"tag"`
+	OverrideScore int    `json:"override_score,omitempty"`
+}
+
+// PreferencesRequest replaces every followed and muted tag.
+type PreferencesRequest struct {
+	FollowedTags []string          `json:"followed_tags"`
+	MutedTags    []MutedTagRequest `json:"muted_tags"`
+}
+
+type IngestRuleRequest struct {
+	FeedID    int64    `json:"feed_id,omitempty"`
+	Field     string   `json:"field"`
+	MatchType string   `json:"match_type"`
+	Pattern   string   `json:"pattern"`
+	Action    string   `json:"action"`
+	Tags      []string `json:"tags,omitempty"`
+	Score     int      `json:"score,omitempty"`
+	Enabled   *bool    `json:"enabled,omitempty"` // defaults to true
+}
+
+// WebhookRequest subscribes URL to Events. MinScore and Tags only narrow
+// article events.
+type WebhookRequest struct {
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret,omitempty"` // generated on create, kept on update
+	Events   []string `json:"events"`
+	MinScore int      `json:"min_score,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Enabled  *bool    `json:"enabled,omitempty"` // defaults to true
+}
+
+// ChannelRequest describes a notification channel. URL is the incoming
+// webhook for Slack and Discord and the homeserver for Matrix, which also
+// needs RoomID and Token.
+type ChannelRequest struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`          // slack, discord or matrix
+	URL       string   `json:"url,omitempty"` // kept on update when empty
+	RoomID    string   `json:"room_id,omitempty"`
+	Token     string   `json:"token,omitempty"`     // kept on update when empty
+	MinScore  *int     `json:"min_score,omitempty"` // defaults to 90
+	Tags      []string `json:"tags,omitempty"`
+	FeedIDs   []int64  `json:"feed_ids,omitempty"`
+	RateLimit int      `json:"rate_limit,omitempty"` // per hour, defaults to 10
+	Enabled   *bool    `json:"enabled,omitempty"`    // defaults to true
+}
+
+// BulkRequest applies Action to either IDs or every article Filter matches.
+type BulkRequest struct {
+	Action string      `json:"action"`
+	IDs    []int64     `json:"ids,omitempty"`
+	Filter *BulkFilter `json:"filter,omitempty"`
+	Tags   []string    `json:"tags,omitempty"`
+}
+
+type BulkFilter struct {
+	Feed          int64    `json:"feed,omitempty"`
+	Category      int64    `json:"category,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	TagMode       string   `json:"tag_mode,omitempty"`
+	MinScore      int      `json:"min_score,omitempty"`
+	MaxScore      int      `json:"max_score,omitempty"`
+	Since         string   `json:"since,omitempty"`
+	Until         string   `json:"until,omitempty"`
+	OlderThanDays int      `json:"older_than_days,omitempty"`
+	Read          string   `json:"read,omitempty"`
+	Saved         *bool    `json:"saved,omitempty"`
+	HideMuted     bool     `json:"hide_muted,omitempty"`
+}
+
+// ListOptions are the filters and paging shared by the article lists.
+// Zero values are left out of the query.
+type ListOptions struct {
+	Feed     int64
+	Category int64
+	Tags     []string
+	MatchAll bool
+	// RequireTags must all be on an article and ExcludeTags none of them.
+	RequireTags []string
+	ExcludeTags []string
+	MinScore    int
+	MaxScore    int
+	Since       time.Time
+	Until       time.Time
+	Read        string // read, unread or all
+	Saved       *bool
+	Sort        string // personalized, score or date
+	Limit       int
+	Cursor      string
+}
@@ -0,0 +1,98 @@
+package opml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrNoBody = errors.New("opml document has no body")
+
+// Feed is one subscription from an OPML export. Category is the title of
+// the nearest enclosing folder outline, or the outline's own category
+// attribute when it sits at the top level.
+type Feed struct {
+	Title    string
+	XMLURL   string
+	HTMLURL  string
+	Category string
+}
+
+type document struct {
+	XMLName xml.Name `xml:"opml"`
+	Body    *body    `xml:"body"`
+}
+
+type body struct {
+	Outlines []outline `xml:"outline"`
+}
+
+type outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr"`
+	XMLURL   string    `xml:"xmlUrl,attr"`
+	HTMLURL  string    `xml:"htmlUrl,attr"`
+	Category string    `xml:"category,attr"`
+	Outlines []outline `xml:"outline"`
+}
+
+// Parse reads an OPML document and flattens its outline tree into feeds.
+// Nested folders collapse onto the innermost one.
+func Parse(r io.Reader) ([]Feed, error) {
+	var doc document
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		// Exports are UTF-8 in practice; accept any declared label rather
+		// than failing on e.g. ISO-8859-1 headers with ASCII content.
+		return input, nil
+	}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding opml: %w", err)
+	}
+	if doc.Body == nil {
+		return nil, ErrNoBody
+	}
+
+	var feeds []Feed
+	walk(doc.Body.Outlines, "", &feeds)
+	return feeds, nil
+}
+
+func walk(outlines []outline, folder string, feeds *[]Feed) {
+	for _, o := range outlines {
+		title := strings.TrimSpace(o.Title)
+		if title == "" {
+			title = strings.TrimSpace(o.Text)
+		}
+
+		xmlURL := strings.TrimSpace(o.XMLURL)
+		if xmlURL == "" {
+			walk(o.Outlines, title, feeds)
+			continue
+		}
+
+		category := folder
+		if category == "" {
+			category = categoryAttr(o.Category)
+		}
+		*feeds = append(*feeds, Feed{
+			Title:    title,
+			XMLURL:   xmlURL,
+			HTMLURL:  strings.TrimSpace(o.HTMLURL),
+			Category: category,
+		})
+	}
+}
+
+// categoryAttr takes the first entry of a comma-separated category list and
+// its last path segment, so "/Tech/Go,News" becomes "Go".
+func categoryAttr(attr string) string {
+	first, _, _ := strings.Cut(attr, ",")
+	first = strings.Trim(strings.TrimSpace(first), "/")
+	if i := strings.LastIndexByte(first, '/'); i >= 0 {
+		first = first[i+1:]
+	}
+	return strings.TrimSpace(first)
+}
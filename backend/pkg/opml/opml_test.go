@@ -0,0 +1,53 @@
+package opml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const sample = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Tech">
+      <outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom" htmlUrl="https://go.dev/blog"/>
+      <outline text="Languages">
+        <outline title="Rust Blog" text="rust" xmlUrl=" https://blog.rust-lang.org/feed.xml "/>
+      </outline>
+    </outline>
+    <outline text="Loose" xmlUrl="https://example.com/loose.xml"/>
+    <outline text="Tagged" xmlUrl="https://example.com/tagged.xml" category="/News/World,Politics"/>
+  </body>
+</opml>`
+
+func TestParse(t *testing.T) {
+	feeds, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Feed{
+		{Title: "Go Blog", XMLURL: "https://go.dev/blog/feed.atom", HTMLURL: "https://go.dev/blog", Category: "Tech"},
+		{Title: "Rust Blog", XMLURL: "https://blog.rust-lang.org/feed.xml", Category: "Languages"},
+		{Title: "Loose", XMLURL: "https://example.com/loose.xml"},
+		{Title: "Tagged", XMLURL: "https://example.com/tagged.xml", Category: "World"},
+	}
+	if len(feeds) != len(want) {
+		t.Fatalf("Parse() returned %d feeds, want %d: %+v", len(feeds), len(want), feeds)
+	}
+	for i := range want {
+		if feeds[i] != want[i] {
+			t.Errorf("Parse()[%d] = %+v, want %+v", i, feeds[i], want[i])
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("not xml")); err == nil {
+		t.Error("Parse() error = nil, want decode error")
+	}
+	if _, err := Parse(strings.NewReader(`<opml version="2.0"><head/></opml>`)); !errors.Is(err, ErrNoBody) {
+		t.Errorf("Parse() error = %v, want ErrNoBody", err)
+	}
+}
@@ -20,21 +20,40 @@ type Extractor interface {
 type DefaultExtractor struct {
 	client  *http.Client
 	timeout time.Duration
+	prepare func(*http.Request)
 }
 
 func NewExtractor(timeout time.Duration) *DefaultExtractor {
 	return &DefaultExtractor{
 		client:  &http.Client{Timeout: timeout},
 		timeout: timeout,
+		prepare: setDefaultUserAgent,
 	}
 }
 
+// NewExtractorWithClient uses client for all fetches and calls prepare on
+// each request to the article's host, e.g. to add per-feed auth headers.
+func NewExtractorWithClient(client *http.Client, prepare func(*http.Request)) *DefaultExtractor {
+	if prepare == nil {
+		prepare = setDefaultUserAgent
+	}
+	return &DefaultExtractor{
+		client:  client,
+		timeout: client.Timeout,
+		prepare: prepare,
+	}
+}
+
+func setDefaultUserAgent(req *http.Request) {
+	req.Header.Set("User-Agent", "TheDailySynapse/1.0")
+}
+
 func (e *DefaultExtractor) Extract(ctx context.Context, url string) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return "", fmt.Errorf("creating request: %w", err)
 	}
-	req.Header.Set("User-Agent", "TheDailySynapse/1.0")
+	e.prepare(req)
 
 	resp, err := e.client.Do(req)
 	if err != nil {
@@ -47,13 +66,13 @@ func (e *DefaultExtractor) Extract(ctx context.Context, url string) (string, err
 		return "", fmt.Errorf("extracting content: %w", err)
 	}
 
-	content := e.inlineImages(ctx, article.Content)
+	content := e.inlineImages(ctx, req.URL.Host, article.Content)
 	return content, nil
 }
 
 var imgSrcRegex = regexp.MustCompile(`<img[^>]+src=["']([^"']+)["']`)
 
-func (e *DefaultExtractor) inlineImages(ctx context.Context, html string) string {
+func (e *DefaultExtractor) inlineImages(ctx context.Context, host string, html string) string {
 	return imgSrcRegex.ReplaceAllStringFunc(html, func(match string) string {
 		submatches := imgSrcRegex.FindStringSubmatch(match)
 		if len(submatches) < 2 {
@@ -65,7 +84,7 @@ func (e *DefaultExtractor) inlineImages(ctx context.Context, html string) string
 			return match
 		}
 
-		dataURI, err := e.fetchAsDataURI(ctx, imgURL)
+		dataURI, err := e.fetchAsDataURI(ctx, host, imgURL)
 		if err != nil {
 			return match
 		}
@@ -74,12 +93,17 @@ func (e *DefaultExtractor) inlineImages(ctx context.Context, html string) string
 	})
 }
 
-func (e *DefaultExtractor) fetchAsDataURI(ctx context.Context, imgURL string) (string, error) {
+func (e *DefaultExtractor) fetchAsDataURI(ctx context.Context, host string, imgURL string) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imgURL, nil)
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("User-Agent", "TheDailySynapse/1.0")
+	// Only send feed credentials back to the article's own host.
+	if req.URL.Host == host {
+		e.prepare(req)
+	} else {
+		setDefaultUserAgent(req)
+	}
 
 	resp, err := e.client.Do(req)
 	if err != nil {
@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBox_RoundTrip(t *testing.T) {
+	box, err := NewBox("passphrase")
+	if err != nil {
+		t.Fatalf("NewBox() error = %v", err)
+	}
+
+	sealed, err := box.Seal("hunter2")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if sealed == "hunter2" {
+		t.Error("Seal() returned plaintext")
+	}
+
+	opened, err := box.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if opened != "hunter2" {
+		t.Errorf("Open() = %v, want hunter2", opened)
+	}
+}
+
+func TestBox_EmptyPassthrough(t *testing.T) {
+	var box *Box
+
+	sealed, err := box.Seal("")
+	if err != nil || sealed != "" {
+		t.Errorf("Seal(\"\") = %q, %v, want empty, nil", sealed, err)
+	}
+
+	opened, err := box.Open("")
+	if err != nil || opened != "" {
+		t.Errorf("Open(\"\") = %q, %v, want empty, nil", opened, err)
+	}
+}
+
+func TestBox_NilBox(t *testing.T) {
+	var box *Box
+
+	if _, err := box.Seal("value"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Seal() error = %v, want ErrNoKey", err)
+	}
+	if _, err := box.Open("dmFsdWU="); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Open() error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestBox_WrongKey(t *testing.T) {
+	a, _ := NewBox("key-a")
+	b, _ := NewBox("key-b")
+
+	sealed, err := a.Seal("value")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	if _, err := b.Open(sealed); err == nil {
+		t.Error("Open() with wrong key succeeded, want error")
+	}
+}
+
+func TestNewBox_EmptyKey(t *testing.T) {
+	if _, err := NewBox(""); !errors.Is(err, ErrNoKey) {
+		t.Errorf("NewBox(\"\") error = %v, want ErrNoKey", err)
+	}
+}
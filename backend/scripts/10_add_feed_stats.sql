@@ -0,0 +1,12 @@
+ALTER TABLE feeds ADD COLUMN last_sync_error TEXT DEFAULT '';
+ALTER TABLE feeds ADD COLUMN last_attempted_at DATETIME;
+
+CREATE TABLE rejected_articles (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER NOT NULL REFERENCES feeds(id),
+    score INTEGER NOT NULL,
+    published_at DATETIME,
+    rejected_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_rejected_articles_feed_id ON rejected_articles (feed_id);
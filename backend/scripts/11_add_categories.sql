@@ -0,0 +1,10 @@
+CREATE TABLE categories (
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    min_score INTEGER DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+ALTER TABLE feeds ADD COLUMN category_id INTEGER REFERENCES categories(id);
+
+CREATE INDEX idx_feeds_category_id ON feeds (category_id);
@@ -0,0 +1,6 @@
+ALTER TABLE feeds ADD COLUMN deleted_at DATETIME;
+ALTER TABLE feeds ADD COLUMN status_before_delete TEXT DEFAULT '';
+
+ALTER TABLE articles ADD COLUMN deleted_at DATETIME;
+
+CREATE INDEX idx_articles_deleted_at ON articles (deleted_at);
@@ -0,0 +1,13 @@
+CREATE TABLE tombstones (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    guid TEXT DEFAULT '',
+    published_at DATETIME,
+    reason TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_tombstones_url ON tombstones (url);
+CREATE INDEX idx_tombstones_feed_guid ON tombstones (feed_id, guid);
+CREATE INDEX idx_tombstones_published_at ON tombstones (published_at);
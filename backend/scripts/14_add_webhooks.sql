@@ -0,0 +1,25 @@
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL,
+    min_score INTEGER DEFAULT 0,
+    tags TEXT DEFAULT '',
+    enabled BOOLEAN DEFAULT 1,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id),
+    event_id INTEGER NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status_code INTEGER DEFAULT 0,
+    attempts INTEGER DEFAULT 0,
+    error TEXT DEFAULT '',
+    duration_ms INTEGER DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
@@ -0,0 +1,3 @@
+ALTER TABLE articles ADD COLUMN scored_at DATETIME;
+
+CREATE INDEX idx_articles_scored_at ON articles (scored_at);
@@ -0,0 +1,14 @@
+CREATE TABLE notification_channels (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    url TEXT NOT NULL,
+    room_id TEXT DEFAULT '',
+    token TEXT DEFAULT '',
+    min_score INTEGER DEFAULT 90,
+    tags TEXT DEFAULT '',
+    feed_ids TEXT DEFAULT '',
+    rate_limit INTEGER DEFAULT 10,
+    enabled BOOLEAN DEFAULT 1,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,5 @@
+CREATE TABLE tag_preferences (
+    tag TEXT PRIMARY KEY,
+    muted BOOLEAN NOT NULL DEFAULT 0,
+    override_score INTEGER DEFAULT 0
+);
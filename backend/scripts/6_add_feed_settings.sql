@@ -0,0 +1,10 @@
+CREATE TABLE feed_settings (
+    feed_id INTEGER PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    headers TEXT DEFAULT '',
+    username TEXT DEFAULT '',
+    password TEXT DEFAULT '',
+    user_agent TEXT DEFAULT '',
+    proxy_url TEXT DEFAULT '',
+    tls_insecure_skip_verify BOOLEAN DEFAULT 0,
+    tls_server_name TEXT DEFAULT ''
+);
@@ -0,0 +1,18 @@
+CREATE TABLE ingest_rules (
+    id INTEGER PRIMARY KEY,
+    feed_id INTEGER REFERENCES feeds(id),
+    field TEXT NOT NULL,
+    match_type TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL,
+    tags TEXT DEFAULT '',
+    score INTEGER DEFAULT 0,
+    enabled BOOLEAN DEFAULT 1,
+    match_count INTEGER DEFAULT 0,
+    last_matched_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_ingest_rules_feed_id ON ingest_rules (feed_id);
+
+ALTER TABLE article_tags ADD COLUMN pinned BOOLEAN DEFAULT 0;
@@ -0,0 +1,29 @@
+ALTER TABLE articles ADD COLUMN guid TEXT DEFAULT '';
+ALTER TABLE articles ADD COLUMN updated_at DATETIME;
+ALTER TABLE articles ADD COLUMN image_url TEXT DEFAULT '';
+ALTER TABLE articles ADD COLUMN categories TEXT DEFAULT '';
+
+CREATE INDEX idx_articles_feed_guid ON articles (feed_id, guid);
+
+CREATE TABLE authors (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    UNIQUE (name, email)
+);
+
+CREATE TABLE article_authors (
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES authors(id),
+    PRIMARY KEY (article_id, author_id)
+);
+
+CREATE TABLE enclosures (
+    id INTEGER PRIMARY KEY,
+    article_id INTEGER NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type TEXT DEFAULT '',
+    length INTEGER DEFAULT 0
+);
+
+CREATE INDEX idx_enclosures_article_id ON enclosures (article_id);
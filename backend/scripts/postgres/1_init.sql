@@ -0,0 +1,192 @@
+-- The Postgres schema as of SQLite migration 16. Later migrations are
+-- numbered independently of the SQLite ones.
+--
+-- Foreign keys are deliberately not declared. SQLite never enforced them
+-- (foreign_keys is off), so the store deletes dependent rows itself and
+-- snapshots from older databases may carry orphans that must still load.
+-- Name columns use the C collation to sort the way SQLite does.
+
+CREATE TABLE categories (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT COLLATE "C" UNIQUE NOT NULL,
+    min_score INTEGER DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE feeds (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    url TEXT UNIQUE NOT NULL,
+    name TEXT COLLATE "C",
+    etag TEXT,
+    last_synced_at TIMESTAMPTZ,
+    last_modified TEXT DEFAULT '',
+    status TEXT DEFAULT 'active',
+    source_type TEXT DEFAULT 'rss',
+    last_sync_error TEXT DEFAULT '',
+    last_attempted_at TIMESTAMPTZ,
+    category_id BIGINT,
+    deleted_at TIMESTAMPTZ,
+    status_before_delete TEXT DEFAULT ''
+);
+
+CREATE INDEX idx_feeds_category_id ON feeds (category_id);
+
+CREATE TABLE feed_settings (
+    feed_id BIGINT PRIMARY KEY,
+    headers TEXT DEFAULT '',
+    username TEXT DEFAULT '',
+    password TEXT DEFAULT '',
+    user_agent TEXT DEFAULT '',
+    proxy_url TEXT DEFAULT '',
+    tls_insecure_skip_verify BOOLEAN DEFAULT FALSE,
+    tls_server_name TEXT DEFAULT ''
+);
+
+CREATE TABLE articles (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    feed_id BIGINT,
+    title TEXT NOT NULL,
+    url TEXT UNIQUE NOT NULL,
+    published_at TIMESTAMPTZ,
+    quality_rank INTEGER,
+    summary TEXT,
+    is_read BOOLEAN DEFAULT FALSE,
+    read_later BOOLEAN DEFAULT FALSE,
+    justification TEXT,
+    guid TEXT DEFAULT '',
+    updated_at TIMESTAMPTZ,
+    image_url TEXT DEFAULT '',
+    categories TEXT DEFAULT '',
+    deleted_at TIMESTAMPTZ,
+    scored_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_articles_quality_rank ON articles (quality_rank DESC);
+CREATE INDEX idx_articles_feed_guid ON articles (feed_id, guid);
+CREATE INDEX idx_articles_deleted_at ON articles (deleted_at);
+CREATE INDEX idx_articles_scored_at ON articles (scored_at);
+
+CREATE TABLE article_content (
+    article_id BIGINT PRIMARY KEY,
+    content TEXT,
+    judge_model TEXT
+);
+
+CREATE TABLE tags (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT COLLATE "C" UNIQUE NOT NULL
+);
+
+CREATE TABLE article_tags (
+    article_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    pinned BOOLEAN DEFAULT FALSE,
+    PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE INDEX idx_article_tags_tag_id ON article_tags (tag_id);
+
+CREATE TABLE authors (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT COLLATE "C" NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    UNIQUE (name, email)
+);
+
+CREATE TABLE article_authors (
+    article_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    PRIMARY KEY (article_id, author_id)
+);
+
+CREATE TABLE enclosures (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    article_id BIGINT NOT NULL,
+    url TEXT NOT NULL,
+    mime_type TEXT DEFAULT '',
+    length BIGINT DEFAULT 0
+);
+
+CREATE INDEX idx_enclosures_article_id ON enclosures (article_id);
+
+CREATE TABLE tombstones (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    feed_id BIGINT NOT NULL,
+    url TEXT NOT NULL,
+    guid TEXT DEFAULT '',
+    published_at TIMESTAMPTZ,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_tombstones_url ON tombstones (url);
+CREATE INDEX idx_tombstones_feed_guid ON tombstones (feed_id, guid);
+CREATE INDEX idx_tombstones_published_at ON tombstones (published_at);
+
+CREATE TABLE rejected_articles (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    feed_id BIGINT NOT NULL,
+    score INTEGER NOT NULL,
+    published_at TIMESTAMPTZ,
+    rejected_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_rejected_articles_feed_id ON rejected_articles (feed_id);
+
+CREATE TABLE ingest_rules (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    feed_id BIGINT,
+    field TEXT NOT NULL,
+    match_type TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL,
+    tags TEXT DEFAULT '',
+    score INTEGER DEFAULT 0,
+    enabled BOOLEAN DEFAULT TRUE,
+    match_count INTEGER DEFAULT 0,
+    last_matched_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_ingest_rules_feed_id ON ingest_rules (feed_id);
+
+CREATE TABLE webhooks (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL,
+    min_score INTEGER DEFAULT 0,
+    tags TEXT DEFAULT '',
+    enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    webhook_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status_code INTEGER DEFAULT 0,
+    attempts INTEGER DEFAULT 0,
+    error TEXT DEFAULT '',
+    duration_ms BIGINT DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id);
+
+CREATE TABLE notification_channels (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    url TEXT NOT NULL,
+    room_id TEXT DEFAULT '',
+    token TEXT DEFAULT '',
+    min_score INTEGER DEFAULT 90,
+    tags TEXT DEFAULT '',
+    feed_ids TEXT DEFAULT '',
+    rate_limit INTEGER DEFAULT 10,
+    enabled BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
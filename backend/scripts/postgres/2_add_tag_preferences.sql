@@ -0,0 +1,5 @@
+CREATE TABLE tag_preferences (
+    tag TEXT COLLATE "C" PRIMARY KEY,
+    muted BOOLEAN NOT NULL DEFAULT FALSE,
+    override_score INTEGER DEFAULT 0
+);